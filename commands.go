@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/mobanhawi/aster/internal/diff"
+	"github.com/mobanhawi/aster/internal/format"
+	"github.com/mobanhawi/aster/internal/ncdu"
+	"github.com/mobanhawi/aster/internal/scanner"
+	"github.com/mobanhawi/aster/internal/snapshot"
+	"github.com/mobanhawi/aster/internal/ui"
+)
+
+// scanHeadless scans root without the TUI, for commands that write their
+// result somewhere instead of browsing it.
+func scanHeadless(root string, opts scanner.Options) (*scanner.Node, error) {
+	fmt.Fprintf(os.Stderr, "scanning %s…\n", root)
+	return scanner.ScanWithOptions(context.Background(), root, nil, opts)
+}
+
+// saveSnapshot scans root and writes the tree to path.
+func saveSnapshot(path, root string, opts scanner.Options) int {
+	node, err := scanHeadless(root, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	meta := snapshot.Meta{Root: node.Name, ScannedAt: time.Now(), Options: opts}
+	if err := snapshot.Save(path, node, meta); err != nil {
+		fmt.Fprintf(os.Stderr, "error saving snapshot: %v\n", err)
+		return 1
+	}
+	total := format.Bytes(node.Size())
+	fmt.Printf("saved %s (%s) to %s\n", node.Name, total, path)
+	return 0
+}
+
+// refreshSnapshot brings the snapshot at path up to date with an incremental
+// rescan of its root, re-reading only directories that changed, and writes
+// it back in place.
+func refreshSnapshot(path string) int {
+	prev, meta, err := snapshot.Load(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading snapshot: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "refreshing %s…\n", meta.Root)
+	start := time.Now()
+	node, err := scanner.Refresh(context.Background(), prev, nil, meta.Options)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	meta.ScannedAt = time.Now()
+	if err := snapshot.Save(path, node, meta); err != nil {
+		fmt.Fprintf(os.Stderr, "error saving snapshot: %v\n", err)
+		return 1
+	}
+	total := format.Bytes(node.Size())
+	before := format.Bytes(prev.Size())
+	fmt.Printf("refreshed %s (%s, was %s) in %s\n", node.Name, total, before, time.Since(start).Round(time.Millisecond))
+	return 0
+}
+
+// loadSnapshot opens the TUI, configured by view, on a tree read from a
+// snapshot file.
+func loadSnapshot(path string, view ui.Config) int {
+	node, meta, err := snapshot.Load(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading snapshot: %v\n", err)
+		return 1
+	}
+	view.Scan, view.Tree, view.TakenAt = meta.Options, node, meta.ScannedAt
+	return runUI(ui.NewWithConfig(meta.Root, view))
+}
+
+// compareSnapshots opens the TUI on the difference between the snapshot at
+// oldPath and newer, which is either another snapshot or a path to scan now.
+func compareSnapshots(oldPath, newer string, opts scanner.Options, view ui.Config) int {
+	oldTree, _, err := snapshot.Load(oldPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading snapshot: %v\n", err)
+		return 1
+	}
+
+	newTree, err := loadOrScan(newer, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+
+	result := diff.Compare(oldTree, newTree)
+	view.Diff = result
+	return runUI(ui.NewWithConfig(result.Root.Name, view))
+}
+
+// loadOrScan reads arg as a snapshot if it is a regular file, and scans it
+// otherwise.
+func loadOrScan(arg string, opts scanner.Options) (*scanner.Node, error) {
+	root, err := resolveRoot(arg)
+	if err != nil {
+		return nil, err
+	}
+	// #nosec G703 -- This is a CLI. Exploring untrusted paths directly from input is intended.
+	if info, err := os.Stat(root); err == nil && info.Mode().IsRegular() {
+		tree, _, err := snapshot.Load(root)
+		return tree, err
+	}
+	return scanHeadless(root, opts)
+}
+
+// exportNcdu scans root and writes the tree as an ncdu JSON dump to path, or
+// to stdout when path is "-".
+func exportNcdu(path, root string, opts scanner.Options) int {
+	node, err := scanHeadless(root, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	meta := ncdu.Meta{ProgName: "aster", ProgVersion: version, Timestamp: time.Now()}
+	if path == "-" {
+		err = ncdu.Export(os.Stdout, node, meta)
+	} else {
+		err = writeNcdu(path, node, meta)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error exporting ncdu dump: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func writeNcdu(path string, node *scanner.Node, meta ncdu.Meta) error {
+	// #nosec G304 -- the output path is supplied by the user on the command line
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	return errors.Join(ncdu.Export(f, node, meta), f.Close())
+}
+
+// importNcdu opens the TUI on a tree read from an ncdu JSON dump at path, or
+// from stdin when path is "-".
+func importNcdu(path string, view ui.Config) int {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		// #nosec G304 -- the dump path is supplied by the user on the command line
+		f, err := os.Open(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			return 1
+		}
+		defer func() {
+			if cerr := f.Close(); cerr != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", cerr)
+			}
+		}()
+		r = f
+	}
+
+	node, meta, err := ncdu.Import(r)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error importing ncdu dump: %v\n", err)
+		return 1
+	}
+	view.Tree, view.TakenAt = node, meta.Timestamp
+	return runUI(ui.NewWithConfig(node.Name, view))
+}
@@ -0,0 +1,216 @@
+// Package diff aligns two scanned trees by path and reports how every entry
+// changed between them.
+package diff
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/mobanhawi/aster/internal/scanner"
+)
+
+// Kind classifies how a path changed between two scans.
+type Kind int8
+
+const (
+	// Unchanged paths exist in both trees with the same size.
+	Unchanged Kind = iota
+	// Added paths exist only in the newer tree.
+	Added
+	// Removed paths exist only in the older tree.
+	Removed
+	// Grown paths got bigger.
+	Grown
+	// Shrunk paths got smaller.
+	Shrunk
+)
+
+// String returns a lower-case label for k.
+func (k Kind) String() string {
+	switch k {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Grown:
+		return "grown"
+	case Shrunk:
+		return "shrunk"
+	default:
+		return "unchanged"
+	}
+}
+
+// Entry holds the before and after sizes of one path. Kind follows the
+// apparent sizes.
+type Entry struct {
+	Old  int64
+	New  int64
+	Kind Kind
+	// OldUsage and NewUsage are the allocated sizes.
+	OldUsage int64
+	NewUsage int64
+}
+
+// Delta returns the change in apparent bytes (positive when the path grew).
+func (e Entry) Delta() int64 {
+	return e.New - e.Old
+}
+
+// UsageDelta returns the change in allocated bytes.
+func (e Entry) UsageDelta() int64 {
+	return e.NewUsage - e.OldUsage
+}
+
+// Result is the union of two trees. Every path from either tree appears once
+// in Root; its size is the newer size (zero for removed paths) and Entry
+// reports both sides.
+type Result struct {
+	Root    *scanner.Node
+	entries map[*scanner.Node]Entry
+}
+
+// Entry returns the change recorded for a node of r.Root.
+func (r *Result) Entry(n *scanner.Node) Entry {
+	return r.entries[n]
+}
+
+// SortByDelta sorts n's children by absolute change, largest first, breaking
+// ties by name so the order is stable across renders.
+func (r *Result) SortByDelta(n *scanner.Node) {
+	r.sortBy(n, Entry.Delta)
+}
+
+// SortByUsageDelta is SortByDelta for the allocated sizes.
+func (r *Result) SortByUsageDelta(n *scanner.Node) {
+	r.sortBy(n, Entry.UsageDelta)
+}
+
+func (r *Result) sortBy(n *scanner.Node, delta func(Entry) int64) {
+	slices.SortFunc(n.Children, func(a, b *scanner.Node) int {
+		if c := cmp.Compare(abs(delta(r.entries[b])), abs(delta(r.entries[a]))); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Name, b.Name)
+	})
+}
+
+// Compare aligns oldRoot and newRoot by path. The input trees are not
+// modified; the union tree is built from copies.
+func Compare(oldRoot, newRoot *scanner.Node) *Result {
+	r := &Result{entries: make(map[*scanner.Node]Entry)}
+	r.Root = r.merge(nil, oldRoot, newRoot)
+	// The roots may have been scanned under different paths; the newer one
+	// is what the user is looking at now.
+	r.Root.Name = newRoot.Name
+	return r
+}
+
+// merge builds the union node for a path present in old, new, or both. When
+// the path changed type, the node takes the newer kind and a newer
+// directory's contents count as added.
+func (r *Result) merge(parent, oldNode, newNode *scanner.Node) *scanner.Node {
+	switch {
+	case oldNode == nil:
+		return r.clone(parent, newNode, Added)
+	case newNode == nil:
+		return r.clone(parent, oldNode, Removed)
+	}
+
+	n := &scanner.Node{
+		Parent:     parent,
+		Name:       newNode.Name,
+		IsDir:      newNode.IsDir,
+		Type:       newNode.Type,
+		Err:        newNode.Err,
+		LinkTarget: newNode.LinkTarget,
+		IsHardlink: newNode.IsHardlink,
+		IsMount:    newNode.IsMount,
+	}
+	n.SetSize(newNode.Size())
+	n.SetUsage(newNode.Usage())
+	n.SetCounts(newNode.Files(), newNode.Dirs())
+	n.SetErrors(newNode.Errors())
+	r.entries[n] = entryFor(oldNode, newNode)
+
+	if oldNode.IsDir != newNode.IsDir {
+		for _, c := range newNode.Children {
+			n.Children = append(n.Children, r.clone(n, c, Added))
+		}
+		return n
+	}
+	if !newNode.IsDir {
+		return n
+	}
+
+	olds := make(map[string]*scanner.Node, len(oldNode.Children))
+	for _, c := range oldNode.Children {
+		olds[c.Name] = c
+	}
+	n.Children = make([]*scanner.Node, 0, max(len(newNode.Children), len(oldNode.Children)))
+	for _, c := range newNode.Children {
+		match := olds[c.Name]
+		delete(olds, c.Name)
+		n.Children = append(n.Children, r.merge(n, match, c))
+	}
+	// Whatever is left only existed before. Walk oldNode.Children rather than
+	// the map to keep the output order deterministic.
+	for _, c := range oldNode.Children {
+		if _, gone := olds[c.Name]; gone {
+			n.Children = append(n.Children, r.merge(n, c, nil))
+		}
+	}
+	return n
+}
+
+// clone copies a subtree that exists on one side only, tagging every node
+// with kind.
+func (r *Result) clone(parent, src *scanner.Node, kind Kind) *scanner.Node {
+	n := &scanner.Node{
+		Parent:     parent,
+		Name:       src.Name,
+		IsDir:      src.IsDir,
+		Type:       src.Type,
+		Err:        src.Err,
+		LinkTarget: src.LinkTarget,
+		IsHardlink: src.IsHardlink,
+		IsMount:    src.IsMount,
+	}
+	e := Entry{Kind: kind}
+	if kind == Removed {
+		e.Old, e.OldUsage = src.Size(), src.Usage()
+	} else {
+		e.New, e.NewUsage = src.Size(), src.Usage()
+		n.SetSize(src.Size())
+		n.SetUsage(src.Usage())
+		n.SetCounts(src.Files(), src.Dirs())
+		n.SetErrors(src.Errors())
+	}
+	r.entries[n] = e
+
+	if len(src.Children) > 0 {
+		n.Children = make([]*scanner.Node, len(src.Children))
+		for i, c := range src.Children {
+			n.Children[i] = r.clone(n, c, kind)
+		}
+	}
+	return n
+}
+
+func entryFor(oldNode, newNode *scanner.Node) Entry {
+	e := Entry{Old: oldNode.Size(), New: newNode.Size(), OldUsage: oldNode.Usage(), NewUsage: newNode.Usage()}
+	switch {
+	case e.New > e.Old:
+		e.Kind = Grown
+	case e.New < e.Old:
+		e.Kind = Shrunk
+	}
+	return e
+}
+
+func abs(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
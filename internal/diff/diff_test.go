@@ -0,0 +1,155 @@
+package diff_test
+
+import (
+	"testing"
+
+	"github.com/mobanhawi/aster/internal/diff"
+	"github.com/mobanhawi/aster/internal/scanner"
+	"github.com/mobanhawi/aster/internal/scanner/scannertest"
+)
+
+// ── Helpers ──────────────────────────────────────────────────────────────────
+
+func child(t *testing.T, n *scanner.Node, name string) *scanner.Node {
+	t.Helper()
+	for _, c := range n.Children {
+		if c.Name == name {
+			return c
+		}
+	}
+	t.Fatalf("%s has no child %q", n.Name, name)
+	return nil
+}
+
+// ── Tests ────────────────────────────────────────────────────────────────────
+
+func TestCompare(t *testing.T) {
+	oldTree := scannertest.Node("/data", true, 1600,
+		scannertest.Node("logs", true, 1000,
+			scannertest.Node("a.log", false, 600),
+			scannertest.Node("old.log", false, 400),
+		),
+		scannertest.Node("same.txt", false, 100),
+		scannertest.Node("gone", true, 500, scannertest.Node("x.bin", false, 500)),
+	)
+	newTree := scannertest.Node("/data", true, 4100,
+		scannertest.Node("logs", true, 3000,
+			scannertest.Node("a.log", false, 2600),
+			scannertest.Node("new.log", false, 400),
+		),
+		scannertest.Node("same.txt", false, 100),
+		scannertest.Node("cache", true, 1000, scannertest.Node("y.bin", false, 1000)),
+	)
+
+	r := diff.Compare(oldTree, newTree)
+
+	testCases := []struct {
+		name     string
+		path     []string
+		wantKind diff.Kind
+		wantOld  int64
+		wantNew  int64
+	}{
+		{"GivenRoot_WhenCompared_ThenGrownByTotalDelta", nil, diff.Grown, 1600, 4100},
+		{"GivenGrownDir_WhenCompared_ThenGrown", []string{"logs"}, diff.Grown, 1000, 3000},
+		{"GivenGrownFile_WhenCompared_ThenGrown", []string{"logs", "a.log"}, diff.Grown, 600, 2600},
+		{"GivenRemovedFile_WhenCompared_ThenRemoved", []string{"logs", "old.log"}, diff.Removed, 400, 0},
+		{"GivenAddedFile_WhenCompared_ThenAdded", []string{"logs", "new.log"}, diff.Added, 0, 400},
+		{"GivenSameFile_WhenCompared_ThenUnchanged", []string{"same.txt"}, diff.Unchanged, 100, 100},
+		{"GivenRemovedDir_WhenCompared_ThenDescendantsRemoved", []string{"gone", "x.bin"}, diff.Removed, 500, 0},
+		{"GivenAddedDir_WhenCompared_ThenDescendantsAdded", []string{"cache", "y.bin"}, diff.Added, 0, 1000},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			n := r.Root
+			for _, name := range tc.path {
+				n = child(t, n, name)
+			}
+			e := r.Entry(n)
+			if e.Kind != tc.wantKind || e.Old != tc.wantOld || e.New != tc.wantNew {
+				t.Errorf("entry = %+v (%s), want %s %d→%d", e, e.Kind, tc.wantKind, tc.wantOld, tc.wantNew)
+			}
+			if n.Size() != tc.wantNew {
+				t.Errorf("Size() = %d, want newer size %d", n.Size(), tc.wantNew)
+			}
+		})
+	}
+
+	t.Run("GivenInputs_WhenCompared_ThenInputsUntouched", func(t *testing.T) {
+		if len(oldTree.Children[0].Children) != 2 || child(t, oldTree, "logs").Parent != oldTree {
+			t.Error("Compare modified the old tree")
+		}
+	})
+}
+
+func TestSortByDelta(t *testing.T) {
+	t.Run("GivenMixedChanges_WhenSorted_ThenLargestAbsoluteDeltaFirst", func(t *testing.T) {
+		oldTree := scannertest.Node("/", true, 0,
+			scannertest.Node("grew", false, 100),
+			scannertest.Node("shrank", false, 5000),
+			scannertest.Node("same", false, 10),
+		)
+		newTree := scannertest.Node("/", true, 0,
+			scannertest.Node("grew", false, 1100),
+			scannertest.Node("shrank", false, 1000),
+			scannertest.Node("same", false, 10),
+		)
+		r := diff.Compare(oldTree, newTree)
+		r.SortByDelta(r.Root)
+
+		want := []string{"shrank", "grew", "same"}
+		for i, c := range r.Root.Children {
+			if c.Name != want[i] {
+				t.Errorf("children[%d] = %q, want %q", i, c.Name, want[i])
+			}
+		}
+		if d := r.Entry(r.Root.Children[0]).Delta(); d != -4000 {
+			t.Errorf("Delta() = %d, want -4000", d)
+		}
+	})
+	t.Run("GivenUsageChanges_WhenSortedByUsage_ThenLargestAllocatedDeltaFirst", func(t *testing.T) {
+		sparse, dense := scannertest.Node("sparse", false, 1000), scannertest.Node("dense", false, 10)
+		sparse.SetUsage(4096)
+		dense.SetUsage(4096)
+		oldTree := scannertest.Node("/", true, 0, sparse, dense)
+		grown, filled := scannertest.Node("sparse", false, 9000), scannertest.Node("dense", false, 10)
+		grown.SetUsage(4096)
+		filled.SetUsage(65536)
+		r := diff.Compare(oldTree, scannertest.Node("/", true, 0, grown, filled))
+		r.SortByUsageDelta(r.Root)
+
+		if first := r.Root.Children[0]; first.Name != "dense" || r.Entry(first).UsageDelta() != 61440 {
+			t.Errorf("first = %q with usage delta %d, want dense with 61440", first.Name, r.Entry(first).UsageDelta())
+		}
+	})
+}
+
+func TestCompareTypeChange(t *testing.T) {
+	oldTree := scannertest.Node("/", true, 150,
+		scannertest.Node("was-file", false, 100),
+		scannertest.Node("was-dir", true, 50, scannertest.Node("inner", false, 50)),
+	)
+	newTree := scannertest.Node("/", true, 350,
+		scannertest.Node("was-file", true, 300, scannertest.Node("c", false, 300)),
+		scannertest.Node("was-dir", false, 50),
+	)
+	r := diff.Compare(oldTree, newTree)
+
+	t.Run("GivenFileBecameDir_WhenCompared_ThenDirWithAddedContents", func(t *testing.T) {
+		n := child(t, r.Root, "was-file")
+		if !n.IsDir || len(n.Children) != 1 || r.Entry(n.Children[0]).Kind != diff.Added {
+			t.Errorf("IsDir %v with %d children; want a dir holding one added entry", n.IsDir, len(n.Children))
+		}
+		if e := r.Entry(n); e.Old != 100 || e.New != 300 {
+			t.Errorf("entry = %+v, want 100→300", e)
+		}
+	})
+
+	t.Run("GivenDirBecameFile_WhenCompared_ThenFileWithoutChildren", func(t *testing.T) {
+		n := child(t, r.Root, "was-dir")
+		if n.IsDir || len(n.Children) != 0 {
+			t.Errorf("IsDir %v with %d children; want a plain file", n.IsDir, len(n.Children))
+		}
+	})
+}
@@ -0,0 +1,277 @@
+// Package dupes finds files with identical contents in a scanned tree.
+//
+// Candidates are narrowed in three passes, each reading only the files that
+// are still ambiguous: equal size, then a hash of the first and last few KiB,
+// then a hash of the whole file. Most files never get past the first pass, so
+// a tree of millions of files costs little more than a walk of the scan.
+package dupes
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"os"
+	"runtime"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/mobanhawi/aster/internal/scanner"
+)
+
+// edgeSize is how much of each end of a file the partial hash covers. Files
+// up to twice this size are hashed in full by the partial pass.
+const edgeSize = 4 << 10
+
+// File is a candidate for comparison.
+type File struct {
+	Node *scanner.Node
+	// Path is Node's full path, resolved when the candidate was collected.
+	Path string
+	// Size is Node's size when the candidate was collected.
+	Size int64
+
+	sum string // hash from the latest pass
+}
+
+// Group is a set of files with identical contents.
+type Group struct {
+	// Size is the size of each copy.
+	Size int64
+	// Files holds every copy, ordered by path.
+	Files []File
+}
+
+// Reclaimable is the space freed by keeping one copy and deleting the rest.
+func (g Group) Reclaimable() int64 {
+	return g.Size * int64(len(g.Files)-1)
+}
+
+// Candidates lists the files under root of at least minSize bytes that share
+// their size with another file. Empty files, symlinks and other non-regular
+// files, hard links whose bytes are counted elsewhere and entries with errors
+// are left out.
+//
+// Candidates only reads the tree, so callers that own it can collect here and
+// hand the result to Find on another goroutine.
+func Candidates(root *scanner.Node, minSize int64) []File {
+	bySize := make(map[int64][]*scanner.Node)
+	var walk func(n *scanner.Node)
+	walk = func(n *scanner.Node) {
+		for _, c := range n.Children {
+			switch {
+			case c.IsDir:
+				walk(c)
+			case c.Size() > 0 && c.Size() >= minSize && !c.IsHardlink && c.Type == 0 && c.Err == nil:
+				bySize[c.Size()] = append(bySize[c.Size()], c)
+			}
+		}
+	}
+	walk(root)
+
+	var files []File
+	for size, nodes := range bySize {
+		if len(nodes) < 2 {
+			continue
+		}
+		for _, n := range nodes {
+			files = append(files, File{Node: n, Path: n.FullPath(), Size: size})
+		}
+	}
+	return files
+}
+
+// Find compares files and returns the groups of identical ones, largest
+// Reclaimable first. Files that can't be read are skipped. hashed, if not
+// nil, is advanced by every byte read, for progress display. Find stops early
+// with ctx.Err() when ctx is cancelled.
+func Find(ctx context.Context, files []File, hashed *atomic.Int64) ([]Group, error) {
+	if hashed == nil {
+		hashed = new(atomic.Int64)
+	}
+	groups := split(files, func(f File) int64 { return f.Size })
+
+	groups, err := refine(ctx, groups, func(f File) (string, error) {
+		return hashEdges(f, hashed)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Small files were read whole by the partial pass; only the rest need a
+	// full hash.
+	var small, large [][]File
+	for _, g := range groups {
+		if g[0].Size <= 2*edgeSize {
+			small = append(small, g)
+		} else {
+			large = append(large, g)
+		}
+	}
+	large, err = refine(ctx, large, func(f File) (string, error) {
+		return hashAll(ctx, f, hashed)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]Group, 0, len(small)+len(large))
+	for _, g := range append(small, large...) {
+		slices.SortFunc(g, func(a, b File) int { return cmp.Compare(a.Path, b.Path) })
+		out = append(out, Group{Size: g[0].Size, Files: g})
+	}
+	slices.SortFunc(out, func(a, b Group) int {
+		if c := cmp.Compare(b.Reclaimable(), a.Reclaimable()); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Files[0].Path, b.Files[0].Path)
+	})
+	return out, nil
+}
+
+// split partitions files by key and keeps the partitions with more than one
+// file.
+func split[K comparable](files []File, key func(File) K) [][]File {
+	parts := make(map[K][]File)
+	var order []K // keeps the output deterministic
+	for _, f := range files {
+		k := key(f)
+		if _, ok := parts[k]; !ok {
+			order = append(order, k)
+		}
+		parts[k] = append(parts[k], f)
+	}
+	var out [][]File
+	for _, k := range order {
+		if len(parts[k]) > 1 {
+			out = append(out, parts[k])
+		}
+	}
+	return out
+}
+
+// refine hashes every file of every group concurrently and splits each group
+// by hash. Files whose hash fails drop out.
+func refine(ctx context.Context, groups [][]File, hash func(File) (string, error)) ([][]File, error) {
+	var all []File
+	for _, g := range groups {
+		all = append(all, g...)
+	}
+	sums := make([]string, len(all))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(runtime.NumCPU()*2, len(all)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if sum, err := hash(all[i]); err == nil {
+					sums[i] = sum
+				}
+			}
+		}()
+	}
+feed:
+	for i := range all {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var out [][]File
+	i := 0
+	for _, g := range groups {
+		hashed := make([]File, 0, len(g))
+		for _, f := range g {
+			if f.sum = sums[i]; f.sum != "" {
+				hashed = append(hashed, f)
+			}
+			i++
+		}
+		out = append(out, split(hashed, func(f File) string { return f.sum })...)
+	}
+	return out, nil
+}
+
+// hashEdges hashes the first and last edgeSize bytes of f, or all of it when
+// it is small.
+func hashEdges(f File, hashed *atomic.Int64) (_ string, err error) {
+	// #nosec G304 -- the path comes from the user's own scan
+	file, err := os.Open(f.Path)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if cerr := file.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+
+	var buf []byte
+	if f.Size <= 2*edgeSize {
+		buf = make([]byte, f.Size)
+		_, err = io.ReadFull(file, buf)
+	} else {
+		buf = make([]byte, 2*edgeSize)
+		if _, err = io.ReadFull(file, buf[:edgeSize]); err == nil {
+			_, err = file.ReadAt(buf[edgeSize:], f.Size-edgeSize)
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	hashed.Add(int64(len(buf)))
+	sum := sha256.Sum256(buf)
+	return string(sum[:]), nil
+}
+
+// hashAll hashes the whole of f, giving up when ctx is cancelled.
+func hashAll(ctx context.Context, f File, hashed *atomic.Int64) (_ string, err error) {
+	// #nosec G304 -- the path comes from the user's own scan
+	file, err := os.Open(f.Path)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if cerr := file.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+
+	h := sha256.New()
+	n, err := io.Copy(h, &progressReader{ctx: ctx, r: file, hashed: hashed})
+	if err != nil {
+		return "", err
+	}
+	if n != f.Size {
+		return "", errors.New("file changed size since the scan")
+	}
+	return string(h.Sum(nil)), nil
+}
+
+// progressReader counts bytes into hashed and fails once ctx is done, so a
+// multi-gigabyte hash can be abandoned part way.
+type progressReader struct {
+	ctx    context.Context
+	r      io.Reader
+	hashed *atomic.Int64
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	if err := p.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := p.r.Read(b)
+	p.hashed.Add(int64(n))
+	return n, err
+}
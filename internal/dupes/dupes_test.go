@@ -0,0 +1,163 @@
+package dupes_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mobanhawi/aster/internal/dupes"
+	"github.com/mobanhawi/aster/internal/scanner"
+)
+
+// ── Helpers ──────────────────────────────────────────────────────────────────
+
+// content returns size bytes of a repeating pattern seeded by seed.
+func content(size int, seed byte) []byte {
+	b := make([]byte, size)
+	for i := range b {
+		b[i] = seed + byte(i%251)
+	}
+	return b
+}
+
+// scanFiles writes files (relative path → contents) under a temp dir and
+// scans it.
+func scanFiles(t *testing.T, files map[string][]byte) *scanner.Node {
+	t.Helper()
+	root := t.TempDir()
+	for rel, data := range files {
+		path := filepath.Join(root, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	tree, err := scanner.Scan(context.Background(), root, nil)
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	return tree
+}
+
+// names returns the base names of g's files.
+func names(g dupes.Group) []string {
+	out := make([]string, len(g.Files))
+	for i, f := range g.Files {
+		out[i] = filepath.Base(f.Path)
+	}
+	return out
+}
+
+// ── Tests ────────────────────────────────────────────────────────────────────
+
+func TestFind(t *testing.T) {
+	const big = 64 << 10 // well past the partial-hash window
+
+	// middle differs from the big copies only in one byte in the middle, so
+	// the partial hash matches and only the full hash tells them apart.
+	middle := content(big, 1)
+	middle[big/2]++
+
+	tree := scanFiles(t, map[string][]byte{
+		"a/movie.mkv":      content(big, 1),
+		"b/movie copy.mkv": content(big, 1),
+		"c/movie (1).mkv":  content(big, 1),
+		"middle.mkv":       middle,
+		"head.mkv":         content(big, 9),
+		"x/notes.txt":      []byte("same words"),
+		"y/notes.txt":      []byte("same words"),
+		"other.txt":        []byte("diff words"),
+		"empty1":           {},
+		"empty2":           {},
+	})
+
+	t.Run("GivenTree_WhenSearched_ThenIdenticalFilesGroupedBiggestFirst", func(t *testing.T) {
+		var hashed atomic.Int64
+		groups, err := dupes.Find(context.Background(), dupes.Candidates(tree, 0), &hashed)
+		if err != nil {
+			t.Fatalf("Find: %v", err)
+		}
+		if len(groups) != 2 {
+			t.Fatalf("got %d groups, want 2: %+v", len(groups), groups)
+		}
+		if got, want := names(groups[0]), []string{"movie.mkv", "movie copy.mkv", "movie (1).mkv"}; !slices.Equal(got, want) {
+			t.Errorf("first group = %v, want %v", got, want)
+		}
+		if groups[0].Reclaimable() != 2*big || groups[0].Files[0].Node.Name != "movie.mkv" {
+			t.Errorf("Reclaimable() = %d, want %d", groups[0].Reclaimable(), 2*big)
+		}
+		if got := names(groups[1]); !slices.Equal(got, []string{"notes.txt", "notes.txt"}) {
+			t.Errorf("second group = %v, want both notes.txt", got)
+		}
+		// Every big file gets a partial hash; only the four sharing their
+		// edges are read in full.
+		if floor := int64(4 * big); hashed.Load() < floor {
+			t.Errorf("hashed %d bytes, want at least %d", hashed.Load(), floor)
+		}
+	})
+
+	t.Run("GivenMinSize_WhenCollecting_ThenSmallFilesSkipped", func(t *testing.T) {
+		files := dupes.Candidates(tree, 1024)
+		for _, f := range files {
+			if f.Size < 1024 {
+				t.Errorf("%s (%d bytes) should be below the minimum", f.Path, f.Size)
+			}
+		}
+		if len(files) != 5 {
+			t.Errorf("got %d candidates, want the 5 big files", len(files))
+		}
+	})
+
+	t.Run("GivenCancelledContext_WhenSearched_ThenContextError", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := dupes.Find(ctx, dupes.Candidates(tree, 0), nil); !errors.Is(err, context.Canceled) {
+			t.Errorf("err = %v, want context.Canceled", err)
+		}
+	})
+
+	t.Run("GivenFileRemovedAfterScan_WhenSearched_ThenSkipped", func(t *testing.T) {
+		tree := scanFiles(t, map[string][]byte{
+			"one":  content(100, 3),
+			"two":  content(100, 3),
+			"gone": content(100, 3),
+		})
+		if err := os.Remove(filepath.Join(tree.Name, "gone")); err != nil {
+			t.Fatalf("remove: %v", err)
+		}
+		groups, err := dupes.Find(context.Background(), dupes.Candidates(tree, 0), nil)
+		if err != nil {
+			t.Fatalf("Find: %v", err)
+		}
+		if len(groups) != 1 || !slices.Equal(names(groups[0]), []string{"one", "two"}) {
+			t.Errorf("groups = %+v, want just one and two", groups)
+		}
+	})
+}
+
+func TestFindHardlinks(t *testing.T) {
+	t.Run("GivenHardlinkedFile_WhenSearched_ThenNotReportedAsDuplicate", func(t *testing.T) {
+		root := t.TempDir()
+		orig := filepath.Join(root, "orig")
+		if err := os.WriteFile(orig, content(100, 5), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		if err := os.Link(orig, filepath.Join(root, "link")); err != nil {
+			t.Skipf("hard links unsupported: %v", err)
+		}
+		tree, err := scanner.Scan(context.Background(), root, nil)
+		if err != nil {
+			t.Fatalf("Scan: %v", err)
+		}
+		groups, err := dupes.Find(context.Background(), dupes.Candidates(tree, 0), nil)
+		if err != nil || len(groups) != 0 {
+			t.Errorf("groups = %+v, err = %v; deleting a hard link frees nothing", groups, err)
+		}
+	})
+}
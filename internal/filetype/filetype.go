@@ -0,0 +1,143 @@
+// Package filetype totals a scanned tree by file extension or by coarse
+// category, answering "how much of this is video?" where the tree itself
+// only answers "how much of this is in ~/Movies?".
+package filetype
+
+import (
+	"cmp"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/mobanhawi/aster/internal/scanner"
+)
+
+// Grouping selects how files are keyed.
+type Grouping int
+
+const (
+	// ByExtension keys files by their lower-cased extension.
+	ByExtension Grouping = iota
+	// ByCategory keys files by the kind of content their extension implies.
+	ByCategory
+)
+
+// NoExtension is the key of files without an extension, dotfiles included.
+const NoExtension = "(none)"
+
+// Categories, in the order they are documented.
+const (
+	Video     = "video"
+	Images    = "images"
+	Audio     = "audio"
+	Archives  = "archives"
+	Documents = "documents"
+	Code      = "code"
+	Build     = "build artefacts"
+	Other     = "other"
+)
+
+// categories maps extensions to their category; anything else is Other.
+var categories = map[string]string{}
+
+func init() {
+	for cat, exts := range map[string]string{
+		Video:     ".mp4 .mkv .mov .avi .wmv .webm .m4v .mpg .mpeg .flv .3gp",
+		Images:    ".jpg .jpeg .png .gif .bmp .tif .tiff .webp .heic .heif .raw .cr2 .nef .arw .dng .psd .svg .ico",
+		Audio:     ".mp3 .wav .flac .aac .m4a .ogg .opus .aiff .wma",
+		Archives:  ".zip .tar .gz .tgz .bz2 .xz .zst .7z .rar .dmg .iso .img .pkg .deb .rpm .jar .whl",
+		Documents: ".pdf .doc .docx .xls .xlsx .ppt .pptx .odt .ods .odp .txt .md .rtf .epub .csv",
+		Code: ".go .c .h .cc .cpp .hpp .rs .py .js .ts .tsx .jsx .java .kt .swift .m .rb .php .cs .sh " +
+			".html .css .scss .json .yaml .yml .toml .xml .sql .lua .pl .r .scala .zig",
+		Build: ".o .a .so .dylib .dll .lib .obj .class .pyc .pyo .exe .wasm .rlib .rmeta .d .pch .gch",
+	} {
+		for _, ext := range strings.Fields(exts) {
+			categories[strings.ToLower(ext)] = cat
+		}
+	}
+}
+
+// Ext returns the lower-cased extension of name including the dot, or
+// NoExtension. A leading dot alone does not make an extension.
+func Ext(name string) string {
+	ext := strings.ToLower(filepath.Ext(name))
+	if ext == "" || ext == "." || len(ext) == len(name) {
+		return NoExtension
+	}
+	return ext
+}
+
+// Category returns the category of name's extension.
+func Category(name string) string {
+	if cat, ok := categories[Ext(name)]; ok {
+		return cat
+	}
+	return Other
+}
+
+// Key returns the key g files name under.
+func (g Grouping) Key(name string) string {
+	if g == ByCategory {
+		return Category(name)
+	}
+	return Ext(name)
+}
+
+// Stat totals the files sharing a key.
+type Stat struct {
+	Key   string
+	Size  int64
+	Usage int64
+	Files int
+}
+
+// Aggregate totals every file below root by g's key, largest Size first.
+func Aggregate(root *scanner.Node, g Grouping) []Stat {
+	byKey := make(map[string]*Stat)
+	walk(root, func(n *scanner.Node) {
+		k := g.Key(n.Name)
+		s := byKey[k]
+		if s == nil {
+			s = &Stat{Key: k}
+			byKey[k] = s
+		}
+		s.Size += n.Size()
+		s.Usage += n.Usage()
+		s.Files++
+	})
+
+	stats := make([]Stat, 0, len(byKey))
+	for _, s := range byKey {
+		stats = append(stats, *s)
+	}
+	slices.SortFunc(stats, func(a, b Stat) int {
+		if c := cmp.Compare(b.Size, a.Size); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Key, b.Key)
+	})
+	return stats
+}
+
+// Files returns the files below root that g keys as key, largest first.
+func Files(root *scanner.Node, g Grouping, key string) []*scanner.Node {
+	var files []*scanner.Node
+	walk(root, func(n *scanner.Node) {
+		if g.Key(n.Name) == key {
+			files = append(files, n)
+		}
+	})
+	slices.SortStableFunc(files, func(a, b *scanner.Node) int { return cmp.Compare(b.Size(), a.Size()) })
+	return files
+}
+
+// walk calls fn for every file below n.
+func walk(n *scanner.Node, fn func(*scanner.Node)) {
+	for _, c := range n.Children {
+		if c.IsDir {
+			walk(c, fn)
+		} else {
+			fn(c)
+		}
+	}
+}
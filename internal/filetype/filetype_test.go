@@ -0,0 +1,101 @@
+package filetype_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mobanhawi/aster/internal/filetype"
+	"github.com/mobanhawi/aster/internal/scanner/scannertest"
+)
+
+// ── Tests ────────────────────────────────────────────────────────────────────
+
+func TestExtAndCategory(t *testing.T) {
+	testCases := []struct {
+		name         string
+		file         string
+		wantExt      string
+		wantCategory string
+	}{
+		{"GivenUpperCaseExt_WhenKeyed_ThenLowerCased", "Movie.MKV", ".mkv", filetype.Video},
+		{"GivenDoubleExt_WhenKeyed_ThenLastOneCounts", "logs.tar.gz", ".gz", filetype.Archives},
+		{"GivenObjectFile_WhenKeyed_ThenBuildArtefact", "main.o", ".o", filetype.Build},
+		{"GivenNoExt_WhenKeyed_ThenNoneAndOther", "Makefile", filetype.NoExtension, filetype.Other},
+		{"GivenDotfile_WhenKeyed_ThenNoExtension", ".bashrc", filetype.NoExtension, filetype.Other},
+		{"GivenTrailingDot_WhenKeyed_ThenNoExtension", "draft.", filetype.NoExtension, filetype.Other},
+		{"GivenUnknownExt_WhenKeyed_ThenOther", "data.xyz", ".xyz", filetype.Other},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := filetype.Ext(tc.file); got != tc.wantExt {
+				t.Errorf("Ext(%q) = %q, want %q", tc.file, got, tc.wantExt)
+			}
+			if got := filetype.Category(tc.file); got != tc.wantCategory {
+				t.Errorf("Category(%q) = %q, want %q", tc.file, got, tc.wantCategory)
+			}
+		})
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		grouping filetype.Grouping
+		want     []filetype.Stat
+	}{
+		{
+			name:     "GivenByExtension_WhenAggregated_ThenLargestFirstTiesByKey",
+			grouping: filetype.ByExtension,
+			want: []filetype.Stat{
+				{Key: ".mkv", Size: 5000, Usage: 10000, Files: 1},
+				{Key: ".o", Size: 1500, Usage: 3000, Files: 1},
+				{Key: ".go", Size: 1000, Usage: 2000, Files: 1},
+				{Key: ".jpg", Size: 1000, Usage: 2000, Files: 1},
+				{Key: ".mp4", Size: 1000, Usage: 2000, Files: 1},
+				{Key: filetype.NoExtension, Size: 500, Usage: 1000, Files: 2},
+			},
+		},
+		{
+			name:     "GivenByCategory_WhenAggregated_ThenExtensionsCombined",
+			grouping: filetype.ByCategory,
+			want: []filetype.Stat{
+				{Key: filetype.Video, Size: 6000, Usage: 12000, Files: 2},
+				{Key: filetype.Build, Size: 1500, Usage: 3000, Files: 1},
+				{Key: filetype.Code, Size: 1000, Usage: 2000, Files: 1},
+				{Key: filetype.Images, Size: 1000, Usage: 2000, Files: 1},
+				{Key: filetype.Other, Size: 500, Usage: 1000, Files: 2},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := filetype.Aggregate(scannertest.SampleTree(), tc.grouping); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Aggregate() =\n%+v\nwant\n%+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFiles(t *testing.T) {
+	testCases := []struct {
+		name     string
+		grouping filetype.Grouping
+		key      string
+		want     []string
+	}{
+		{"GivenCategory_WhenListed_ThenLargestFirstAcrossDirs", filetype.ByCategory, filetype.Video, []string{"/data/media/movie.MKV", "/data/media/clips/clip.mp4"}},
+		{"GivenNoExtension_WhenListed_ThenDotfilesIncluded", filetype.ByExtension, filetype.NoExtension, []string{"/data/Makefile", "/data/.bashrc"}},
+		{"GivenUnusedKey_WhenListed_ThenEmpty", filetype.ByExtension, ".pdf", nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []string
+			for _, n := range filetype.Files(scannertest.SampleTree(), tc.grouping, tc.key) {
+				got = append(got, n.FullPath())
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Files() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
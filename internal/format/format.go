@@ -0,0 +1,11 @@
+// Package format renders values for people, the same way in the TUI and in
+// the command-line output.
+package format
+
+import humanize "github.com/dustin/go-humanize"
+
+// Bytes formats n like the TUI's size column, e.g. "3.2 GB", clamping
+// negatives to zero.
+func Bytes(n int64) string {
+	return humanize.Bytes(uint64(max(n, 0))) // #nosec G115 -- clamped to non-negative
+}
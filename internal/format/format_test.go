@@ -0,0 +1,27 @@
+package format_test
+
+import (
+	"testing"
+
+	"github.com/mobanhawi/aster/internal/format"
+)
+
+func TestBytes(t *testing.T) {
+	testCases := []struct {
+		name string
+		n    int64
+		want string
+	}{
+		{"GivenZero_WhenFormatted_ThenZeroBytes", 0, "0 B"},
+		{"GivenSmallSize_WhenFormatted_ThenBytes", 512, "512 B"},
+		{"GivenLargeSize_WhenFormatted_ThenDecimalUnits", 3_200_000_000, "3.2 GB"},
+		{"GivenNegative_WhenFormatted_ThenClampedToZero", -42, "0 B"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := format.Bytes(tc.n); got != tc.want {
+				t.Errorf("Bytes(%d) = %q, want %q", tc.n, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,346 @@
+// Package ncdu reads and writes the JSON dump format of the ncdu disk usage
+// analyzer (https://dev.yorhel.nl/ncdu/jsonfmt), so trees can be exchanged
+// with ncdu and the tools that consume its output.
+//
+// A dump is [1, 2, {metadata}, rootDir], where a directory is an array whose
+// first element describes the directory itself and the rest are its entries:
+// objects for files, nested arrays for subdirectories. Both directions stream
+// so million-entry trees are never held as JSON in memory.
+package ncdu
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strconv"
+	"time"
+
+	"github.com/mobanhawi/aster/internal/scanner"
+)
+
+// Format version written by Export; Import accepts any 1.x minor version.
+const (
+	majorVersion = 1
+	minorVersion = 2
+)
+
+// Meta is the metadata block at the top of a dump.
+type Meta struct {
+	ProgName    string
+	ProgVersion string
+	Timestamp   time.Time
+}
+
+// Export writes root to w in ncdu's JSON format.
+func Export(w io.Writer, root *scanner.Node, meta Meta) error {
+	e := &encoder{w: bufio.NewWriter(w)}
+
+	e.raw("[" + strconv.Itoa(majorVersion) + "," + strconv.Itoa(minorVersion) + ",")
+	e.raw(`{"progname":`)
+	e.str(meta.ProgName)
+	e.raw(`,"progver":`)
+	e.str(meta.ProgVersion)
+	e.raw(`,"timestamp":` + strconv.FormatInt(meta.Timestamp.Unix(), 10) + "},\n")
+	e.node(root)
+	e.raw("]\n")
+
+	if e.err != nil {
+		return e.err
+	}
+	return e.w.Flush()
+}
+
+// encoder writes JSON fragments, remembering the first error.
+type encoder struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (e *encoder) raw(s string) {
+	if e.err == nil {
+		_, e.err = e.w.WriteString(s)
+	}
+}
+
+func (e *encoder) str(s string) {
+	b, err := json.Marshal(s)
+	if err != nil && e.err == nil {
+		e.err = err
+	}
+	if e.err == nil {
+		_, e.err = e.w.Write(b)
+	}
+}
+
+func (e *encoder) int(key string, v int64) {
+	e.raw(`,"` + key + `":` + strconv.FormatInt(v, 10))
+}
+
+func (e *encoder) uint(key string, v uint64) {
+	e.raw(`,"` + key + `":` + strconv.FormatUint(v, 10))
+}
+
+// node writes n and, for directories, everything below it.
+func (e *encoder) node(n *scanner.Node) {
+	// A directory that was not descended into is written as a plain entry
+	// flagged as excluded, which is how ncdu records other filesystems.
+	isDirArray := n.IsDir && !n.IsMount
+	if isDirArray {
+		e.raw("[")
+	}
+
+	e.raw(`{"name":`)
+	e.str(n.Name)
+	switch {
+	case n.Inode != nil:
+		// Every link carries the inode's sizes and identity; readers count
+		// each inode once.
+		e.int("asize", n.Inode.Size)
+		e.int("dsize", n.Inode.Usage)
+		e.uint("dev", n.Inode.Dev)
+		e.uint("ino", n.Inode.Ino)
+		e.raw(`,"hlnkc":true`)
+	case !n.IsDir:
+		e.int("asize", n.Size())
+		e.int("dsize", n.Usage())
+	}
+	if n.Err != nil {
+		e.raw(`,"read_error":true`)
+	}
+	if n.IsMount {
+		e.raw(`,"excluded":"otherfs"`)
+	}
+	if !n.IsDir && n.Type != 0 {
+		e.raw(`,"notreg":true`) // a symlink, FIFO, socket or device
+	}
+	e.raw("}")
+
+	if isDirArray {
+		for _, c := range n.Children {
+			e.raw(",\n")
+			e.node(c)
+		}
+		e.raw("]")
+	}
+}
+
+// entry is the information object describing one file or directory.
+type entry struct {
+	Name      string `json:"name"`
+	ASize     int64  `json:"asize"`
+	DSize     int64  `json:"dsize"`
+	Dev       uint64 `json:"dev"`
+	Ino       uint64 `json:"ino"`
+	HardLink  bool   `json:"hlnkc"`
+	NotReg    bool   `json:"notreg"`
+	ReadError bool   `json:"read_error"`
+	Excluded  string `json:"excluded"`
+}
+
+// inode identifies a hardlinked file for deduplication.
+type inode struct {
+	dev, ino uint64
+}
+
+// Import builds a tree from an ncdu JSON dump. Hardlinked files are counted
+// once per inode, like ncdu does; later links are marked IsHardlink.
+func Import(r io.Reader) (*scanner.Node, Meta, error) {
+	d := &decoder{dec: json.NewDecoder(bufio.NewReader(r)), links: make(map[inode]struct{})}
+	root, meta, err := d.dump()
+	if err != nil {
+		return nil, Meta{}, fmt.Errorf("invalid ncdu dump: %w", err)
+	}
+	return root, meta, nil
+}
+
+type decoder struct {
+	dec   *json.Decoder
+	links map[inode]struct{}
+}
+
+// dump parses the outer [major, minor, meta, root] array.
+func (d *decoder) dump() (*scanner.Node, Meta, error) {
+	if err := d.expect(json.Delim('[')); err != nil {
+		return nil, Meta{}, err
+	}
+	var major, minor int
+	if err := d.dec.Decode(&major); err != nil {
+		return nil, Meta{}, err
+	}
+	if major != majorVersion {
+		return nil, Meta{}, fmt.Errorf("unsupported major version %d", major)
+	}
+	if err := d.dec.Decode(&minor); err != nil {
+		return nil, Meta{}, err
+	}
+
+	var raw struct {
+		ProgName    string `json:"progname"`
+		ProgVersion string `json:"progver"`
+		Timestamp   int64  `json:"timestamp"`
+	}
+	if err := d.dec.Decode(&raw); err != nil {
+		return nil, Meta{}, err
+	}
+	meta := Meta{ProgName: raw.ProgName, ProgVersion: raw.ProgVersion}
+	if raw.Timestamp > 0 {
+		meta.Timestamp = time.Unix(raw.Timestamp, 0)
+	}
+
+	if err := d.expect(json.Delim('[')); err != nil {
+		return nil, Meta{}, err
+	}
+	root, err := d.dir(nil, 0)
+	if err != nil {
+		return nil, Meta{}, err
+	}
+	return root, meta, nil
+}
+
+// dir parses a directory array whose opening bracket was already consumed.
+func (d *decoder) dir(parent *scanner.Node, parentDev uint64) (*scanner.Node, error) {
+	info, err := d.entry()
+	if err != nil {
+		return nil, err
+	}
+	if info.Dev == 0 {
+		info.Dev = parentDev // dev is only written when it changes
+	}
+	n := newNode(parent, info)
+	n.IsDir = true
+	n.Type = fs.ModeDir
+
+	for d.dec.More() {
+		tok, err := d.dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		var child *scanner.Node
+		switch tok {
+		case json.Delim('['):
+			child, err = d.dir(n, info.Dev)
+		case json.Delim('{'):
+			child, err = d.file(n, info.Dev)
+		default:
+			err = fmt.Errorf("unexpected %v in directory %q", tok, n.Name)
+		}
+		if err != nil {
+			return nil, err
+		}
+		n.Children = append(n.Children, child)
+		n.AddSize(child.Size())
+		n.AddUsage(child.Usage())
+		n.AddCounts(child.Entries())
+		n.AddErrors(child.Unreadable())
+	}
+	return n, d.expect(json.Delim(']'))
+}
+
+// file parses a non-directory entry whose opening brace was already consumed.
+func (d *decoder) file(parent *scanner.Node, parentDev uint64) (*scanner.Node, error) {
+	info, err := d.fields()
+	if err != nil {
+		return nil, err
+	}
+	if info.Dev == 0 {
+		info.Dev = parentDev
+	}
+	n := newNode(parent, info)
+	if info.HardLink {
+		n.Inode = &scanner.Inode{Dev: info.Dev, Ino: info.Ino, Size: info.ASize, Usage: info.DSize}
+		id := inode{dev: info.Dev, ino: info.Ino}
+		if _, seen := d.links[id]; seen {
+			n.IsHardlink = true
+			n.SetSize(0)
+			n.SetUsage(0)
+		}
+		d.links[id] = struct{}{}
+	}
+	return n, nil
+}
+
+// newNode converts a parsed entry into a Node carrying its own sizes.
+func newNode(parent *scanner.Node, info entry) *scanner.Node {
+	n := &scanner.Node{Parent: parent, Name: info.Name}
+	n.SetSize(info.ASize)
+	n.SetUsage(info.DSize)
+	if info.ReadError {
+		n.Err = errors.New("read error")
+	}
+	if info.NotReg {
+		n.Type = fs.ModeIrregular // ncdu doesn't say which kind
+	}
+	switch info.Excluded {
+	case "otherfs", "othfs", "kernfs":
+		n.IsDir = true
+		n.IsMount = true
+		n.Type = fs.ModeDir
+	}
+	return n
+}
+
+// entry parses an information object including its opening brace.
+func (d *decoder) entry() (entry, error) {
+	if err := d.expect(json.Delim('{')); err != nil {
+		return entry{}, err
+	}
+	return d.fields()
+}
+
+// fields parses the members of an information object up to and including
+// its closing brace. Unknown members are skipped.
+func (d *decoder) fields() (entry, error) {
+	var e entry
+	for d.dec.More() {
+		tok, err := d.dec.Token()
+		if err != nil {
+			return e, err
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return e, fmt.Errorf("expected object key, got %v", tok)
+		}
+		var dst any
+		switch key {
+		case "name":
+			dst = &e.Name
+		case "asize":
+			dst = &e.ASize
+		case "dsize":
+			dst = &e.DSize
+		case "dev":
+			dst = &e.Dev
+		case "ino":
+			dst = &e.Ino
+		case "hlnkc":
+			dst = &e.HardLink
+		case "notreg":
+			dst = &e.NotReg
+		case "read_error":
+			dst = &e.ReadError
+		case "excluded":
+			dst = &e.Excluded
+		default:
+			dst = new(json.RawMessage)
+		}
+		if err := d.dec.Decode(dst); err != nil {
+			return e, fmt.Errorf("field %q: %w", key, err)
+		}
+	}
+	return e, d.expect(json.Delim('}'))
+}
+
+// expect consumes the next token and checks that it is want.
+func (d *decoder) expect(want json.Delim) error {
+	tok, err := d.dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != want {
+		return fmt.Errorf("expected %v, got %v", want, tok)
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package ncdu_test
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mobanhawi/aster/internal/ncdu"
+	"github.com/mobanhawi/aster/internal/scanner"
+	"github.com/mobanhawi/aster/internal/scanner/scannertest"
+)
+
+// ── Helpers ──────────────────────────────────────────────────────────────────
+
+// assertSameTree compares what a dump records of two trees recursively.
+// Directories' own sizes are left out: Export doesn't write them, as scans
+// don't count them. Of the file type only "not regular" survives a dump.
+func assertSameTree(t *testing.T, want, got *scanner.Node) {
+	t.Helper()
+	if got.Name != want.Name || got.IsDir != want.IsDir || got.IsHardlink != want.IsHardlink ||
+		(got.Type == 0) != (want.Type == 0) || !want.IsDir && (got.Size() != want.Size() || got.Usage() != want.Usage()) {
+		t.Errorf("%s: got %q dir=%v shared=%v %d/%d, want dir=%v shared=%v %d/%d", want.Name, got.Name,
+			got.IsDir, got.IsHardlink, got.Size(), got.Usage(), want.IsDir, want.IsHardlink, want.Size(), want.Usage())
+	}
+	if (got.Inode == nil) != (want.Inode == nil) || (got.Inode != nil && *got.Inode != *want.Inode) {
+		t.Errorf("%s: Inode = %+v, want %+v", want.Name, got.Inode, want.Inode)
+	}
+	if len(got.Children) != len(want.Children) {
+		t.Fatalf("%s: %d children, want %d", want.Name, len(got.Children), len(want.Children))
+	}
+	for i := range want.Children {
+		assertSameTree(t, want.Children[i], got.Children[i])
+	}
+}
+
+// sampleDump is a trimmed ncdu 1.x export with a hardlinked pair, a read
+// error, an excluded mount and fields aster doesn't use.
+const sampleDump = `[1,2,{"progname":"ncdu","progver":"1.19","timestamp":1760000000},
+[{"name":"/srv","asize":4096,"dsize":4096,"dev":42},
+ {"name":"a.bin","asize":1000,"dsize":4096,"ino":7,"hlnkc":true,"nlink":2},
+ [{"name":"sub","asize":4096,"dsize":4096,"mtime":1700000000},
+  {"name":"b.bin","asize":1000,"dsize":4096,"ino":7,"hlnkc":true,"nlink":2},
+  {"name":"c.txt","asize":10,"dsize":4096,"extra":{"nested":[1,2]}}],
+ [{"name":"locked","read_error":true}],
+ {"name":"proc","excluded":"otherfs"}
+]]`
+
+// ── Tests ────────────────────────────────────────────────────────────────────
+
+func TestImport(t *testing.T) {
+	root, meta, err := ncdu.Import(strings.NewReader(sampleDump))
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+
+	t.Run("GivenDump_WhenImported_ThenMetadataRead", func(t *testing.T) {
+		if meta.ProgName != "ncdu" || meta.ProgVersion != "1.19" || !meta.Timestamp.Equal(time.Unix(1760000000, 0)) {
+			t.Errorf("meta = %+v", meta)
+		}
+	})
+
+	t.Run("GivenHardlinkPair_WhenImported_ThenInodeCountedOnce", func(t *testing.T) {
+		// 4096 (/srv) + 1000 (a.bin) + 4096 (sub) + 10 (c.txt); b.bin is a repeat.
+		if want := int64(4096 + 1000 + 4096 + 10); root.Size() != want {
+			t.Errorf("root Size() = %d, want %d", root.Size(), want)
+		}
+		b := root.Children[1].Children[0]
+		if b.Name != "b.bin" || !b.IsHardlink || b.Size() != 0 {
+			t.Errorf("b.bin: IsHardlink=%v Size=%d, want shared and zero", b.IsHardlink, b.Size())
+		}
+	})
+
+	t.Run("GivenFlags_WhenImported_ThenErrorsAndMountsMapped", func(t *testing.T) {
+		locked, proc := root.Children[2], root.Children[3]
+		if !locked.IsDir || locked.Err == nil {
+			t.Errorf("locked: IsDir=%v Err=%v, want dir with error", locked.IsDir, locked.Err)
+		}
+		if !proc.IsMount || !proc.IsDir {
+			t.Errorf("proc: IsMount=%v IsDir=%v, want excluded mount", proc.IsMount, proc.IsDir)
+		}
+		if root.Children[1].Children[1].Parent != root.Children[1] {
+			t.Error("Parent links not set")
+		}
+	})
+}
+
+func TestExportRoundTrip(t *testing.T) {
+	t.Run("GivenTree_WhenExportedAndImported_ThenShapeAndTotalsPreserved", func(t *testing.T) {
+		big := scannertest.Node("big.bin", false, 1000)
+		shared := scannertest.Node("link.bin", false, 0)
+		shared.IsHardlink = true
+		big.Inode = &scanner.Inode{Dev: 1, Ino: 9, Size: 1000, Usage: 2000}
+		shared.Inode = big.Inode
+		mnt := scannertest.Node("mnt", true, 0)
+		mnt.IsMount = true
+		locked := scannertest.Node("locked", true, 0)
+		locked.Err = errors.New("permission denied")
+		weird := scannertest.Node(`quote"and\backslash`, false, 5)
+		pipe := scannertest.Node("pipe", false, 0)
+		pipe.Type = fs.ModeNamedPipe
+		tree := scannertest.Node("/data", true, 1505,
+			scannertest.Node("sub", true, 1000, big, shared),
+			scannertest.Node("small.txt", false, 500),
+			weird, mnt, locked, pipe,
+		)
+
+		var buf bytes.Buffer
+		if err := ncdu.Export(&buf, tree, ncdu.Meta{ProgName: "aster", ProgVersion: "test", Timestamp: time.Unix(1, 0)}); err != nil {
+			t.Fatalf("Export: %v", err)
+		}
+		got, meta, err := ncdu.Import(&buf)
+		if err != nil {
+			t.Fatalf("Import: %v\n%s", err, buf.String())
+		}
+
+		if meta.ProgName != "aster" {
+			t.Errorf("progname = %q, want aster", meta.ProgName)
+		}
+		if got.Name != "/data" || got.Size() != tree.Size() || got.Usage() != tree.Usage() {
+			t.Errorf("root = %q %d/%d, want /data %d/%d", got.Name, got.Size(), got.Usage(), tree.Size(), tree.Usage())
+		}
+		if len(got.Children) != 6 || got.Children[2].Name != weird.Name {
+			t.Fatalf("children not preserved: %+v", got.Children)
+		}
+		if !got.Children[0].Children[1].IsHardlink || !got.Children[3].IsMount || got.Children[4].Err == nil {
+			t.Error("hardlink, mount or error flag lost in round trip")
+		}
+		if got.Children[5].Type == 0 || got.Children[1].Type != 0 {
+			t.Errorf("pipe/small.txt types = %v/%v, want only the pipe irregular", got.Children[5].Type, got.Children[1].Type)
+		}
+	})
+
+	t.Run("GivenNcduDump_WhenImportedExportedAndImported_ThenHardlinksSurvive", func(t *testing.T) {
+		fixture, err := os.ReadFile("testdata/ncdu-1.18.json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		first, _, err := ncdu.Import(bytes.NewReader(fixture))
+		if err != nil {
+			t.Fatalf("Import fixture: %v", err)
+		}
+		// copy.bin is met first and charged; original.bin is its repeat.
+		if first.Size() != 13316 || first.Usage() != 20480 {
+			t.Errorf("fixture size/usage = %d/%d, want 13316/20480", first.Size(), first.Usage())
+		}
+
+		var buf bytes.Buffer
+		if err := ncdu.Export(&buf, first, ncdu.Meta{ProgName: "aster"}); err != nil {
+			t.Fatalf("Export: %v", err)
+		}
+		if latest := first.Children[2]; latest.Name != "latest" || latest.Type == 0 {
+			t.Errorf("%s: Type = %v, want notreg imported", latest.Name, latest.Type)
+		}
+		if !strings.Contains(buf.String(), `{"name":"latest","asize":16,"dsize":0,"notreg":true}`) {
+			t.Errorf("latest not exported as notreg:\n%s", buf.String())
+		}
+		link := `"asize":1000,"dsize":4096,"dev":2049,"ino":1311750,"hlnkc":true`
+		if n := strings.Count(buf.String(), link); n != 2 {
+			t.Errorf("exported %d links with %s, want both\n%s", n, link, buf.String())
+		}
+		second, _, err := ncdu.Import(&buf)
+		if err != nil {
+			t.Fatalf("Import export: %v", err)
+		}
+		assertSameTree(t, first, second)
+	})
+}
+
+func TestImportRejectsBadInput(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+	}{
+		{"GivenNotJSON_WhenImported_ThenError", "hello"},
+		{"GivenWrongMajorVersion_WhenImported_ThenError", `[2,0,{},[{"name":"/"}]]`},
+		{"GivenTruncatedDump_WhenImported_ThenError", `[1,2,{},[{"name":"/"},{"name":"a"`},
+		{"GivenScalarEntry_WhenImported_ThenError", `[1,2,{},[{"name":"/"},42]]`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, _, err := ncdu.Import(strings.NewReader(tc.input)); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package preview
+
+import "io/fs"
+
+// owner always reports nothing: ownership is not exposed here.
+func owner(_ fs.FileInfo) string {
+	return ""
+}
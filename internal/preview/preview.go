@@ -0,0 +1,130 @@
+// Package preview inspects a single entry for the browser's preview pane:
+// the metadata a scan does not keep (mode, owner), what kind of content a
+// file holds and, for text, its first lines.
+package preview
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// headSize is how much of a file is read to sniff its type and show its first
+// lines: enough for a screenful, little enough to preview files on slow disks.
+const headSize = 4096
+
+// tabWidth is how many spaces a tab expands to in previewed lines.
+const tabWidth = 4
+
+// Info describes one entry.
+type Info struct {
+	Mode fs.FileMode
+	// Owner is the owning user's name, or their numeric ID when it has no
+	// name; empty where ownership is not exposed.
+	Owner string
+	// Type is the MIME type of a regular file's content, e.g.
+	// "text/plain; charset=utf-8", sniffed from its first bytes or, failing
+	// that, guessed from its extension. Empty for anything else.
+	Type string
+	// Lines are the first lines of a text file, with tabs expanded and
+	// control characters replaced so they can be printed safely. Nil for
+	// files that are not text.
+	Lines []string
+}
+
+// Inspect describes the entry at path without following a final symlink.
+// Only regular files are opened, reading at most their first few kilobytes,
+// and at most maxLines lines are kept.
+func Inspect(path string, maxLines int) (*Info, error) {
+	fi, err := os.Lstat(path)
+	if err != nil {
+		return nil, err
+	}
+	info := &Info{Mode: fi.Mode(), Owner: owner(fi)}
+	if !fi.Mode().IsRegular() {
+		return info, nil
+	}
+
+	f, err := os.Open(path) // #nosec G304 -- previewing entries of the scanned tree is the point
+	if err != nil {
+		return info, err
+	}
+	head := make([]byte, headSize)
+	n, err := io.ReadFull(f, head)
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		err = nil // shorter than headSize
+	}
+	if err = errors.Join(err, f.Close()); err != nil {
+		return info, err
+	}
+	head = head[:n]
+
+	info.Type = http.DetectContentType(head)
+	if info.Type == "application/octet-stream" {
+		if t := mime.TypeByExtension(filepath.Ext(path)); t != "" {
+			info.Type = t
+		}
+	}
+	if text, ok := asText(head, n == headSize); ok {
+		info.Lines = lines(text, maxLines)
+	}
+	return info, nil
+}
+
+// asText returns head as a string if it looks like text: valid UTF-8 without
+// NUL bytes. A truncated head may end in the middle of a character, which is
+// cut off.
+func asText(head []byte, truncated bool) (string, bool) {
+	if bytes.IndexByte(head, 0) >= 0 {
+		return "", false
+	}
+	if truncated {
+		for i := 0; i < utf8.UTFMax && len(head) > 0 && !utf8.Valid(head); i++ {
+			head = head[:len(head)-1]
+		}
+	}
+	return string(head), utf8.Valid(head)
+}
+
+// lines splits text into at most maxLines printable lines.
+func lines(text string, maxLines int) []string {
+	text = strings.TrimSuffix(text, "\n")
+	if text == "" || maxLines <= 0 {
+		return []string{}
+	}
+	split := strings.SplitN(text, "\n", maxLines+1)
+	out := split[:min(len(split), maxLines)]
+	for i, l := range out {
+		out[i] = printable(strings.TrimSuffix(l, "\r"))
+	}
+	return out
+}
+
+// printable expands tabs and replaces other control characters, which could
+// move the cursor or restyle the terminal, with '·'.
+func printable(s string) string {
+	var sb strings.Builder
+	col := 0
+	for _, r := range s {
+		switch {
+		case r == '\t':
+			pad := tabWidth - col%tabWidth
+			sb.WriteString(strings.Repeat(" ", pad))
+			col += pad
+			continue
+		case r == utf8.RuneError, unicode.IsControl(r):
+			r = '·'
+		}
+		sb.WriteRune(r)
+		col++
+	}
+	return sb.String()
+}
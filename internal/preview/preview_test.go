@@ -0,0 +1,130 @@
+package preview_test
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mobanhawi/aster/internal/preview"
+)
+
+// ── Helpers ──────────────────────────────────────────────────────────────────
+
+// write creates name in dir with content and returns its path.
+func write(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// ── Tests ────────────────────────────────────────────────────────────────────
+
+func TestInspect(t *testing.T) {
+	dir := t.TempDir()
+	png := "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"
+	testCases := []struct {
+		name      string
+		content   string
+		maxLines  int
+		wantType  string
+		wantLines []string
+	}{
+		{
+			name:      "GivenTextFile_WhenInspected_ThenFirstLinesKept",
+			content:   "one\r\ntwo\nthree\nfour\n",
+			maxLines:  3,
+			wantType:  "text/plain; charset=utf-8",
+			wantLines: []string{"one", "two", "three"},
+		},
+		{
+			name:      "GivenTabsAndEscapes_WhenInspected_ThenLinesMadePrintable",
+			content:   "a\tb\n\x1b[2Jclear",
+			maxLines:  10,
+			wantType:  "text/plain; charset=utf-8",
+			wantLines: []string{"a   b", "·[2Jclear"},
+		},
+		{
+			name:     "GivenBinaryFile_WhenInspected_ThenTypeSniffedAndNoLines",
+			content:  png,
+			maxLines: 10,
+			wantType: "image/png",
+		},
+		{
+			name:      "GivenEmptyFile_WhenInspected_ThenNoLinesButText",
+			content:   "",
+			maxLines:  10,
+			wantType:  "text/plain; charset=utf-8",
+			wantLines: []string{},
+		},
+		{
+			name:      "GivenLongFile_WhenInspected_ThenOnlyTheHeadRead",
+			content:   strings.Repeat("é", 3000),
+			maxLines:  10,
+			wantType:  "text/plain; charset=utf-8",
+			wantLines: []string{strings.Repeat("é", 2048)},
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := write(t, dir, "f"+string(rune('a'+i)), tc.content)
+			info, err := preview.Inspect(path, tc.maxLines)
+			if err != nil {
+				t.Fatalf("Inspect: %v", err)
+			}
+			if !info.Mode.IsRegular() || info.Mode.Perm() != 0o600 {
+				t.Errorf("Mode = %v, want -rw-------", info.Mode)
+			}
+			if info.Type != tc.wantType {
+				t.Errorf("Type = %q, want %q", info.Type, tc.wantType)
+			}
+			if !slices.Equal(info.Lines, tc.wantLines) || (info.Lines == nil) != (tc.wantLines == nil) {
+				t.Errorf("Lines = %q, want %q", info.Lines, tc.wantLines)
+			}
+		})
+	}
+
+	t.Run("GivenSymlink_WhenInspected_ThenLinkDescribedNotTarget", func(t *testing.T) {
+		target := write(t, dir, "target.txt", "hello\n")
+		link := filepath.Join(dir, "link")
+		if err := os.Symlink(target, link); err != nil {
+			t.Skip("symlinks unsupported:", err)
+		}
+		info, err := preview.Inspect(link, 10)
+		if err != nil {
+			t.Fatalf("Inspect: %v", err)
+		}
+		if info.Mode&os.ModeSymlink == 0 || info.Type != "" || info.Lines != nil {
+			t.Errorf("got %+v, want a symlink with no content read", info)
+		}
+	})
+
+	t.Run("GivenOwnFile_WhenInspected_ThenOwnerIsCurrentUser", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("ownership is not exposed on windows")
+		}
+		u, err := user.Current()
+		if err != nil {
+			t.Skip("no current user:", err)
+		}
+		info, err := preview.Inspect(write(t, dir, "mine", "x"), 1)
+		if err != nil {
+			t.Fatalf("Inspect: %v", err)
+		}
+		if info.Owner != u.Username && info.Owner != u.Uid {
+			t.Errorf("Owner = %q, want %q", info.Owner, u.Username)
+		}
+	})
+
+	t.Run("GivenMissingFile_WhenInspected_ThenError", func(t *testing.T) {
+		if _, err := preview.Inspect(filepath.Join(dir, "missing"), 1); err == nil {
+			t.Error("Inspect succeeded, want an error")
+		}
+	})
+}
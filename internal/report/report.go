@@ -0,0 +1,208 @@
+// Package report ranks the largest paths of a scanned tree and formats them
+// for non-interactive use: cron jobs, CI logs and SSH sessions without a TTY.
+package report
+
+import (
+	"cmp"
+	"container/heap"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mobanhawi/aster/internal/format"
+	"github.com/mobanhawi/aster/internal/scanner"
+)
+
+// Options controls which paths make it into a report.
+type Options struct {
+	// Top is how many directories and how many files to keep.
+	Top int
+	// RankDepth limits how far below the root entries are considered; zero
+	// means no limit. Direct children of the root are at depth 1.
+	RankDepth int
+	// MinSize drops entries smaller than this many bytes.
+	MinSize int64
+}
+
+// Entry is one ranked path.
+type Entry struct {
+	Path  string `json:"path"`
+	Size  int64  `json:"size"`
+	Usage int64  `json:"usage"`
+	// Percent is the share of the root's apparent size, 0–100.
+	Percent float64 `json:"percent"`
+}
+
+// Report holds the largest directories and files below Root, biggest first.
+type Report struct {
+	Root        string  `json:"root"`
+	Size        int64   `json:"size"`
+	Usage       int64   `json:"usage"`
+	Directories []Entry `json:"directories"`
+	Files       []Entry `json:"files"`
+}
+
+// Build walks root and keeps the opts.Top largest directories and files.
+// The root itself is not ranked.
+func Build(root *scanner.Node, opts Options) *Report {
+	dirs := &topN{limit: opts.Top}
+	files := &topN{limit: opts.Top}
+
+	var walk func(n *scanner.Node, depth int)
+	walk = func(n *scanner.Node, depth int) {
+		if opts.RankDepth > 0 && depth > opts.RankDepth {
+			return
+		}
+		for _, c := range n.Children {
+			if c.Size() < opts.MinSize {
+				continue
+			}
+			if c.IsDir {
+				dirs.offer(c)
+				walk(c, depth+1)
+			} else {
+				files.offer(c)
+			}
+		}
+	}
+	walk(root, 1)
+
+	r := &Report{Root: root.FullPath(), Size: root.Size(), Usage: root.Usage()}
+	r.Directories = r.entries(dirs.sorted())
+	r.Files = r.entries(files.sorted())
+	return r
+}
+
+func (r *Report) entries(nodes []*scanner.Node) []Entry {
+	out := make([]Entry, len(nodes))
+	for i, n := range nodes {
+		out[i] = Entry{Path: n.FullPath(), Size: n.Size(), Usage: n.Usage()}
+		if r.Size > 0 {
+			out[i].Percent = float64(n.Size()) / float64(r.Size) * 100
+		}
+	}
+	return out
+}
+
+// WriteTable prints r as two aligned tables with human-readable sizes.
+func (r *Report) WriteTable(w io.Writer) error {
+	sizeW := len(format.Bytes(r.Size))
+	for _, sec := range r.sections() {
+		for _, e := range sec.entries {
+			sizeW = max(sizeW, len(format.Bytes(e.Size)))
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%*s  %6s  %s\n", sizeW, format.Bytes(r.Size), "", r.Root)
+	for _, sec := range r.sections() {
+		fmt.Fprintf(&b, "\n%s\n", sec.title)
+		for _, e := range sec.entries {
+			fmt.Fprintf(&b, "%*s  %5.1f%%  %s\n", sizeW, format.Bytes(e.Size), e.Percent, e.Path)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteJSON prints r as a single indented JSON object. Sizes are in bytes.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteCSV prints one row per entry with a header. Sizes are in bytes.
+func (r *Report) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"type", "path", "size", "usage", "percent"}); err != nil {
+		return err
+	}
+	for _, sec := range r.sections() {
+		for _, e := range sec.entries {
+			if err := cw.Write([]string{
+				sec.kind,
+				e.Path,
+				strconv.FormatInt(e.Size, 10),
+				strconv.FormatInt(e.Usage, 10),
+				strconv.FormatFloat(e.Percent, 'f', 2, 64),
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+type section struct {
+	kind, title string
+	entries     []Entry
+}
+
+func (r *Report) sections() []section {
+	return []section{
+		{"dir", "DIRECTORIES", r.Directories},
+		{"file", "FILES", r.Files},
+	}
+}
+
+// topN keeps the limit largest nodes offered to it in a min-heap, so ranking
+// a million-entry tree never sorts more than limit items. Ties are broken by
+// path, so which of several equal nodes make the cut doesn't depend on the
+// order they are offered in.
+type topN struct {
+	limit int
+	nodes []*scanner.Node
+}
+
+// below reports whether a ranks below b: it is smaller, or as large with a
+// later path.
+func below(a, b *scanner.Node) bool {
+	if sa, sb := a.Size(), b.Size(); sa != sb {
+		return sa < sb
+	}
+	return a.FullPath() > b.FullPath()
+}
+
+func (t *topN) Len() int           { return len(t.nodes) }
+func (t *topN) Less(i, j int) bool { return below(t.nodes[i], t.nodes[j]) }
+func (t *topN) Swap(i, j int)      { t.nodes[i], t.nodes[j] = t.nodes[j], t.nodes[i] }
+func (t *topN) Push(x any) {
+	if n, ok := x.(*scanner.Node); ok {
+		t.nodes = append(t.nodes, n)
+	}
+}
+
+func (t *topN) Pop() any {
+	last := t.nodes[len(t.nodes)-1]
+	t.nodes = t.nodes[:len(t.nodes)-1]
+	return last
+}
+
+func (t *topN) offer(n *scanner.Node) {
+	switch {
+	case t.limit <= 0:
+	case len(t.nodes) < t.limit:
+		heap.Push(t, n)
+	case below(t.nodes[0], n):
+		t.nodes[0] = n
+		heap.Fix(t, 0)
+	}
+}
+
+// sorted returns the kept nodes largest first, ties broken by path.
+func (t *topN) sorted() []*scanner.Node {
+	out := slices.Clone(t.nodes)
+	slices.SortFunc(out, func(a, b *scanner.Node) int {
+		if c := cmp.Compare(b.Size(), a.Size()); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.FullPath(), b.FullPath())
+	})
+	return out
+}
@@ -0,0 +1,127 @@
+package report_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mobanhawi/aster/internal/report"
+	"github.com/mobanhawi/aster/internal/scanner/scannertest"
+)
+
+// ── Helpers ──────────────────────────────────────────────────────────────────
+
+func paths(entries []report.Entry) []string {
+	out := make([]string, len(entries))
+	for i, e := range entries {
+		out[i] = e.Path
+	}
+	return out
+}
+
+// ── Tests ────────────────────────────────────────────────────────────────────
+
+func TestBuild(t *testing.T) {
+	testCases := []struct {
+		name      string
+		opts      report.Options
+		wantDirs  []string
+		wantFiles []string
+	}{
+		{
+			name:      "GivenTopTwo_WhenBuilt_ThenLargestFirst",
+			opts:      report.Options{Top: 2},
+			wantDirs:  []string{"/data/media", "/data/build"},
+			wantFiles: []string{"/data/media/movie.MKV", "/data/build/main.o"},
+		},
+		{
+			name:      "GivenDepthOne_WhenBuilt_ThenOnlyRootChildren",
+			opts:      report.Options{Top: 10, RankDepth: 1},
+			wantDirs:  []string{"/data/media", "/data/build"},
+			wantFiles: []string{"/data/Makefile", "/data/.bashrc"},
+		},
+		{
+			name:      "GivenMinSize_WhenBuilt_ThenSmallEntriesDropped",
+			opts:      report.Options{Top: 10, MinSize: 1500},
+			wantDirs:  []string{"/data/media", "/data/build", "/data/media/clips"},
+			wantFiles: []string{"/data/media/movie.MKV", "/data/build/main.o"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := report.Build(scannertest.SampleTree(), tc.opts)
+			if got := paths(r.Directories); strings.Join(got, ",") != strings.Join(tc.wantDirs, ",") {
+				t.Errorf("Directories = %v, want %v", got, tc.wantDirs)
+			}
+			if got := paths(r.Files); strings.Join(got, ",") != strings.Join(tc.wantFiles, ",") {
+				t.Errorf("Files = %v, want %v", got, tc.wantFiles)
+			}
+		})
+	}
+
+	t.Run("GivenTieAtTheCut_WhenBuilt_ThenEarlierPathKept", func(t *testing.T) {
+		// z.bin is offered first, but a.bin sorts first and wins the tie.
+		root := scannertest.Node("/t", true, 1000, scannertest.Node("z", true, 500, scannertest.Node("z.bin", false, 500)), scannertest.Node("a.bin", false, 500))
+		if got := paths(report.Build(root, report.Options{Top: 1}).Files); len(got) != 1 || got[0] != "/t/a.bin" {
+			t.Errorf("Files = %v, want [/t/a.bin]", got)
+		}
+	})
+
+	t.Run("GivenEntries_WhenBuilt_ThenPercentOfRoot", func(t *testing.T) {
+		r := report.Build(scannertest.SampleTree(), report.Options{Top: 1})
+		if r.Root != "/data" || r.Size != 10000 {
+			t.Errorf("root = %q %d, want /data 10000", r.Root, r.Size)
+		}
+		if p := r.Directories[0].Percent; p != 70 {
+			t.Errorf("Percent = %v, want 70", p)
+		}
+	})
+}
+
+func TestWriters(t *testing.T) {
+	r := report.Build(scannertest.SampleTree(), report.Options{Top: 2})
+
+	t.Run("GivenReport_WhenTable_ThenHumanSizesAligned", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := r.WriteTable(&buf); err != nil {
+			t.Fatalf("WriteTable: %v", err)
+		}
+		out := buf.String()
+		for _, want := range []string{"DIRECTORIES", "FILES", "7.0 kB   70.0%  /data/media", "/data/media/movie.MKV"} {
+			if !strings.Contains(out, want) {
+				t.Errorf("table missing %q:\n%s", want, out)
+			}
+		}
+	})
+
+	t.Run("GivenReport_WhenJSON_ThenRoundTrips", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := r.WriteJSON(&buf); err != nil {
+			t.Fatalf("WriteJSON: %v", err)
+		}
+		var got report.Report
+		if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+			t.Fatalf("invalid JSON: %v", err)
+		}
+		if got.Root != "/data" || len(got.Directories) != 2 || got.Files[0].Size != 5000 {
+			t.Errorf("decoded = %+v", got)
+		}
+	})
+
+	t.Run("GivenReport_WhenCSV_ThenHeaderAndRowPerEntry", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := r.WriteCSV(&buf); err != nil {
+			t.Fatalf("WriteCSV: %v", err)
+		}
+		rows, err := csv.NewReader(&buf).ReadAll()
+		if err != nil {
+			t.Fatalf("invalid CSV: %v", err)
+		}
+		if len(rows) != 5 || rows[0][0] != "type" || rows[1][0] != "dir" || rows[3][0] != "file" || rows[3][2] != "5000" {
+			t.Errorf("rows = %v", rows)
+		}
+	})
+}
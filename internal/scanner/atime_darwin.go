@@ -0,0 +1,16 @@
+//go:build darwin
+
+package scanner
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// accessTime returns info's last access time in Unix nanoseconds.
+func accessTime(info fs.FileInfo) int64 {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return st.Atimespec.Nano()
+	}
+	return 0
+}
@@ -0,0 +1,10 @@
+//go:build !linux && !darwin
+
+package scanner
+
+import "io/fs"
+
+// accessTime always reports zero: access times are not exposed here.
+func accessTime(_ fs.FileInfo) int64 {
+	return 0
+}
@@ -2,6 +2,7 @@ package scanner
 
 import (
 	"cmp"
+	"io/fs"
 	"os"
 	"slices"
 	"strings"
@@ -24,17 +25,79 @@ type Node struct {
 	// size is atomic to support concurrent updates during scanning.
 	size atomic.Int64
 
+	// usage is the allocated (on-disk) size in bytes, maintained alongside
+	// size. It differs from size for sparse files and block-rounded small files.
+	usage atomic.Int64
+
+	// files and dirs count the files and directories below the node,
+	// recursively, maintained atomically alongside size.
+	files atomic.Int64
+	dirs  atomic.Int64
+
+	// errs counts the entries below the node, recursively, that could not
+	// be read, maintained alongside files and dirs.
+	errs atomic.Int64
+
 	// sortGen tracks the sort-mode generation (O(1) staleness check).
 	sortGen uint64
 
 	// Err stores any error encountered during scan of this node.
 	Err error
 
+	// LinkTarget is the raw target of a symlink when symlinks are followed,
+	// empty for other entries. Plain scans don't read it, which would cost a
+	// readlink per link.
+	LinkTarget string
+
+	// newest is the latest ModTime in the subtree, maintained atomically
+	// alongside size.
+	newest atomic.Int64
+
+	// ModTime is the entry's modification time in Unix nanoseconds, zero
+	// when unknown. A directory's changes whenever an entry is added, removed
+	// or renamed, which is what lets Refresh skip unchanged directories.
+	ModTime int64
+
+	// AccessTime is a file's last access time in Unix nanoseconds, zero when
+	// unknown or not recorded by the platform. Directories leave it zero.
+	AccessTime int64
+
+	// Type is the entry's own file type, the fs.ModeType bits of an lstat:
+	// zero for a regular file, fs.ModeSymlink for a link whether or not it
+	// was followed, fs.ModeNamedPipe for a FIFO and so on.
+	Type fs.FileMode
+
 	// SortedMode tracks the last SortMode used (e.g. size vs name).
 	SortedMode int8
 
 	// IsDir marks if this node can have children.
 	IsDir bool
+
+	// IsHardlink marks a file whose inode was already counted through another
+	// link. Its size is zero so each inode contributes to the totals once.
+	IsHardlink bool
+
+	// Inode identifies a file with more than one hard link, on every one of
+	// its links, so exports can name them all. Nil for everything else.
+	Inode *Inode
+
+	// IsMount marks a directory on another filesystem that was not descended
+	// into because the scan was restricted to one filesystem.
+	IsMount bool
+}
+
+// Inode describes a hardlinked file's inode.
+type Inode struct {
+	Dev, Ino uint64
+	// Size and Usage are the inode's own sizes, which a repeat link does not
+	// carry.
+	Size, Usage int64
+}
+
+// fileID identifies an inode uniquely across mounted filesystems.
+type fileID struct {
+	dev uint64
+	ino uint64
 }
 
 // FullPath reconstructs the absolute path by walking up to the root.
@@ -88,6 +151,299 @@ func (n *Node) SetSize(bytes int64) {
 	n.size.Store(bytes)
 }
 
+// Usage returns the allocated disk usage in bytes (recursive for dirs).
+func (n *Node) Usage() int64 {
+	return n.usage.Load()
+}
+
+// AddUsage atomically adds bytes to this node's allocated-usage counter.
+func (n *Node) AddUsage(bytes int64) {
+	n.usage.Add(bytes)
+}
+
+// SetUsage sets the allocated usage directly (non-concurrent use only).
+func (n *Node) SetUsage(bytes int64) {
+	n.usage.Store(bytes)
+}
+
+// Files returns the number of files below n, recursively. Symlinks, hard
+// links and other non-directories count as files.
+func (n *Node) Files() int64 {
+	return n.files.Load()
+}
+
+// Dirs returns the number of directories below n, recursively.
+func (n *Node) Dirs() int64 {
+	return n.dirs.Load()
+}
+
+// Items returns the number of entries below n, recursively.
+func (n *Node) Items() int64 {
+	return n.Files() + n.Dirs()
+}
+
+// AddCounts atomically adds to n's file and directory counts.
+func (n *Node) AddCounts(files, dirs int64) {
+	n.files.Add(files)
+	n.dirs.Add(dirs)
+}
+
+// SetCounts sets the file and directory counts directly (non-concurrent use
+// only).
+func (n *Node) SetCounts(files, dirs int64) {
+	n.files.Store(files)
+	n.dirs.Store(dirs)
+}
+
+// Entries returns the files and directories in n's subtree with n itself
+// included: what n adds to its parent's counts.
+func (n *Node) Entries() (files, dirs int64) {
+	if n.IsDir {
+		return n.Files(), n.Dirs() + 1
+	}
+	return n.Files() + 1, n.Dirs()
+}
+
+// Errors returns the number of entries below n, recursively, whose Err is
+// set.
+func (n *Node) Errors() int64 {
+	return n.errs.Load()
+}
+
+// AddErrors atomically adds to n's error count.
+func (n *Node) AddErrors(errs int64) {
+	n.errs.Add(errs)
+}
+
+// SetErrors sets the error count directly (non-concurrent use only).
+func (n *Node) SetErrors(errs int64) {
+	n.errs.Store(errs)
+}
+
+// Unreadable returns the entries in n's subtree that could not be read with
+// n itself included: what n adds to its parent's error count.
+func (n *Node) Unreadable() int64 {
+	return n.Errors() + errCount(n.Err)
+}
+
+// Newest returns the latest ModTime of n and everything below it, zero when
+// unknown. Removing entries does not lower it; a rescan does.
+func (n *Node) Newest() int64 {
+	return n.newest.Load()
+}
+
+// SetNewest sets Newest directly (non-concurrent use only).
+func (n *Node) SetNewest(t int64) {
+	n.newest.Store(t)
+}
+
+// raiseNewest atomically moves Newest forward to t if t is later.
+func (n *Node) raiseNewest(t int64) {
+	raiseMax(&n.newest, t)
+}
+
+// raiseMax atomically moves v forward to t if t is larger.
+func raiseMax(v *atomic.Int64, t int64) {
+	for {
+		cur := v.Load()
+		if t <= cur || v.CompareAndSwap(cur, t) {
+			return
+		}
+	}
+}
+
+// raiseNewestUp raises Newest to t on n and every ancestor.
+func (n *Node) raiseNewestUp(t int64) {
+	for a := n; a != nil; a = a.Parent {
+		a.raiseNewest(t)
+	}
+}
+
+// RemoveChild detaches c from n and subtracts its size, usage, entries and
+// errors from n and every ancestor. It reports false if c is not one of n's children. c keeps
+// its own counters and subtree so it can be re-attached with AddChild.
+func (n *Node) RemoveChild(c *Node) bool {
+	i := slices.Index(n.Children, c)
+	if i < 0 {
+		return false
+	}
+	n.Children = slices.Delete(n.Children, i, i+1)
+	n.resize(-c.Size(), -c.Usage())
+	files, dirs := c.Entries()
+	n.recount(-files, -dirs, -c.Unreadable())
+	return true
+}
+
+// AddChild attaches c under n and adds its size, usage, entries and errors
+// to n and every ancestor, which are marked unsorted so the next render places c correctly.
+func (n *Node) AddChild(c *Node) {
+	c.Parent = n
+	n.Children = append(n.Children, c)
+	n.resize(c.Size(), c.Usage())
+	files, dirs := c.Entries()
+	n.recount(files, dirs, c.Unreadable())
+	n.raiseNewestUp(c.Newest())
+}
+
+// resize adds the given differences to n and every ancestor and marks them
+// unsorted, since a changed size can reorder each listing on the way up.
+func (n *Node) resize(dSize, dUsage int64) {
+	for a := n; a != nil; a = a.Parent {
+		a.AddSize(dSize)
+		a.AddUsage(dUsage)
+		a.sortGen = 0
+	}
+}
+
+// recount adds the given differences to the file, directory and error
+// counts of n and every ancestor.
+func (n *Node) recount(dFiles, dDirs, dErrs int64) {
+	if dFiles == 0 && dDirs == 0 && dErrs == 0 {
+		return
+	}
+	for a := n; a != nil; a = a.Parent {
+		a.AddCounts(dFiles, dDirs)
+		a.AddErrors(dErrs)
+	}
+}
+
+// ReplaceWith brings n up to date with fresh, a rescan of n returned by
+// Rescan, and applies the size, usage and count differences to every
+// ancestor.
+// Entries present in both keep their identity, so pointers held elsewhere
+// (navigation, marks) stay valid; vanished entries are dropped and new ones
+// adopted from fresh.
+func (n *Node) ReplaceWith(fresh *Node) {
+	dSize, dUsage := fresh.Size()-n.Size(), fresh.Usage()-n.Usage()
+	oldFiles, oldDirs := n.Entries()
+	oldErrs := n.Unreadable()
+	n.merge(fresh)
+	if n.Parent != nil {
+		files, dirs := n.Entries()
+		n.Parent.resize(dSize, dUsage)
+		n.Parent.recount(files-oldFiles, dirs-oldDirs, n.Unreadable()-oldErrs)
+		n.Parent.raiseNewestUp(n.Newest())
+	}
+}
+
+// merge copies fresh's totals and status into n and reconciles the children
+// by name, recursively.
+func (n *Node) merge(fresh *Node) {
+	n.SetSize(fresh.Size())
+	n.SetUsage(fresh.Usage())
+	n.SetCounts(fresh.Files(), fresh.Dirs())
+	n.SetErrors(fresh.Errors())
+	n.Err = fresh.Err
+	n.IsDir = fresh.IsDir
+	n.Type = fresh.Type
+	n.IsMount = fresh.IsMount
+	n.IsHardlink = fresh.IsHardlink
+	n.Inode = fresh.Inode
+	n.LinkTarget = fresh.LinkTarget
+	n.ModTime = fresh.ModTime
+	n.AccessTime = fresh.AccessTime
+	n.SetNewest(fresh.Newest())
+	n.sortGen = 0
+
+	existing := make(map[string]*Node, len(n.Children))
+	for _, c := range n.Children {
+		existing[c.Name] = c
+	}
+	children := make([]*Node, 0, len(fresh.Children))
+	for _, fc := range fresh.Children {
+		c, ok := existing[fc.Name]
+		if ok && c.IsDir == fc.IsDir {
+			c.merge(fc)
+		} else {
+			c = fc
+		}
+		c.Parent = n
+		children = append(children, c)
+	}
+	if fresh.Children == nil {
+		children = nil
+	}
+	n.Children = children
+}
+
+// Sync updates n's direct children from fresh, a one-level listing of n
+// returned by ReadLevel. Vanished entries are removed, new ones appended and
+// changed files resized, with the size and count differences applied to n
+// and every ancestor. Existing subdirectories are left alone. It returns the newly
+// added directories, whose contents still need a Rescan.
+func (n *Node) Sync(fresh *Node) (added []*Node) {
+	listed := make(map[string]*Node, len(fresh.Children))
+	for _, fc := range fresh.Children {
+		listed[fc.Name] = fc
+	}
+
+	var dSize, dUsage, dFiles, dDirs, dErrs int64
+	kept := n.Children[:0]
+	for _, c := range n.Children {
+		fc, ok := listed[c.Name]
+		switch {
+		case !ok || fc.IsDir != c.IsDir:
+			files, dirs := c.Entries()
+			dSize -= c.Size()
+			dUsage -= c.Usage()
+			dFiles -= files
+			dDirs -= dirs
+			dErrs -= c.Unreadable()
+			continue
+		case !c.IsDir && !c.IsHardlink:
+			dSize += fc.Size() - c.Size()
+			dUsage += fc.Usage() - c.Usage()
+			c.SetSize(fc.Size())
+			c.SetUsage(fc.Usage())
+			c.ModTime = fc.ModTime
+			c.AccessTime = fc.AccessTime
+			c.SetNewest(fc.Newest())
+		}
+		delete(listed, c.Name)
+		kept = append(kept, c)
+	}
+	clear(n.Children[len(kept):]) // drop references to removed nodes
+	n.Children = kept
+
+	// Whatever is left in listed is new. Walk fresh.Children to keep the
+	// order deterministic.
+	for _, fc := range fresh.Children {
+		if _, isNew := listed[fc.Name]; !isNew {
+			continue
+		}
+		fc.Parent = n
+		n.Children = append(n.Children, fc)
+		files, dirs := fc.Entries()
+		dSize += fc.Size()
+		dUsage += fc.Usage()
+		dFiles += files
+		dDirs += dirs
+		dErrs += fc.Unreadable()
+		if fc.IsDir && !fc.IsMount {
+			added = append(added, fc)
+		}
+	}
+
+	if n.Parent != nil {
+		// n's own error is counted by its ancestors, not by n.
+		n.Parent.recount(0, 0, errCount(fresh.Err)-errCount(n.Err))
+	}
+	n.Err = fresh.Err
+	n.ModTime = fresh.ModTime
+	n.resize(dSize, dUsage)
+	n.recount(dFiles, dDirs, dErrs)
+	n.raiseNewestUp(fresh.Newest())
+	return added
+}
+
+// errCount is 1 for a non-nil error.
+func errCount(err error) int64 {
+	if err != nil {
+		return 1
+	}
+	return 0
+}
+
 // IsSorted reports whether this node's children are already sorted.
 func (n *Node) IsSorted(gen uint64, mode int8) bool {
 	return n.sortGen == gen && n.SortedMode == mode
@@ -106,6 +462,13 @@ func (n *Node) SortBySize() {
 	})
 }
 
+// SortByUsage sorts children by allocated usage descending (largest first).
+func (n *Node) SortByUsage() {
+	slices.SortFunc(n.Children, func(a, b *Node) int {
+		return cmp.Compare(b.Usage(), a.Usage())
+	})
+}
+
 // SortByName sorts children alphabetically by name.
 func (n *Node) SortByName() {
 	slices.SortFunc(n.Children, func(a, b *Node) int {
@@ -113,6 +476,29 @@ func (n *Node) SortByName() {
 	})
 }
 
+// SortByAge sorts children by Newest ascending, least recently modified
+// first; entries with unknown times come last.
+func (n *Node) SortByAge() {
+	slices.SortFunc(n.Children, func(a, b *Node) int {
+		ta, tb := a.Newest(), b.Newest()
+		switch {
+		case ta == 0 && tb != 0:
+			return 1
+		case tb == 0 && ta != 0:
+			return -1
+		}
+		return cmp.Compare(ta, tb)
+	})
+}
+
+// SortByCount sorts children by the number of entries below them,
+// descending; files, which hold none, keep their relative order at the end.
+func (n *Node) SortByCount() {
+	slices.SortStableFunc(n.Children, func(a, b *Node) int {
+		return cmp.Compare(b.Items(), a.Items())
+	})
+}
+
 // ResetSorted is kept for backwards compatibility with tests.
 func (n *Node) ResetSorted() {
 	if n == nil {
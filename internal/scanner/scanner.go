@@ -2,6 +2,8 @@ package scanner
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
 	"io/fs"
 	"os"
@@ -12,9 +14,79 @@ import (
 	"sync/atomic"
 )
 
+// Options configures a scan. The zero value scans everything reachable.
+type Options struct {
+	// Exclude lists glob patterns (filepath.Match syntax) for entries to leave
+	// out of the tree entirely. Patterns without a separator are matched
+	// against the entry name, others against the path relative to the root.
+	Exclude []string
+
+	// MaxDepth collapses every directory deeper than this many levels below
+	// the root into a single node carrying its subtree's total; no nodes are
+	// built for the entries inside. Zero means unlimited.
+	MaxDepth int
+
+	// SkipHidden leaves out dotfiles and dot-directories.
+	SkipHidden bool
+
+	// SkipSymlinks leaves symlinks out of the tree instead of listing them
+	// as zero-size entries.
+	SkipSymlinks bool
+
+	// FollowSymlinks counts the targets of symlinks: linked files contribute
+	// their size and linked directories are descended into. Every directory
+	// is visited at most once, so link cycles terminate.
+	FollowSymlinks bool
+
+	// OneFileSystem stops the walk at mount points: directories on a different
+	// device than root are kept in the tree as empty nodes marked IsMount.
+	OneFileSystem bool
+}
+
+// validate reports contradictory settings and malformed Exclude patterns.
+func (o *Options) validate() error {
+	if o.FollowSymlinks && o.SkipSymlinks {
+		return errors.New("FollowSymlinks and SkipSymlinks are mutually exclusive")
+	}
+	for _, pattern := range o.Exclude {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("exclude pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
 // Scan walks the directory tree rooted at root concurrently using a semaphore-
 // limited goroutine-per-directory model.
 func Scan(ctx context.Context, root string, progressCh chan<- int64) (*Node, error) {
+	return ScanWithOptions(ctx, root, progressCh, Options{})
+}
+
+// ScanWithOptions is Scan with the walk configured by opts.
+func ScanWithOptions(ctx context.Context, root string, progressCh chan<- int64, opts Options) (*Node, error) {
+	return scan(ctx, root, progressCh, opts, nil)
+}
+
+// Refresh scans prev's root again and returns a new tree, reusing prev
+// wherever the filesystem shows no change. A directory whose mtime matches
+// the one recorded in prev is not read: its files are copied from prev and
+// only its subdirectories are visited, since changes further down don't
+// reach its mtime. Changed directories are read in full.
+//
+// prev must be a tree root from ScanWithOptions, Refresh or a snapshot, and
+// opts should match the options it was scanned with. Files rewritten in place
+// without touching their directory keep their old size, and hard links are
+// only deduplicated among the directories that are actually read.
+// FollowSymlinks disables reuse.
+func Refresh(ctx context.Context, prev *Node, progressCh chan<- int64, opts Options) (*Node, error) {
+	return scan(ctx, prev.Name, progressCh, opts, prev)
+}
+
+func scan(ctx context.Context, root string, progressCh chan<- int64, opts Options, prev *Node) (*Node, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
 	absRoot, err := filepath.Abs(root)
 	if err != nil {
 		return nil, err
@@ -28,28 +100,104 @@ func Scan(ctx context.Context, root string, progressCh chan<- int64) (*Node, err
 	rootNode := &Node{
 		Name:  absRoot,
 		IsDir: info.IsDir(),
+		Type:  info.Mode().Type(),
 	}
 
 	if !info.IsDir() {
-		rootNode.SetSize(info.Size())
+		setFileInfo(rootNode, info)
 		sendProgress(ctx, progressCh, info.Size())
 		return rootNode, nil
 	}
 
-	numWorkers := runtime.NumCPU() * 32
-	if numWorkers < 256 {
-		numWorkers = 256
+	w := newWalker(opts, absRoot, info, progressCh)
+	if opts.FollowSymlinks {
+		w.claimDir(info)
 	}
-	sem := make(chan struct{}, numWorkers)
-
-	var globalWg sync.WaitGroup
-	globalWg.Add(1)
-	go scanDir(ctx, rootNode, absRoot, nil, sem, progressCh, &globalWg)
-	globalWg.Wait()
+	w.wg.Add(1)
+	go w.scanDir(ctx, rootNode, absRoot, 0, prev, nil)
+	w.wg.Wait()
 
 	return rootNode, nil
 }
 
+// Location places a directory within a scanned tree: everything Rescan
+// needs to know about it, so that the rescan itself never reads the tree,
+// which may change while it runs. Take it with Locate on the goroutine that
+// owns the tree.
+type Location struct {
+	// Path is the directory's absolute path and Name its node's name.
+	Path, Name string
+	// Root is the path of the tree's root and Depth the number of levels
+	// below it.
+	Root  string
+	Depth int
+}
+
+// Locate returns n's Location.
+func Locate(n *Node) Location {
+	root, depth := n, 0
+	for root.Parent != nil {
+		root = root.Parent
+		depth++
+	}
+	return Location{Path: n.FullPath(), Name: n.Name, Root: root.Name, Depth: depth}
+}
+
+// Rescan scans the directory at loc again and returns a fresh, detached node
+// with the same name that can be merged in with ReplaceWith. The walk
+// behaves as part of a scan of the tree root with opts: MaxDepth, path-style
+// Exclude patterns and the OneFileSystem boundary are measured from that
+// root. Hardlinks and followed directories are only deduplicated within the
+// directory.
+func Rescan(ctx context.Context, loc Location, opts Options) (*Node, error) {
+	return rescan(ctx, loc, opts, false)
+}
+
+// ReadLevel lists the directory at loc one level deep, like Rescan but
+// without descending: files carry their sizes and subdirectories come back
+// empty. The result is meant for Sync.
+func ReadLevel(ctx context.Context, loc Location, opts Options) (*Node, error) {
+	return rescan(ctx, loc, opts, true)
+}
+
+func rescan(ctx context.Context, loc Location, opts Options, shallow bool) (*Node, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	info, err := os.Lstat(loc.Path)
+	if err != nil {
+		return nil, err
+	}
+	fresh := &Node{Name: loc.Name, IsDir: info.IsDir(), Type: info.Mode().Type()}
+	if !info.IsDir() {
+		setFileInfo(fresh, info)
+		return fresh, nil
+	}
+
+	rootInfo, err := os.Lstat(loc.Root)
+	if err != nil {
+		rootInfo = info // the root is gone; loc is the best reference left
+	}
+	w := newWalker(opts, loc.Root, rootInfo, nil)
+	w.shallow = shallow
+	if dev, ok := deviceID(info); ok && opts.OneFileSystem && dev != w.rootDev {
+		fresh.IsMount = true
+		return fresh, nil
+	}
+	if opts.FollowSymlinks {
+		w.claimDir(info)
+	}
+	w.wg.Add(1)
+	go w.scanDir(ctx, fresh, loc.Path, loc.Depth, nil, nil)
+	w.wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return fresh, nil
+}
+
 const (
 	// readDirBatchSize controls how many entries we read from disk at once.
 	// Processing in batches keeps peak memory usage low for massive directories
@@ -57,52 +205,120 @@ const (
 	readDirBatchSize = 1024
 )
 
+// walker holds the state shared by every scanDir goroutine of a single Scan.
+type walker struct {
+	opts Options
+	// rootDev is the device of the scan root, used by OneFileSystem.
+	rootDev uint64
+	// rootPrefix is the root path with a trailing separator, stripped to
+	// match path-style Exclude patterns.
+	rootPrefix string
+	// sem bounds the number of directories open at once.
+	sem        chan struct{}
+	progressCh chan<- int64
+	// wg tracks every in-flight scanDir goroutine across the whole tree.
+	wg sync.WaitGroup
+	// links records multiply-linked inodes so each is counted only once.
+	links inodeSet
+	// dirs records every directory entered when following symlinks, so a
+	// link back to an ancestor (or to an already-scanned tree) is not walked.
+	dirs inodeSet
+	// shallow stops the walk after the first directory; subdirectories are
+	// listed but not entered.
+	shallow bool
+}
+
+// newWalker prepares a walk of a tree rooted at absRoot, described by info.
+func newWalker(opts Options, absRoot string, info fs.FileInfo, progressCh chan<- int64) *walker {
+	numWorkers := runtime.NumCPU() * 32
+	if numWorkers < 256 {
+		numWorkers = 256
+	}
+
+	w := &walker{
+		opts:       opts,
+		rootPrefix: dirPrefixOf(absRoot),
+		sem:        make(chan struct{}, numWorkers),
+		progressCh: progressCh,
+	}
+	w.rootDev, _ = deviceID(info)
+	return w
+}
+
+// inodeSet is a concurrency-safe set of file identities.
+type inodeSet struct {
+	mu   sync.Mutex
+	seen map[fileID]struct{}
+}
+
+// claim adds id to the set and reports whether it was not already present,
+// i.e. whether the caller is the first to see this inode.
+func (s *inodeSet) claim(id fileID) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.seen == nil {
+		s.seen = make(map[fileID]struct{})
+	}
+	if _, ok := s.seen[id]; ok {
+		return false
+	}
+	s.seen[id] = struct{}{}
+	return true
+}
+
 // scanDir reads a single directory, processes its file children inline, and
 // spawns a new goroutine (bounded by sem) for each subdirectory child.
-func scanDir(
-	ctx context.Context,
-	node *Node,
-	currentPath string,
-	parentWg *sync.WaitGroup,
-	sem chan struct{},
-	progressCh chan<- int64,
-	globalWg *sync.WaitGroup,
-) {
+// depth is the number of levels below the scan root (the root is 0). prev,
+// when non-nil, is the same directory in an earlier scan, reused if the
+// directory is unchanged (see Refresh).
+func (w *walker) scanDir(ctx context.Context, node *Node, currentPath string, depth int, prev *Node, parentWg *sync.WaitGroup) {
 	var localChildrenWg sync.WaitGroup
 
 	defer func() {
 		localChildrenWg.Wait()
 		if node.Parent != nil {
 			node.Parent.AddSize(node.Size())
+			node.Parent.AddUsage(node.Usage())
+			node.Parent.AddCounts(node.Files(), node.Dirs())
+			node.Parent.AddErrors(node.Unreadable())
+			node.Parent.raiseNewest(node.Newest())
 		}
 		if parentWg != nil {
 			parentWg.Done()
 		}
-		globalWg.Done()
+		w.wg.Done()
 	}()
 
 	if ctx.Err() != nil {
 		return
 	}
+	if prev != nil && w.reuse(ctx, node, prev, currentPath, depth, &localChildrenWg) {
+		return
+	}
+	if w.opts.MaxDepth > 0 && depth >= w.opts.MaxDepth {
+		w.collapse(ctx, node, currentPath)
+		return
+	}
 
 	// Bypassing os.ReadDir to:
 	// 1. Avoid the mandatory alphabetical sort (we sort lazily in UI).
 	// 2. Process in chunks to cap peak memory for massive directories.
-	sem <- struct{}{}
+	w.sem <- struct{}{}
 	// #nosec G304,G703 -- currentPath is a directory being scanned, sanitized by filepath.Abs in Scan()
 	f, err := os.Open(currentPath)
 	if err != nil {
-		<-sem
+		<-w.sem
 		node.Err = err
 		return
 	}
-
-	sep := string(os.PathSeparator)
-	dirPrefix := currentPath
-	if !strings.HasSuffix(dirPrefix, sep) {
-		dirPrefix += sep
+	if info, err := f.Stat(); err == nil {
+		node.ModTime = info.ModTime().UnixNano()
+		node.raiseNewest(node.ModTime)
 	}
 
+	dirPrefix := dirPrefixOf(currentPath)
+	prevDirs := subdirsOf(prev)
+
 	for ctx.Err() == nil {
 		// Read a batch of entries.
 		// Using f.ReadDir instead of os.ReadDir bypasses the stdlib's sorting.
@@ -117,26 +333,27 @@ func scanDir(
 			break
 		}
 
-		processBatch(ctx, node, entries, dirPrefix, sem, &localChildrenWg, progressCh, globalWg)
+		entries = w.filter(entries, dirPrefix)
+		w.processBatch(ctx, node, entries, dirPrefix, depth, prevDirs, &localChildrenWg)
 	}
 
 	if cerr := f.Close(); cerr != nil && node.Err == nil {
 		node.Err = cerr
 	}
-	<-sem
+	<-w.sem
 }
 
 // processBatch handles logical processing for a chunk of directory entries,
-// reducing the cyclomatic complexity of scanDir.
-func processBatch(
+// reducing the cyclomatic complexity of scanDir. prevDirs holds the
+// subdirectories of the previous scan of node, by name, during a Refresh.
+func (w *walker) processBatch(
 	ctx context.Context,
 	node *Node,
 	entries []fs.DirEntry,
 	dirPrefix string,
-	sem chan struct{},
+	depth int,
+	prevDirs map[string]*Node,
 	localChildrenWg *sync.WaitGroup,
-	progressCh chan<- int64,
-	globalWg *sync.WaitGroup,
 ) {
 	// Pre-grow children slice to minimize reallocs.
 	needed := len(node.Children) + len(entries)
@@ -158,6 +375,7 @@ func processBatch(
 			Name:   entry.Name(),
 			Parent: node,
 			IsDir:  entry.IsDir(),
+			Type:   entry.Type(),
 		}
 		if entry.Type()&fs.ModeSymlink != 0 {
 			child.IsDir = false
@@ -166,7 +384,7 @@ func processBatch(
 	}
 
 	var wg sync.WaitGroup
-	var totalSize atomic.Int64
+	var totalSize, totalUsage, newest atomic.Int64
 
 	numChunks := 8
 	if len(entries) < 32 {
@@ -188,42 +406,467 @@ func processBatch(
 		wg.Add(1)
 		go func(s, e int) {
 			defer wg.Done()
-			var localSize int64
+			var localSize, localUsage, localNewest int64
 
 			for j := s; j < e; j++ {
 				entry := entries[j]
 				child := node.Children[startChildIdx+j]
 
 				if entry.Type()&fs.ModeSymlink != 0 {
+					if w.opts.FollowSymlinks {
+						sz, du := w.followLink(ctx, child, dirPrefix+entry.Name(), depth, localChildrenWg)
+						localSize += sz
+						localUsage += du
+					}
 					continue
 				}
 
 				if entry.IsDir() {
+					if w.crossesDevice(entry) {
+						child.IsMount = true
+						continue
+					}
+					if w.shallow || !w.claimEntry(entry) {
+						continue
+					}
 					localChildrenWg.Add(1)
-					globalWg.Add(1)
+					w.wg.Add(1)
 					childPath := dirPrefix + entry.Name()
-					go scanDir(ctx, child, childPath, localChildrenWg, sem, progressCh, globalWg)
+					go w.scanDir(ctx, child, childPath, depth+1, prevDirs[child.Name], localChildrenWg)
 				} else {
 					info, err := entry.Info()
-					if err == nil {
-						sz := info.Size()
-						child.SetSize(sz)
-						localSize += sz
+					if err != nil {
+						continue
+					}
+					localNewest = max(localNewest, info.ModTime().UnixNano())
+					// Only the first link to reach an inode is charged for
+					// its bytes; later links stay in the tree at zero size.
+					if !w.firstLink(child, info) {
+						child.IsHardlink = true
+						setFileTimes(child, info)
+						continue
 					}
+					setFileInfo(child, info)
+					localSize += child.Size()
+					localUsage += child.Usage()
 				}
 			}
 			if localSize > 0 {
 				totalSize.Add(localSize)
 			}
+			if localUsage > 0 {
+				totalUsage.Add(localUsage)
+			}
+			raiseMax(&newest, localNewest)
 		}(start, end)
 	}
 	wg.Wait()
+	node.raiseNewest(newest.Load())
+	node.AddCounts(countKinds(node.Children[startChildIdx:]))
+
+	if batchFilesUsage := totalUsage.Load(); batchFilesUsage > 0 {
+		node.AddUsage(batchFilesUsage)
+	}
 
 	batchFilesSize := totalSize.Load()
 	if batchFilesSize > 0 {
 		node.AddSize(batchFilesSize)
-		sendProgress(ctx, progressCh, batchFilesSize)
+		sendProgress(ctx, w.progressCh, batchFilesSize)
+	}
+}
+
+// countKinds counts the files and directories among entries, after symlinks
+// have been resolved. Their contents are added by their own scanDir.
+func countKinds(entries []*Node) (files, dirs int64) {
+	for _, c := range entries {
+		if c.IsDir {
+			dirs++
+		} else {
+			files++
+		}
+	}
+	return files, dirs
+}
+
+// followLink resolves the symlink child at linkPath. A linked directory that
+// has not been visited yet is scanned like a regular subdirectory; a linked
+// file returns its size and usage for the caller to add to the batch totals.
+func (w *walker) followLink(
+	ctx context.Context,
+	child *Node,
+	linkPath string,
+	depth int,
+	localChildrenWg *sync.WaitGroup,
+) (size, usage int64) {
+	if target, err := os.Readlink(linkPath); err == nil {
+		child.LinkTarget = target
+	}
+	info, err := os.Stat(linkPath)
+	if err != nil {
+		child.Err = err // dangling link or unreadable target
+		child.Parent.AddErrors(1)
+		return 0, 0
+	}
+
+	if info.IsDir() {
+		if w.onOtherDevice(info) {
+			child.IsDir = true
+			child.IsMount = true
+			return 0, 0
+		}
+		if !w.claimDir(info) {
+			return 0, 0 // cycle, or a tree already counted elsewhere
+		}
+		child.IsDir = true
+		if w.shallow {
+			return 0, 0
+		}
+		localChildrenWg.Add(1)
+		w.wg.Add(1)
+		go w.scanDir(ctx, child, linkPath, depth+1, nil, localChildrenWg)
+		return 0, 0
+	}
+
+	child.Parent.raiseNewest(info.ModTime().UnixNano())
+	if !w.firstLink(child, info) {
+		child.IsHardlink = true
+		setFileTimes(child, info)
+		return 0, 0
+	}
+	setFileInfo(child, info)
+	return child.Size(), child.Usage()
+}
+
+// totals accumulates a subtree collapsed by MaxDepth.
+type totals struct {
+	size, usage, files, dirs, errs, newest int64
+}
+
+// collapse fills node, a directory at MaxDepth, with the totals of its
+// subtree without creating nodes for the entries in it.
+func (w *walker) collapse(ctx context.Context, node *Node, path string) {
+	var t totals
+	node.ModTime, node.Err = w.sumDir(ctx, path, &t)
+	node.AddSize(t.size)
+	node.AddUsage(t.usage)
+	node.AddCounts(t.files, t.dirs)
+	node.AddErrors(t.errs)
+	node.raiseNewest(max(node.ModTime, t.newest))
+}
+
+// sumDir adds the entries of the directory at path, and everything below
+// it, to t, returning the directory's mtime and the error reading it, if
+// any. Only the paths of subdirectories are held while a level is read, and
+// they are descended into after it is closed, so the semaphore is never held
+// across levels.
+func (w *walker) sumDir(ctx context.Context, path string, t *totals) (modTime int64, err error) {
+	w.sem <- struct{}{}
+	// #nosec G304,G703 -- path is a directory being scanned, below the root cleaned in Scan()
+	f, err := os.Open(path)
+	if err != nil {
+		<-w.sem
+		return 0, err
+	}
+	if info, serr := f.Stat(); serr == nil {
+		modTime = info.ModTime().UnixNano()
+		t.newest = max(t.newest, modTime)
+	}
+
+	dirPrefix := dirPrefixOf(path)
+	before := t.size
+	var subdirs []string
+	for ctx.Err() == nil {
+		entries, rerr := f.ReadDir(readDirBatchSize)
+		if rerr != nil {
+			if rerr != io.EOF {
+				err = rerr
+			}
+			break
+		}
+		for _, entry := range w.filter(entries, dirPrefix) {
+			if sub := w.sumEntry(entry, dirPrefix, t); sub != "" {
+				subdirs = append(subdirs, sub)
+			}
+		}
+	}
+	if cerr := f.Close(); cerr != nil && err == nil {
+		err = cerr
+	}
+	<-w.sem
+	sendProgress(ctx, w.progressCh, t.size-before)
+
+	for _, sub := range subdirs {
+		if _, err := w.sumDir(ctx, sub, t); err != nil {
+			t.errs++ // no node below to record it on, only the count
+		}
+	}
+	return modTime, err
+}
+
+// sumEntry adds a single entry to t as processBatch would count it, and
+// returns the path of the directory to descend into, if any.
+func (w *walker) sumEntry(entry fs.DirEntry, dirPrefix string, t *totals) string {
+	path := dirPrefix + entry.Name()
+	var info fs.FileInfo
+	var err error
+	switch {
+	case entry.Type()&fs.ModeSymlink != 0:
+		if !w.opts.FollowSymlinks {
+			t.files++
+			return ""
+		}
+		if info, err = os.Stat(path); err != nil {
+			t.files++ // dangling link or unreadable target
+			t.errs++
+			return ""
+		}
+	case entry.IsDir():
+		t.dirs++
+		if w.crossesDevice(entry) {
+			return ""
+		}
+		if !w.claimEntry(entry) {
+			return ""
+		}
+		return path
+	default:
+		if info, err = entry.Info(); err != nil {
+			t.files++
+			return ""
+		}
+	}
+
+	if info.IsDir() { // a followed link
+		t.dirs++
+		if w.onOtherDevice(info) || !w.claimDir(info) {
+			return ""
+		}
+		return path
+	}
+	t.files++
+	t.newest = max(t.newest, info.ModTime().UnixNano())
+	if id, ok := hardlinkID(info); ok && !w.links.claim(id) {
+		return ""
+	}
+	t.size += info.Size()
+	t.usage += allocatedSize(info)
+	return ""
+}
+
+// firstLink reports whether child is the first link to reach its inode, the
+// one charged for its bytes. A file with several links records its inode
+// either way.
+func (w *walker) firstLink(child *Node, info fs.FileInfo) bool {
+	id, ok := hardlinkID(info)
+	if !ok {
+		return true
+	}
+	child.Inode = &Inode{Dev: id.dev, Ino: id.ino, Size: info.Size(), Usage: allocatedSize(info)}
+	return w.links.claim(id)
+}
+
+// reuseLink claims a reused hardlinked file's inode as firstLink would, so
+// the links kept from the previous scan and those scanned afresh still
+// charge the inode's bytes exactly once.
+func (w *walker) reuseLink(child *Node) {
+	child.IsHardlink = !w.links.claim(fileID{dev: child.Inode.Dev, ino: child.Inode.Ino})
+	if child.IsHardlink {
+		child.SetSize(0)
+		child.SetUsage(0)
+	} else {
+		child.SetSize(child.Inode.Size)
+		child.SetUsage(child.Inode.Usage)
+	}
+}
+
+// setFileInfo records a file's sizes and times from info.
+func setFileInfo(n *Node, info fs.FileInfo) {
+	n.SetSize(info.Size())
+	n.SetUsage(allocatedSize(info))
+	setFileTimes(n, info)
+}
+
+// setFileTimes records a file's times from info.
+func setFileTimes(n *Node, info fs.FileInfo) {
+	n.ModTime = info.ModTime().UnixNano()
+	n.AccessTime = accessTime(info)
+	n.SetNewest(n.ModTime)
+}
+
+// claimEntry claims the subdirectory entry before it is scanned when
+// following symlinks, as followLink does for linked directories, so a tree
+// reached both directly and through a link is counted once, by whichever
+// gets there first. Unlike a link, a directory without an inode identity is
+// still entered, as it is when links are not followed.
+func (w *walker) claimEntry(entry fs.DirEntry) bool {
+	if !w.opts.FollowSymlinks {
+		return true
+	}
+	info, err := entry.Info()
+	if err != nil {
+		return true // gone; scanDir records the error
+	}
+	id, ok := inodeID(info)
+	return !ok || w.dirs.claim(id)
+}
+
+// claimDir records the directory described by info as visited and reports
+// whether this was the first visit. Without an inode identity a cycle cannot
+// be ruled out, so it reports false and linked directories are not followed.
+func (w *walker) claimDir(info fs.FileInfo) bool {
+	id, ok := inodeID(info)
+	if !ok {
+		return false
+	}
+	return w.dirs.claim(id)
+}
+
+// filter drops the entries excluded by the walker's options, reusing the
+// backing array of entries.
+func (w *walker) filter(entries []fs.DirEntry, dirPrefix string) []fs.DirEntry {
+	if len(w.opts.Exclude) == 0 && !w.opts.SkipHidden && !w.opts.SkipSymlinks {
+		return entries
+	}
+	kept := entries[:0]
+	for _, entry := range entries {
+		if !w.skip(entry, dirPrefix) {
+			kept = append(kept, entry)
+		}
+	}
+	return kept
+}
+
+// skip reports whether a single entry is excluded by the walker's options.
+func (w *walker) skip(entry fs.DirEntry, dirPrefix string) bool {
+	name := entry.Name()
+	if w.opts.SkipHidden && strings.HasPrefix(name, ".") {
+		return true
+	}
+	if w.opts.SkipSymlinks && entry.Type()&fs.ModeSymlink != 0 {
+		return true
+	}
+	for _, pattern := range w.opts.Exclude {
+		target := name
+		if strings.ContainsRune(pattern, os.PathSeparator) {
+			target = strings.TrimPrefix(dirPrefix, w.rootPrefix) + name
+		}
+		// Patterns were validated up front, so err is always nil here.
+		if ok, err := filepath.Match(pattern, target); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// dirPrefixOf returns path with exactly one trailing separator.
+func dirPrefixOf(path string) string {
+	sep := string(os.PathSeparator)
+	if strings.HasSuffix(path, sep) {
+		return path
+	}
+	return path + sep
+}
+
+// reuse fills node from prev, the same directory in an earlier scan, when
+// the directory's mtime shows that its entries are unchanged. Files are
+// copied from prev and subdirectories are scanned again against their own
+// previous versions. It reports false when node must be read instead.
+func (w *walker) reuse(ctx context.Context, node, prev *Node, path string, depth int, wg *sync.WaitGroup) bool {
+	if prev.ModTime == 0 || prev.Err != nil || w.opts.FollowSymlinks ||
+		(w.opts.MaxDepth > 0 && depth >= w.opts.MaxDepth) {
+		return false // nothing recorded, or the children don't hold the detail
+	}
+	info, err := os.Lstat(path)
+	if err != nil || !info.IsDir() {
+		return false
+	}
+	if dev, ok := deviceID(info); ok && w.opts.OneFileSystem && dev != w.rootDev {
+		node.IsMount = true // mounted over since the last scan
+		return true
+	}
+	if info.ModTime().UnixNano() != prev.ModTime {
+		return false
+	}
+
+	node.ModTime = prev.ModTime
+	node.raiseNewest(prev.ModTime)
+	node.Children = make([]*Node, 0, len(prev.Children))
+	dirPrefix := dirPrefixOf(path)
+	var size, usage, errs int64
+	for _, pc := range prev.Children {
+		child := &Node{
+			Parent:     node,
+			Name:       pc.Name,
+			Err:        pc.Err,
+			LinkTarget: pc.LinkTarget,
+			IsDir:      pc.IsDir,
+			Type:       pc.Type,
+			IsHardlink: pc.IsHardlink,
+			Inode:      pc.Inode,
+			IsMount:    pc.IsMount,
+		}
+		node.Children = append(node.Children, child)
+		if pc.IsDir && !pc.IsMount {
+			wg.Add(1)
+			w.wg.Add(1)
+			go w.scanDir(ctx, child, dirPrefix+pc.Name, depth+1, pc, wg)
+			continue
+		}
+		child.SetSize(pc.Size())
+		child.SetUsage(pc.Usage())
+		if pc.Inode != nil {
+			w.reuseLink(child)
+		}
+		child.ModTime = pc.ModTime
+		child.AccessTime = pc.AccessTime
+		child.SetNewest(pc.Newest())
+		node.raiseNewest(pc.Newest())
+		size += child.Size()
+		usage += child.Usage()
+		errs += errCount(pc.Err)
+	}
+	node.AddSize(size)
+	node.AddUsage(usage)
+	node.AddCounts(countKinds(node.Children))
+	node.AddErrors(errs)
+	sendProgress(ctx, w.progressCh, size)
+	return true
+}
+
+// subdirsOf indexes prev's subdirectories by name; nil when prev is nil.
+func subdirsOf(prev *Node) map[string]*Node {
+	if prev == nil {
+		return nil
+	}
+	dirs := make(map[string]*Node)
+	for _, c := range prev.Children {
+		if c.IsDir && !c.IsMount {
+			dirs[c.Name] = c
+		}
+	}
+	return dirs
+}
+
+// onOtherDevice reports whether OneFileSystem is set and info describes an
+// entry on a different device than the scan root.
+func (w *walker) onOtherDevice(info fs.FileInfo) bool {
+	dev, ok := deviceID(info)
+	return ok && w.opts.OneFileSystem && dev != w.rootDev
+}
+
+// crossesDevice reports whether OneFileSystem is set and the directory entry
+// lives on a different device than the scan root. The extra lstat is only
+// paid for directories, and only when the option is enabled.
+func (w *walker) crossesDevice(entry fs.DirEntry) bool {
+	if !w.opts.OneFileSystem {
+		return false
+	}
+	info, err := entry.Info()
+	if err != nil {
+		return false
 	}
+	dev, ok := deviceID(info)
+	return ok && dev != w.rootDev
 }
 
 func sendProgress(ctx context.Context, ch chan<- int64, sz int64) {
@@ -2,9 +2,13 @@ package scanner_test
 
 import (
 	"context"
+	"errors"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"runtime"
 	"testing"
+	"time"
 
 	"github.com/mobanhawi/aster/internal/scanner"
 )
@@ -42,6 +46,18 @@ func makeTestDir(t *testing.T, layout map[string][]byte) string {
 
 func bytes(n int) []byte { return make([]byte, n) }
 
+// childNamed returns n's child called name, failing the test if it is missing.
+func childNamed(t *testing.T, n *scanner.Node, name string) *scanner.Node {
+	t.Helper()
+	for _, c := range n.Children {
+		if c.Name == name {
+			return c
+		}
+	}
+	t.Fatalf("%s has no child %q", n.Name, name)
+	return nil
+}
+
 // ── Node tests ────────────────────────────────────────────────────────────────
 
 func TestNodeSize(t *testing.T) {
@@ -121,6 +137,92 @@ func TestNodeSortByName(t *testing.T) {
 	})
 }
 
+func TestNodeSortByAge(t *testing.T) {
+	t.Run("GivenMixedTimes_WhenSortByAge_ThenOldestFirstAndUnknownLast", func(t *testing.T) {
+		parent := &scanner.Node{Name: "parent", IsDir: true}
+		for name, newest := range map[string]int64{"recent": 300, "unknown": 0, "ancient": 100, "middle": 200} {
+			c := &scanner.Node{Name: name}
+			c.SetNewest(newest)
+			parent.Children = append(parent.Children, c)
+		}
+		parent.SortByAge()
+
+		want := []string{"ancient", "middle", "recent", "unknown"}
+		for i, child := range parent.Children {
+			if child.Name != want[i] {
+				t.Errorf("children[%d].Name = %q, want %q", i, child.Name, want[i])
+			}
+		}
+	})
+}
+
+func TestNodeSortByCount(t *testing.T) {
+	t.Run("GivenDirsAndFiles_WhenSortByCount_ThenMostEntriesFirstAndFilesLast", func(t *testing.T) {
+		parent := &scanner.Node{Name: "parent", IsDir: true}
+		for _, c := range []struct {
+			name        string
+			files, dirs int64
+		}{{"file", 0, 0}, {"few", 2, 1}, {"many", 2000, 30}, {"empty", 0, 0}} {
+			n := &scanner.Node{Name: c.name, IsDir: c.name != "file"}
+			n.SetCounts(c.files, c.dirs)
+			parent.Children = append(parent.Children, n)
+		}
+		parent.SortByCount()
+
+		want := []string{"many", "few", "file", "empty"}
+		for i, child := range parent.Children {
+			if child.Name != want[i] {
+				t.Errorf("children[%d].Name = %q, want %q", i, child.Name, want[i])
+			}
+		}
+	})
+}
+
+func TestNodeRemoveAndAddChild(t *testing.T) {
+	t.Run("GivenNestedChild_WhenRemovedAndReadded_ThenAncestorTotalsFollow", func(t *testing.T) {
+		root := &scanner.Node{Name: "/", IsDir: true}
+		dir := &scanner.Node{Name: "dir", IsDir: true, Parent: root}
+		leaf := &scanner.Node{Name: "leaf", Parent: dir}
+		other := &scanner.Node{Name: "other", Parent: dir}
+		leaf.SetSize(fileSizeLarge)
+		leaf.SetUsage(fileSizeLarge * 2)
+		other.SetSize(fileSizeSmall)
+		dir.Children = []*scanner.Node{leaf, other}
+		root.Children = []*scanner.Node{dir}
+		for _, n := range []*scanner.Node{dir, root} {
+			n.SetSize(fileSizeLarge + fileSizeSmall)
+			n.SetUsage(fileSizeLarge * 2)
+		}
+		dir.SetCounts(2, 0)
+		root.SetCounts(2, 1)
+
+		if !dir.RemoveChild(leaf) {
+			t.Fatal("RemoveChild(leaf) = false, want true")
+		}
+		if root.Size() != fileSizeSmall || dir.Usage() != 0 || len(dir.Children) != 1 {
+			t.Errorf("after remove: root %d, dir usage %d, %d children", root.Size(), dir.Usage(), len(dir.Children))
+		}
+		if root.Files() != 1 || root.Dirs() != 1 || dir.Files() != 1 {
+			t.Errorf("after remove: root %d files %d dirs, dir %d files", root.Files(), root.Dirs(), dir.Files())
+		}
+		if dir.RemoveChild(leaf) {
+			t.Error("RemoveChild of a detached node = true, want false")
+		}
+
+		dir.MarkSorted(7, 0)
+		dir.AddChild(leaf)
+		if root.Size() != fileSizeLarge+fileSizeSmall || root.Usage() != fileSizeLarge*2 || leaf.Parent != dir {
+			t.Errorf("after add: root %d/%d, parent %v", root.Size(), root.Usage(), leaf.Parent)
+		}
+		if root.Files() != 2 || dir.Files() != 2 {
+			t.Errorf("after add: root %d files, dir %d files", root.Files(), dir.Files())
+		}
+		if dir.IsSorted(7, 0) {
+			t.Error("AddChild should mark the parent unsorted")
+		}
+	})
+}
+
 // ── Scanner tests ─────────────────────────────────────────────────────────────
 
 func TestScan(t *testing.T) {
@@ -312,6 +414,12 @@ func TestScanSymlink(t *testing.T) {
 			if child.IsDir {
 				t.Error("symlink should have IsDir=false to avoid deep copies")
 			}
+			if child.LinkTarget != "" {
+				t.Errorf("LinkTarget = %q, want it left unread when not following links", child.LinkTarget)
+			}
+			if child.Type != fs.ModeSymlink {
+				t.Errorf("Type = %v, want a symlink", child.Type)
+			}
 		}
 	}
 	if !foundSymlink {
@@ -347,3 +455,753 @@ func TestGetPurgeableSpace(t *testing.T) {
 		t.Errorf("GetPurgeableSpace() returned negative value: %d", space)
 	}
 }
+
+func TestScanAllocatedUsage(t *testing.T) {
+	t.Run("GivenSparseFile_WhenScanned_ThenUsageBelowApparentSize", func(t *testing.T) {
+		root := t.TempDir()
+		f, err := os.Create(filepath.Join(root, "sparse.img"))
+		if err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		const sparseSize = 64 << 20
+		if err := f.Truncate(sparseSize); err != nil {
+			t.Fatalf("truncate: %v", err)
+		}
+		_ = f.Close()
+
+		node, err := scanner.Scan(context.Background(), root, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if node.Size() != sparseSize {
+			t.Errorf("Size() = %d, want %d", node.Size(), sparseSize)
+		}
+		if node.Usage() >= node.Size() {
+			t.Errorf("Usage() = %d, want < %d for a sparse file", node.Usage(), node.Size())
+		}
+	})
+
+	t.Run("GivenNestedFiles_WhenScanned_ThenUsagePropagatesToRoot", func(t *testing.T) {
+		root := makeTestDir(t, map[string][]byte{
+			"sub/a.bin": bytes(fileSizeLarge),
+			"b.bin":     bytes(fileSizeSmall),
+		})
+
+		node, err := scanner.Scan(context.Background(), root, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var childUsage int64
+		for _, c := range node.Children {
+			childUsage += c.Usage()
+		}
+		if node.Usage() != childUsage {
+			t.Errorf("root Usage() = %d, want sum of children %d", node.Usage(), childUsage)
+		}
+	})
+}
+
+func TestScanHardlinks(t *testing.T) {
+	t.Run("GivenHardlinkedFiles_WhenScanned_ThenInodeCountedOnce", func(t *testing.T) {
+		root := makeTestDir(t, map[string][]byte{
+			"a/original.bin": bytes(fileSizeLarge),
+			"b/":             nil,
+		})
+		for _, name := range []string{"b/link1.bin", "b/link2.bin"} {
+			if err := os.Link(filepath.Join(root, "a/original.bin"), filepath.Join(root, name)); err != nil {
+				t.Skipf("hardlinks unsupported: %v", err)
+			}
+		}
+
+		node, err := scanner.Scan(context.Background(), root, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if node.Size() != fileSizeLarge {
+			t.Errorf("Size() = %d, want %d", node.Size(), fileSizeLarge)
+		}
+
+		var counted, shared int
+		for _, dir := range node.Children {
+			for _, f := range dir.Children {
+				if f.IsHardlink {
+					shared++
+					if f.Size() != 0 {
+						t.Errorf("%s: shared link Size() = %d, want 0", f.Name, f.Size())
+					}
+				} else {
+					counted++
+				}
+			}
+		}
+		if counted != 1 || shared != 2 {
+			t.Errorf("counted=%d shared=%d, want 1 and 2", counted, shared)
+		}
+		var inodes []scanner.Inode
+		for _, dir := range node.Children {
+			for _, f := range dir.Children {
+				if f.Inode == nil {
+					t.Fatalf("%s: no inode recorded", f.Name)
+				}
+				inodes = append(inodes, *f.Inode)
+			}
+		}
+		for _, ino := range inodes {
+			if ino != inodes[0] || ino.Size != fileSizeLarge {
+				t.Errorf("inodes = %+v, want one inode of %d bytes on every link", inodes, fileSizeLarge)
+				break
+			}
+		}
+	})
+}
+
+func TestScanOneFileSystem(t *testing.T) {
+	t.Run("GivenSingleDevice_WhenScannedWithOneFileSystem_ThenNothingMarkedAsMount", func(t *testing.T) {
+		root := makeTestDir(t, map[string][]byte{
+			"sub/deeper/file.bin": bytes(fileSizeMedium),
+		})
+
+		node, err := scanner.ScanWithOptions(context.Background(), root, nil, scanner.Options{OneFileSystem: true})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if node.Size() != fileSizeMedium {
+			t.Errorf("Size() = %d, want %d", node.Size(), fileSizeMedium)
+		}
+		for _, c := range node.Children {
+			if c.IsMount {
+				t.Errorf("%s unexpectedly marked as mount point", c.Name)
+			}
+		}
+	})
+}
+
+func TestScanWithOptions(t *testing.T) {
+	layout := map[string][]byte{
+		"keep.txt":           bytes(fileSizeSmall),
+		"drop.log":           bytes(fileSizeMedium),
+		".hidden":            bytes(fileSizeMedium),
+		"src/main.go":        bytes(fileSizeSmall),
+		"src/.git/objects/a": bytes(fileSizeLarge),
+		"deep/a/b/c.bin":     bytes(fileSizeLarge),
+	}
+
+	testCases := []struct {
+		name     string
+		opts     scanner.Options
+		wantSize int64
+		absent   []string // top-level names that must not appear
+	}{
+		{
+			name:     "GivenNameExclude_WhenScanned_ThenMatchingEntriesOmitted",
+			opts:     scanner.Options{Exclude: []string{"*.log", ".git"}},
+			wantSize: fileSizeSmall*2 + fileSizeMedium + fileSizeLarge,
+			absent:   []string{"drop.log"},
+		},
+		{
+			name:     "GivenPathExclude_WhenScanned_ThenOnlyThatPathOmitted",
+			opts:     scanner.Options{Exclude: []string{"deep/a"}},
+			wantSize: fileSizeSmall*2 + fileSizeMedium*2 + fileSizeLarge,
+		},
+		{
+			name:     "GivenMaxDepthAndExclude_WhenScanned_ThenCollapsedTotalsHonourExclude",
+			opts:     scanner.Options{MaxDepth: 1, Exclude: []string{".git", "deep/a/b"}},
+			wantSize: fileSizeSmall*2 + fileSizeMedium*2,
+		},
+		{
+			name:     "GivenSkipHidden_WhenScanned_ThenDotfilesOmitted",
+			opts:     scanner.Options{SkipHidden: true},
+			wantSize: fileSizeSmall*2 + fileSizeMedium + fileSizeLarge,
+			absent:   []string{".hidden"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			root := makeTestDir(t, layout)
+			node, err := scanner.ScanWithOptions(context.Background(), root, nil, tc.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if node.Size() != tc.wantSize {
+				t.Errorf("Size() = %d, want %d", node.Size(), tc.wantSize)
+			}
+			for _, c := range node.Children {
+				for _, name := range tc.absent {
+					if c.Name == name {
+						t.Errorf("%s should have been skipped", name)
+					}
+				}
+			}
+		})
+	}
+
+	t.Run("GivenMaxDepth_WhenScanned_ThenDeeperDirsCollapsedWithTotals", func(t *testing.T) {
+		root := makeTestDir(t, layout)
+		node, err := scanner.ScanWithOptions(context.Background(), root, nil, scanner.Options{MaxDepth: 1})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, c := range node.Children {
+			if c.Name != "deep" {
+				continue
+			}
+			if len(c.Children) != 0 {
+				t.Errorf("deep has %d children, want collapsed", len(c.Children))
+			}
+			if c.Size() != fileSizeLarge {
+				t.Errorf("deep Size() = %d, want %d", c.Size(), fileSizeLarge)
+			}
+		}
+	})
+
+	t.Run("GivenSkipSymlinks_WhenScanned_ThenLinksOmitted", func(t *testing.T) {
+		root := makeTestDir(t, map[string][]byte{"real.bin": bytes(fileSizeSmall)})
+		if err := os.Symlink(filepath.Join(root, "real.bin"), filepath.Join(root, "link")); err != nil {
+			t.Fatalf("symlink: %v", err)
+		}
+		node, err := scanner.ScanWithOptions(context.Background(), root, nil, scanner.Options{SkipSymlinks: true})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(node.Children) != 1 {
+			t.Errorf("children = %d, want 1 (symlink skipped)", len(node.Children))
+		}
+	})
+
+	t.Run("GivenMalformedPattern_WhenScanned_ThenReturnsError", func(t *testing.T) {
+		root := makeTestDir(t, layout)
+		if _, err := scanner.ScanWithOptions(context.Background(), root, nil, scanner.Options{Exclude: []string{"["}}); err == nil {
+			t.Error("expected error for malformed exclude pattern")
+		}
+	})
+}
+
+func TestScanFollowSymlinks(t *testing.T) {
+	t.Run("GivenLinkedDir_WhenFollowing_ThenTargetContentsCounted", func(t *testing.T) {
+		data := makeTestDir(t, map[string][]byte{"big.bin": bytes(fileSizeLarge)})
+		root := makeTestDir(t, map[string][]byte{"local.txt": bytes(fileSizeSmall)})
+		if err := os.Symlink(data, filepath.Join(root, "data")); err != nil {
+			t.Fatalf("symlink: %v", err)
+		}
+
+		node, err := scanner.ScanWithOptions(context.Background(), root, nil, scanner.Options{FollowSymlinks: true})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if node.Size() != fileSizeSmall+fileSizeLarge {
+			t.Errorf("Size() = %d, want %d", node.Size(), fileSizeSmall+fileSizeLarge)
+		}
+		for _, c := range node.Children {
+			if c.Name == "data" {
+				if !c.IsDir || c.LinkTarget != data {
+					t.Errorf("data: IsDir=%v LinkTarget=%q, want dir linking to %q", c.IsDir, c.LinkTarget, data)
+				}
+			}
+		}
+	})
+
+	t.Run("GivenLinkCycle_WhenFollowing_ThenScanTerminates", func(t *testing.T) {
+		root := makeTestDir(t, map[string][]byte{"a/file.bin": bytes(fileSizeMedium)})
+		if err := os.Symlink("..", filepath.Join(root, "a", "up")); err != nil {
+			t.Fatalf("symlink: %v", err)
+		}
+		if err := os.Symlink(".", filepath.Join(root, "self")); err != nil {
+			t.Fatalf("symlink: %v", err)
+		}
+
+		node, err := scanner.ScanWithOptions(context.Background(), root, nil, scanner.Options{FollowSymlinks: true})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if node.Size() != fileSizeMedium {
+			t.Errorf("Size() = %d, want %d (cycle must not be counted)", node.Size(), fileSizeMedium)
+		}
+	})
+
+	t.Run("GivenLinkBesideItsTarget_WhenFollowing_ThenTargetCountedOnce", func(t *testing.T) {
+		root := makeTestDir(t, map[string][]byte{"data/nested/big.bin": bytes(fileSizeLarge)})
+		for _, name := range []string{"a-link", "z-link"} {
+			if err := os.Symlink(filepath.Join(root, "data", "nested"), filepath.Join(root, name)); err != nil {
+				t.Fatalf("symlink: %v", err)
+			}
+		}
+
+		// Which of the directory and its links is scanned first depends on
+		// scheduling, so scan repeatedly.
+		for range 50 {
+			node, err := scanner.ScanWithOptions(context.Background(), root, nil, scanner.Options{FollowSymlinks: true})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if node.Size() != fileSizeLarge {
+				t.Fatalf("Size() = %d, want %d", node.Size(), fileSizeLarge)
+			}
+		}
+	})
+
+	t.Run("GivenDanglingLink_WhenFollowing_ThenErrorRecordedOnNode", func(t *testing.T) {
+		root := t.TempDir()
+		if err := os.Symlink(filepath.Join(root, "missing"), filepath.Join(root, "broken")); err != nil {
+			t.Fatalf("symlink: %v", err)
+		}
+
+		node, err := scanner.ScanWithOptions(context.Background(), root, nil, scanner.Options{FollowSymlinks: true})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(node.Children) != 1 || node.Children[0].Err == nil {
+			t.Error("expected dangling link to carry an error")
+		}
+	})
+
+	t.Run("GivenFollowAndSkip_WhenScanned_ThenReturnsError", func(t *testing.T) {
+		_, err := scanner.ScanWithOptions(context.Background(), t.TempDir(), nil,
+			scanner.Options{FollowSymlinks: true, SkipSymlinks: true})
+		if err == nil {
+			t.Error("expected error for contradictory symlink options")
+		}
+	})
+}
+
+func TestRescan(t *testing.T) {
+	t.Run("GivenChangedSubtree_WhenRescannedAndReplaced_ThenAncestorsAdjustedByDelta", func(t *testing.T) {
+		root := makeTestDir(t, map[string][]byte{
+			"keep.bin":       bytes(fileSizeSmall),
+			"sub/old.bin":    bytes(fileSizeLarge),
+			"sub/nested/a.b": bytes(fileSizeMedium),
+		})
+		tree, err := scanner.Scan(context.Background(), root, nil)
+		if err != nil {
+			t.Fatalf("Scan: %v", err)
+		}
+		sub := childNamed(t, tree, "sub")
+		nested := childNamed(t, sub, "nested")
+
+		if err := os.Remove(filepath.Join(root, "sub", "old.bin")); err != nil {
+			t.Fatalf("remove: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(root, "sub", "nested", "new.bin"), bytes(fileSizeSmall), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+
+		fresh, err := scanner.Rescan(context.Background(), scanner.Locate(sub), scanner.Options{})
+		if err != nil {
+			t.Fatalf("Rescan: %v", err)
+		}
+		if fresh.Name != "sub" || fresh.Size() != fileSizeMedium+fileSizeSmall {
+			t.Errorf("fresh = %q %d, want sub %d", fresh.Name, fresh.Size(), fileSizeMedium+fileSizeSmall)
+		}
+		if sub.Size() != fileSizeLarge+fileSizeMedium {
+			t.Error("Rescan must not modify the live tree")
+		}
+
+		sub.ReplaceWith(fresh)
+		if want := int64(fileSizeSmall + fileSizeMedium + fileSizeSmall); tree.Size() != want {
+			t.Errorf("root Size() = %d, want %d", tree.Size(), want)
+		}
+		if childNamed(t, sub, "nested") != nested || nested.Size() != fileSizeMedium+fileSizeSmall {
+			t.Error("surviving directories should keep their identity and take the new size")
+		}
+		if n := childNamed(t, nested, "new.bin"); n.Parent != nested {
+			t.Error("new entries should be parented into the live tree")
+		}
+	})
+
+	t.Run("GivenMaxDepth_WhenRescanned_ThenDepthCountedFromTreeRoot", func(t *testing.T) {
+		root := makeTestDir(t, map[string][]byte{"a/b/c.bin": bytes(fileSizeLarge)})
+		opts := scanner.Options{MaxDepth: 2}
+		tree, err := scanner.ScanWithOptions(context.Background(), root, nil, opts)
+		if err != nil {
+			t.Fatalf("Scan: %v", err)
+		}
+		fresh, err := scanner.Rescan(context.Background(), scanner.Locate(childNamed(t, tree, "a")), opts)
+		if err != nil {
+			t.Fatalf("Rescan: %v", err)
+		}
+		b := childNamed(t, fresh, "b")
+		if len(b.Children) != 0 || b.Size() != fileSizeLarge {
+			t.Errorf("b: %d children, size %d; want collapsed at depth 2", len(b.Children), b.Size())
+		}
+	})
+
+	t.Run("GivenNestedDir_WhenLocated_ThenPathAndTreeRootRecorded", func(t *testing.T) {
+		root := makeTestDir(t, map[string][]byte{"a/b/c.bin": bytes(fileSizeSmall)})
+		tree, err := scanner.Scan(context.Background(), root, nil)
+		if err != nil {
+			t.Fatalf("Scan: %v", err)
+		}
+		loc := scanner.Locate(childNamed(t, childNamed(t, tree, "a"), "b"))
+		want := scanner.Location{Path: filepath.Join(tree.Name, "a", "b"), Name: "b", Root: tree.Name, Depth: 2}
+		if loc != want {
+			t.Errorf("Locate = %+v, want %+v", loc, want)
+		}
+	})
+
+	t.Run("GivenDeletedDir_WhenRescanned_ThenReturnsError", func(t *testing.T) {
+		root := makeTestDir(t, map[string][]byte{"gone/x": bytes(fileSizeSmall)})
+		tree, err := scanner.Scan(context.Background(), root, nil)
+		if err != nil {
+			t.Fatalf("Scan: %v", err)
+		}
+		if err := os.RemoveAll(filepath.Join(root, "gone")); err != nil {
+			t.Fatalf("remove: %v", err)
+		}
+		if _, err := scanner.Rescan(context.Background(), scanner.Locate(childNamed(t, tree, "gone")), scanner.Options{}); err == nil {
+			t.Error("expected error rescanning a deleted directory")
+		}
+	})
+}
+
+func TestReadLevelAndSync(t *testing.T) {
+	t.Run("GivenChangedDirectory_WhenSynced_ThenOnlyDirectChildrenUpdated", func(t *testing.T) {
+		root := makeTestDir(t, map[string][]byte{
+			"grow.log":     bytes(fileSizeSmall),
+			"gone.tmp":     bytes(fileSizeMedium),
+			"deep/big.bin": bytes(fileSizeLarge),
+		})
+		tree, err := scanner.Scan(context.Background(), root, nil)
+		if err != nil {
+			t.Fatalf("Scan: %v", err)
+		}
+
+		if err := os.WriteFile(filepath.Join(root, "grow.log"), bytes(fileSizeLarge), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		if err := os.Remove(filepath.Join(root, "gone.tmp")); err != nil {
+			t.Fatalf("remove: %v", err)
+		}
+		if err := os.MkdirAll(filepath.Join(root, "fresh"), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(root, "fresh", "x.bin"), bytes(fileSizeSmall), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+
+		level, err := scanner.ReadLevel(context.Background(), scanner.Locate(tree), scanner.Options{})
+		if err != nil {
+			t.Fatalf("ReadLevel: %v", err)
+		}
+		deep := childNamed(t, tree, "deep")
+
+		added := tree.Sync(level)
+		if len(added) != 1 || added[0].Name != "fresh" || added[0].Parent != tree {
+			t.Fatalf("added = %v, want the new directory", added)
+		}
+		// grow.log grew to large, gone.tmp vanished, deep is untouched and
+		// fresh is still unscanned.
+		if want := int64(fileSizeLarge + fileSizeLarge); tree.Size() != want {
+			t.Errorf("root Size() = %d, want %d", tree.Size(), want)
+		}
+		if len(tree.Children) != 3 || deep.Size() != fileSizeLarge || len(deep.Children) != 1 {
+			t.Errorf("children = %d, deep size %d with %d children", len(tree.Children), deep.Size(), len(deep.Children))
+		}
+		if tree.Files() != 2 || tree.Dirs() != 2 {
+			t.Errorf("root files/dirs = %d/%d, want 2/2", tree.Files(), tree.Dirs())
+		}
+
+		fresh, err := scanner.Rescan(context.Background(), scanner.Locate(added[0]), scanner.Options{})
+		if err != nil {
+			t.Fatalf("Rescan: %v", err)
+		}
+		added[0].ReplaceWith(fresh)
+		if want := int64(fileSizeLarge + fileSizeLarge + fileSizeSmall); tree.Size() != want {
+			t.Errorf("after scanning the new dir, root Size() = %d, want %d", tree.Size(), want)
+		}
+		if tree.Files() != 3 || added[0].Files() != 1 {
+			t.Errorf("after scanning the new dir, files = %d (new dir %d), want 3 (1)", tree.Files(), added[0].Files())
+		}
+	})
+}
+
+func TestRefresh(t *testing.T) {
+	// find follows rel from root through the tree, failing if it is missing.
+	find := func(t *testing.T, root *scanner.Node, rel ...string) *scanner.Node {
+		t.Helper()
+		n := root
+		for _, name := range rel {
+			n = childNamed(t, n, name)
+		}
+		return n
+	}
+	setup := func(t *testing.T) (string, *scanner.Node) {
+		t.Helper()
+		root := makeTestDir(t, map[string][]byte{
+			"static/a.bin":       bytes(fileSizeLarge),
+			"static/deep/b.bin":  bytes(fileSizeMedium),
+			"busy/c.log":         bytes(fileSizeSmall),
+			"top.txt":            bytes(fileSizeSmall),
+			"static/deep/d/e.db": bytes(fileSizeSmall),
+		})
+		prev, err := scanner.Scan(context.Background(), root, nil)
+		if err != nil {
+			t.Fatalf("Scan: %v", err)
+		}
+		return root, prev
+	}
+
+	t.Run("GivenScan_WhenDone_ThenDirectoryModTimesRecorded", func(t *testing.T) {
+		root, prev := setup(t)
+		info, err := os.Stat(filepath.Join(root, "static", "deep"))
+		if err != nil {
+			t.Fatalf("stat: %v", err)
+		}
+		if got := find(t, prev, "static", "deep").ModTime; got != info.ModTime().UnixNano() {
+			t.Errorf("ModTime = %d, want %d", got, info.ModTime().UnixNano())
+		}
+	})
+
+	t.Run("GivenUnchangedDirs_WhenRefreshed_ThenCachedFilesReused", func(t *testing.T) {
+		root, prev := setup(t)
+		// Rewriting a file in place leaves its directory's mtime alone, so
+		// the refresh can't see it: proof that the directory wasn't read.
+		if err := os.WriteFile(filepath.Join(root, "static", "a.bin"), bytes(fileSizeSmall), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+
+		got, err := scanner.Refresh(context.Background(), prev, nil, scanner.Options{})
+		if err != nil {
+			t.Fatalf("Refresh: %v", err)
+		}
+		if got == prev || got.Size() != prev.Size() || find(t, got, "static", "a.bin").Size() != fileSizeLarge {
+			t.Errorf("size %d, want the cached %d in a new tree", got.Size(), prev.Size())
+		}
+		if e := find(t, got, "static", "deep", "d", "e.db"); e.Parent != find(t, got, "static", "deep", "d") {
+			t.Error("reused entries should be parented into the new tree")
+		}
+	})
+
+	t.Run("GivenChangesAtSeveralDepths_WhenRefreshed_ThenOnlyChangedDirsReRead", func(t *testing.T) {
+		root, prev := setup(t)
+		if err := os.WriteFile(filepath.Join(root, "static", "deep", "d", "new.bin"), bytes(fileSizeLarge), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		if err := os.RemoveAll(filepath.Join(root, "busy")); err != nil {
+			t.Fatalf("remove: %v", err)
+		}
+
+		got, err := scanner.Refresh(context.Background(), prev, nil, scanner.Options{})
+		if err != nil {
+			t.Fatalf("Refresh: %v", err)
+		}
+		want := prev.Size() + fileSizeLarge - fileSizeSmall
+		if got.Size() != want || len(got.Children) != 2 {
+			t.Errorf("Size() = %d with %d children, want %d with 2", got.Size(), len(got.Children), want)
+		}
+		if d := find(t, got, "static", "deep", "d"); d.Size() != fileSizeSmall+fileSizeLarge || len(d.Children) != 2 {
+			t.Errorf("d = %d with %d children, want the new file counted", d.Size(), len(d.Children))
+		}
+		if static := find(t, got, "static"); static.Size() != fileSizeLarge*2+fileSizeMedium+fileSizeSmall {
+			t.Errorf("static = %d, want its reused total plus the new file", static.Size())
+		}
+	})
+
+	t.Run("GivenHardlinkAcrossReusedAndChangedDirs_WhenRefreshed_ThenInodeCountedOnce", func(t *testing.T) {
+		root, _ := setup(t)
+		if err := os.Link(filepath.Join(root, "static", "a.bin"), filepath.Join(root, "busy", "a.link")); err != nil {
+			t.Skipf("hard links unsupported: %v", err)
+		}
+		prev, err := scanner.Scan(context.Background(), root, nil)
+		if err != nil {
+			t.Fatalf("Scan: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(root, "busy", "new.log"), bytes(fileSizeSmall), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+
+		got, err := scanner.Refresh(context.Background(), prev, nil, scanner.Options{})
+		if err != nil {
+			t.Fatalf("Refresh: %v", err)
+		}
+		if want := prev.Size() + fileSizeSmall; got.Size() != want {
+			t.Errorf("Size() = %d, want %d", got.Size(), want)
+		}
+		a, link := find(t, got, "static", "a.bin"), find(t, got, "busy", "a.link")
+		if a.IsHardlink == link.IsHardlink || a.Inode == nil || link.Inode == nil {
+			t.Errorf("IsHardlink = %v/%v, want exactly one repeat link with both inodes kept", a.IsHardlink, link.IsHardlink)
+		}
+	})
+
+	t.Run("GivenTreeWithoutModTimes_WhenRefreshed_ThenFullyReRead", func(t *testing.T) {
+		root, _ := setup(t)
+		prev := &scanner.Node{Name: root, IsDir: true}
+		got, err := scanner.Refresh(context.Background(), prev, nil, scanner.Options{})
+		if err != nil {
+			t.Fatalf("Refresh: %v", err)
+		}
+		if want := int64(fileSizeLarge + fileSizeMedium + 3*fileSizeSmall); got.Size() != want {
+			t.Errorf("Size() = %d, want %d", got.Size(), want)
+		}
+	})
+}
+
+func TestScanTimes(t *testing.T) {
+	day := func(n int) time.Time { return time.Date(2026, 1, n, 0, 0, 0, 0, time.UTC) }
+	// setup builds a tree whose every entry has a known mtime, directories
+	// last since creating files touches them.
+	setup := func(t *testing.T) string {
+		t.Helper()
+		root := makeTestDir(t, map[string][]byte{
+			"old/a.bin":     bytes(fileSizeSmall),
+			"old/sub/b.bin": bytes(fileSizeSmall),
+			"new/c.bin":     bytes(fileSizeSmall),
+		})
+		for rel, mtime := range map[string]time.Time{
+			"old/a.bin": day(1), "old/sub/b.bin": day(3), "new/c.bin": day(20),
+			"old/sub": day(2), "old": day(2), "new": day(4), ".": day(5),
+		} {
+			if err := os.Chtimes(filepath.Join(root, rel), day(30), mtime); err != nil {
+				t.Fatalf("chtimes: %v", err)
+			}
+		}
+		return root
+	}
+
+	testCases := []struct {
+		name string
+		scan func(t *testing.T, root string) *scanner.Node
+	}{
+		{"GivenTree_WhenScanned_ThenFileTimesAndNewestPerDirRecorded", func(t *testing.T, root string) *scanner.Node {
+			n, err := scanner.Scan(context.Background(), root, nil)
+			if err != nil {
+				t.Fatalf("Scan: %v", err)
+			}
+			return n
+		}},
+		{"GivenUnchangedTree_WhenRefreshed_ThenTimesCarriedOver", func(t *testing.T, root string) *scanner.Node {
+			prev, err := scanner.Scan(context.Background(), root, nil)
+			if err != nil {
+				t.Fatalf("Scan: %v", err)
+			}
+			n, err := scanner.Refresh(context.Background(), prev, nil, scanner.Options{})
+			if err != nil {
+				t.Fatalf("Refresh: %v", err)
+			}
+			return n
+		}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			root := tc.scan(t, setup(t))
+			old := childNamed(t, root, "old")
+			a := childNamed(t, old, "a.bin")
+			if a.ModTime != day(1).UnixNano() || a.Newest() != a.ModTime {
+				t.Errorf("a.bin ModTime/Newest = %d/%d, want %d", a.ModTime, a.Newest(), day(1).UnixNano())
+			}
+			if runtime.GOOS == "linux" && a.AccessTime != day(30).UnixNano() {
+				t.Errorf("a.bin AccessTime = %d, want %d", a.AccessTime, day(30).UnixNano())
+			}
+			if got := old.Newest(); got != day(3).UnixNano() {
+				t.Errorf("old.Newest() = %v, want day 3 from old/sub/b.bin", time.Unix(0, got).UTC())
+			}
+			if got := root.Newest(); got != day(20).UnixNano() {
+				t.Errorf("root.Newest() = %v, want day 20 from new/c.bin", time.Unix(0, got).UTC())
+			}
+		})
+	}
+}
+
+func TestScanCounts(t *testing.T) {
+	layout := map[string][]byte{
+		"top.txt":   bytes(fileSizeSmall),
+		"a/x.bin":   bytes(fileSizeSmall),
+		"a/b/y.bin": bytes(fileSizeSmall),
+		"a/b/z.bin": bytes(fileSizeSmall),
+		"c/w.bin":   bytes(fileSizeSmall),
+	}
+	testCases := []struct {
+		name string
+		scan func(t *testing.T, root string) *scanner.Node
+	}{
+		{"GivenTree_WhenScanned_ThenEntriesCountedRecursively", func(t *testing.T, root string) *scanner.Node {
+			n, err := scanner.Scan(context.Background(), root, nil)
+			if err != nil {
+				t.Fatalf("Scan: %v", err)
+			}
+			return n
+		}},
+		{"GivenUnchangedTree_WhenRefreshed_ThenCountsCarriedOver", func(t *testing.T, root string) *scanner.Node {
+			prev, err := scanner.Scan(context.Background(), root, nil)
+			if err != nil {
+				t.Fatalf("Scan: %v", err)
+			}
+			n, err := scanner.Refresh(context.Background(), prev, nil, scanner.Options{})
+			if err != nil {
+				t.Fatalf("Refresh: %v", err)
+			}
+			return n
+		}},
+		{"GivenMaxDepth_WhenScanned_ThenCollapsedDirsKeepCounts", func(t *testing.T, root string) *scanner.Node {
+			n, err := scanner.ScanWithOptions(context.Background(), root, nil, scanner.Options{MaxDepth: 1})
+			if err != nil {
+				t.Fatalf("ScanWithOptions: %v", err)
+			}
+			return n
+		}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			root := tc.scan(t, makeTestDir(t, layout))
+			if root.Files() != 5 || root.Dirs() != 3 {
+				t.Errorf("root files/dirs = %d/%d, want 5/3", root.Files(), root.Dirs())
+			}
+			a := childNamed(t, root, "a")
+			if a.Files() != 3 || a.Dirs() != 1 || a.Items() != 4 {
+				t.Errorf("a files/dirs/items = %d/%d/%d, want 3/1/4", a.Files(), a.Dirs(), a.Items())
+			}
+			if top := childNamed(t, root, "top.txt"); top.Items() != 0 {
+				t.Errorf("top.txt Items() = %d, want 0", top.Items())
+			}
+		})
+	}
+}
+
+func TestScanErrors(t *testing.T) {
+	setup := func(t *testing.T) string {
+		t.Helper()
+		root := makeTestDir(t, map[string][]byte{
+			"a/b/y.bin": bytes(fileSizeSmall),
+			"c/w.bin":   bytes(fileSizeSmall),
+		})
+		if err := os.Symlink(filepath.Join(root, "missing"), filepath.Join(root, "a", "b", "gone")); err != nil {
+			t.Fatalf("symlink: %v", err)
+		}
+		return root
+	}
+	testCases := []struct {
+		name string
+		opts scanner.Options
+	}{
+		{"GivenDanglingLink_WhenScanned_ThenCountedOnEveryAncestor", scanner.Options{FollowSymlinks: true}},
+		{"GivenDanglingLinkBelowMaxDepth_WhenScanned_ThenCollapsedDirCountsIt", scanner.Options{FollowSymlinks: true, MaxDepth: 1}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			root, err := scanner.ScanWithOptions(context.Background(), setup(t), nil, tc.opts)
+			if err != nil {
+				t.Fatalf("ScanWithOptions: %v", err)
+			}
+			if a, c := childNamed(t, root, "a"), childNamed(t, root, "c"); root.Errors() != 1 || a.Errors() != 1 || c.Errors() != 0 {
+				t.Errorf("errors root/a/c = %d/%d/%d, want 1/1/0", root.Errors(), a.Errors(), c.Errors())
+			}
+		})
+	}
+
+	t.Run("GivenUnreadableChild_WhenRemovedAndAdded_ThenAncestorsRecounted", func(t *testing.T) {
+		root, err := scanner.ScanWithOptions(context.Background(), setup(t), nil, scanner.Options{FollowSymlinks: true})
+		if err != nil {
+			t.Fatalf("ScanWithOptions: %v", err)
+		}
+		a := childNamed(t, root, "a")
+		b := childNamed(t, a, "b")
+		a.RemoveChild(b)
+		if root.Errors() != 0 || a.Errors() != 0 {
+			t.Errorf("errors root/a = %d/%d after removal, want 0/0", root.Errors(), a.Errors())
+		}
+		b.Err = errors.New("permission denied")
+		a.AddChild(b)
+		if root.Errors() != 2 || a.Errors() != 2 || b.Unreadable() != 2 {
+			t.Errorf("errors root/a/b = %d/%d/%d after re-adding, want 2/2/2", root.Errors(), a.Errors(), b.Unreadable())
+		}
+	})
+}
@@ -0,0 +1,53 @@
+// Package scannertest builds scanned trees by hand for the tests of the
+// packages that consume them.
+package scannertest
+
+import "github.com/mobanhawi/aster/internal/scanner"
+
+// Node returns a node with children attached below it. Its apparent size is
+// size and its usage twice that, so tests can tell the size modes apart. A
+// directory's counts and errors cover its children's, which must be complete
+// before it is built; its sizes are taken as given.
+func Node(name string, isDir bool, size int64, children ...*scanner.Node) *scanner.Node {
+	n := &scanner.Node{Name: name, IsDir: isDir, Children: children}
+	n.SetSize(size)
+	n.SetUsage(size * 2)
+	for _, c := range children {
+		c.Parent = n
+		n.AddCounts(c.Entries())
+		n.AddErrors(c.Unreadable())
+	}
+	return n
+}
+
+// SampleTree returns a fresh copy of a small tree with a file type of every
+// kind and a nested directory:
+//
+//	/data             10000
+//	  media            7000
+//	    movie.MKV      5000
+//	    clips          2000
+//	      clip.mp4     1000
+//	      cover.jpg    1000
+//	  build            2500
+//	    main.o         1500
+//	    main.go        1000
+//	  Makefile          300
+//	  .bashrc           200
+func SampleTree() *scanner.Node {
+	return Node("/data", true, 10000,
+		Node("media", true, 7000,
+			Node("movie.MKV", false, 5000),
+			Node("clips", true, 2000,
+				Node("clip.mp4", false, 1000),
+				Node("cover.jpg", false, 1000),
+			),
+		),
+		Node("build", true, 2500,
+			Node("main.o", false, 1500),
+			Node("main.go", false, 1000),
+		),
+		Node("Makefile", false, 300),
+		Node(".bashrc", false, 200),
+	)
+}
@@ -0,0 +1,25 @@
+//go:build !unix
+
+package scanner
+
+import "io/fs"
+
+// allocatedSize returns the apparent size on platforms without st_blocks.
+func allocatedSize(info fs.FileInfo) int64 {
+	return info.Size()
+}
+
+// hardlinkID always reports false: link counts are not exposed here.
+func hardlinkID(_ fs.FileInfo) (fileID, bool) {
+	return fileID{}, false
+}
+
+// deviceID always reports false: device IDs are not exposed here.
+func deviceID(_ fs.FileInfo) (uint64, bool) {
+	return 0, false
+}
+
+// inodeID always reports false: inode numbers are not exposed here.
+func inodeID(_ fs.FileInfo) (fileID, bool) {
+	return fileID{}, false
+}
@@ -0,0 +1,45 @@
+//go:build unix
+
+package scanner
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// allocatedSize returns the bytes actually allocated on disk for info, derived
+// from st_blocks (which POSIX always reports in 512-byte units).
+func allocatedSize(info fs.FileInfo) int64 {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return st.Blocks * 512
+	}
+	return info.Size()
+}
+
+// hardlinkID returns the (device, inode) identity of a non-directory with more
+// than one link. ok is false for singly-linked files, which need no tracking.
+func hardlinkID(info fs.FileInfo) (id fileID, ok bool) {
+	st, isStat := info.Sys().(*syscall.Stat_t)
+	if !isStat || info.IsDir() || st.Nlink < 2 {
+		return fileID{}, false
+	}
+	return inodeID(info)
+}
+
+// inodeID returns the (device, inode) identity of any file.
+func inodeID(info fs.FileInfo) (fileID, bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fileID{}, false
+	}
+	return fileID{dev: uint64(st.Dev), ino: st.Ino}, true //nolint:unconvert // Dev is int32 on darwin
+}
+
+// deviceID returns the ID of the device containing the file described by info.
+func deviceID(info fs.FileInfo) (uint64, bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return uint64(st.Dev), true //nolint:unconvert // Dev is int32 on darwin
+}
@@ -0,0 +1,116 @@
+// Package search finds entries of a scanned tree by name, anywhere below a
+// directory. The walk is resumable so a caller can spread a search of
+// millions of nodes over many short steps and show matches as they turn up.
+package search
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+
+	"github.com/mobanhawi/aster/internal/scanner"
+)
+
+// Syntax selects how a pattern is read.
+type Syntax int
+
+const (
+	// Glob reads patterns with filepath.Match syntax: *, ? and [...].
+	Glob Syntax = iota
+	// Regexp reads patterns as Go regular expressions, matching anywhere in
+	// the name unless anchored.
+	Regexp
+)
+
+// String names the syntax as shown to the user.
+func (s Syntax) String() string {
+	if s == Regexp {
+		return "regex"
+	}
+	return "glob"
+}
+
+// Matcher tests entry names against a compiled pattern.
+type Matcher struct {
+	glob string
+	re   *regexp.Regexp
+}
+
+// Compile parses pattern in the given syntax.
+func Compile(pattern string, syntax Syntax) (*Matcher, error) {
+	if syntax == Regexp {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		return &Matcher{re: re}, nil
+	}
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("glob %q: %w", pattern, err)
+	}
+	return &Matcher{glob: pattern}, nil
+}
+
+// Match reports whether name matches.
+func (m *Matcher) Match(name string) bool {
+	if m.re != nil {
+		return m.re.MatchString(name)
+	}
+	// The pattern was validated by Compile, so err is always nil here.
+	ok, err := filepath.Match(m.glob, name)
+	return err == nil && ok
+}
+
+// Walker is a depth-first search below a root, advanced by Next.
+type Walker struct {
+	m       *Matcher
+	stack   []frame
+	visited int
+}
+
+// frame is a directory being walked and the index of its next child.
+type frame struct {
+	dir  *scanner.Node
+	next int
+}
+
+// Walk returns a search for entries below root whose names match.
+func (m *Matcher) Walk(root *scanner.Node) *Walker {
+	return &Walker{m: m, stack: []frame{{dir: root}}}
+}
+
+// Next visits up to budget more entries in pre-order and returns the
+// matches among them, and whether the walk is complete.
+//
+// Next only reads the tree, but it must not run concurrently with changes
+// to it. Between calls the tree may change: entries added to or removed
+// from a directory being walked can shift its children so that one is
+// visited twice or skipped, and matches found earlier may have left the
+// tree since.
+func (w *Walker) Next(budget int) (found []*scanner.Node, done bool) {
+	for range budget {
+		if len(w.stack) == 0 {
+			return found, true
+		}
+		top := &w.stack[len(w.stack)-1]
+		if top.next >= len(top.dir.Children) {
+			w.stack = w.stack[:len(w.stack)-1]
+			continue
+		}
+		c := top.dir.Children[top.next]
+		top.next++
+		w.visited++
+		if w.m.Match(c.Name) {
+			found = append(found, c)
+		}
+		if c.IsDir && len(c.Children) > 0 {
+			w.stack = append(w.stack, frame{dir: c})
+		}
+	}
+	return found, len(w.stack) == 0
+}
+
+// Visited returns how many entries the walk has looked at so far.
+func (w *Walker) Visited() int {
+	return w.visited
+}
@@ -0,0 +1,128 @@
+package search_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mobanhawi/aster/internal/scanner"
+	"github.com/mobanhawi/aster/internal/scanner/scannertest"
+	"github.com/mobanhawi/aster/internal/search"
+)
+
+// ── Helpers ──────────────────────────────────────────────────────────────────
+
+func sampleTree() *scanner.Node {
+	return scannertest.Node("/data", true, 0,
+		scannertest.Node("photos", true, 0,
+			scannertest.Node("IMG_0001.jpg", false, 0),
+			scannertest.Node("2024", true, 0,
+				scannertest.Node("IMG_0002.JPG", false, 0),
+				scannertest.Node("notes.txt", false, 0),
+			),
+		),
+		scannertest.Node("empty", true, 0),
+		scannertest.Node("report.pdf", false, 0),
+		scannertest.Node("IMG_backup", true, 0,
+			scannertest.Node("IMG_0003.jpg", false, 0),
+		),
+	)
+}
+
+// walkAll runs w to completion in steps of budget and returns the full paths
+// of the matches, in the order found.
+func walkAll(t *testing.T, w *search.Walker, budget int) []string {
+	t.Helper()
+	var got []string
+	for steps := 0; ; steps++ {
+		if steps > 100 {
+			t.Fatal("walk did not finish")
+		}
+		found, done := w.Next(budget)
+		for _, n := range found {
+			got = append(got, n.FullPath())
+		}
+		if done {
+			return got
+		}
+	}
+}
+
+// ── Tests ────────────────────────────────────────────────────────────────────
+
+func TestWalk(t *testing.T) {
+	testCases := []struct {
+		name    string
+		pattern string
+		syntax  search.Syntax
+		budget  int
+		want    []string
+	}{
+		{
+			name:    "GivenGlob_WhenWalked_ThenWholeNamesMatchedCaseSensitively",
+			pattern: "IMG_*.jpg",
+			syntax:  search.Glob,
+			budget:  1000,
+			want:    []string{"/data/photos/IMG_0001.jpg", "/data/IMG_backup/IMG_0003.jpg"},
+		},
+		{
+			name:    "GivenRegexp_WhenWalked_ThenDirectoriesMatchToo",
+			pattern: "(?i)^img_",
+			syntax:  search.Regexp,
+			budget:  1000,
+			want: []string{
+				"/data/photos/IMG_0001.jpg", "/data/photos/2024/IMG_0002.JPG",
+				"/data/IMG_backup", "/data/IMG_backup/IMG_0003.jpg",
+			},
+		},
+		{
+			name:    "GivenTinyBudget_WhenWalkedInSteps_ThenSameMatchesInSameOrder",
+			pattern: "(?i)^img_",
+			syntax:  search.Regexp,
+			budget:  1,
+			want: []string{
+				"/data/photos/IMG_0001.jpg", "/data/photos/2024/IMG_0002.JPG",
+				"/data/IMG_backup", "/data/IMG_backup/IMG_0003.jpg",
+			},
+		},
+		{
+			name:    "GivenNoMatch_WhenWalked_ThenNothingFound",
+			pattern: "*.mov",
+			syntax:  search.Glob,
+			budget:  1000,
+			want:    nil,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, err := search.Compile(tc.pattern, tc.syntax)
+			if err != nil {
+				t.Fatalf("Compile: %v", err)
+			}
+			w := m.Walk(sampleTree())
+			if got := walkAll(t, w, tc.budget); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("matches = %v, want %v", got, tc.want)
+			}
+			if w.Visited() != 9 {
+				t.Errorf("Visited() = %d, want all 9 entries", w.Visited())
+			}
+		})
+	}
+}
+
+func TestCompile(t *testing.T) {
+	testCases := []struct {
+		name    string
+		pattern string
+		syntax  search.Syntax
+	}{
+		{"GivenMalformedGlob_WhenCompiled_ThenError", "[a-", search.Glob},
+		{"GivenMalformedRegexp_WhenCompiled_ThenError", "(unclosed", search.Regexp},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := search.Compile(tc.pattern, tc.syntax); err == nil {
+				t.Errorf("Compile(%q, %v) succeeded, want an error", tc.pattern, tc.syntax)
+			}
+		})
+	}
+}
@@ -0,0 +1,367 @@
+// Package snapshot persists scanned trees to disk so they can be browsed again
+// without rescanning.
+//
+// A snapshot is a gzip stream holding a magic header, a format version, the
+// scan metadata and then every node in pre-order. Integers are varints and
+// strings are length-prefixed, which keeps multi-million-node trees compact.
+package snapshot
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mobanhawi/aster/internal/scanner"
+)
+
+// magic identifies a snapshot stream after decompression.
+const magic = "ASTERSNP"
+
+// version is bumped whenever the encoding changes; snapshots in any other
+// version are rejected.
+const version = 1
+
+// maxStringLen bounds decoded strings so a corrupt file can't force a huge
+// allocation. It comfortably exceeds PATH_MAX and any error message.
+const maxStringLen = 1 << 16
+
+// ErrNotSnapshot is returned when the input is not an aster snapshot.
+var ErrNotSnapshot = errors.New("not an aster snapshot")
+
+// Node flag bits.
+const (
+	flagDir = 1 << iota
+	flagHardlink
+	flagMount
+	flagErr
+	flagLink
+	flagInode
+	flagType
+)
+
+// impliedType is the file type a node is assumed to have when flagType is
+// not set: that of a plain directory or a regular file.
+func impliedType(isDir bool) fs.FileMode {
+	if isDir {
+		return fs.ModeDir
+	}
+	return 0
+}
+
+// Meta describes the scan a snapshot was taken from.
+type Meta struct {
+	// Root is the absolute path that was scanned.
+	Root string
+	// ScannedAt is when the scan finished.
+	ScannedAt time.Time
+	// Options are the scanner options the tree was built with.
+	Options scanner.Options
+}
+
+// Save writes root and meta to path. The file is written to a temporary
+// sibling first and renamed into place, so an interrupted save never leaves a
+// truncated snapshot behind.
+func Save(path string, root *scanner.Node, meta Meta) (err error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, os.Remove(tmp.Name()))
+		}
+	}()
+
+	if err = Write(tmp, root, meta); err != nil {
+		return errors.Join(err, tmp.Close())
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Load reads a snapshot written by Save.
+func Load(path string) (*scanner.Node, Meta, error) {
+	// #nosec G304 -- the snapshot path is supplied by the user on the command line
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, Meta{}, err
+	}
+	root, meta, err := Read(f)
+	if cerr := f.Close(); cerr != nil && err == nil {
+		err = cerr
+	}
+	return root, meta, err
+}
+
+// Write encodes root and meta to w.
+func Write(w io.Writer, root *scanner.Node, meta Meta) error {
+	zw := gzip.NewWriter(w)
+	e := &encoder{w: bufio.NewWriter(zw)}
+
+	e.bytes([]byte(magic))
+	e.uvarint(version)
+	e.meta(meta)
+	e.node(root)
+
+	if e.err != nil {
+		return e.err
+	}
+	if err := e.w.Flush(); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// Read decodes a snapshot from r, rebuilding Parent links and sizes.
+func Read(r io.Reader) (*scanner.Node, Meta, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, Meta{}, ErrNotSnapshot
+	}
+	d := &decoder{r: bufio.NewReader(zr)}
+	root, meta, err := d.snapshot()
+	if cerr := zr.Close(); cerr != nil && err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, Meta{}, err
+	}
+	return root, meta, nil
+}
+
+// snapshot decodes the header, metadata and node tree.
+func (d *decoder) snapshot() (*scanner.Node, Meta, error) {
+	head := make([]byte, len(magic))
+	if _, err := io.ReadFull(d.r, head); err != nil || string(head) != magic {
+		return nil, Meta{}, ErrNotSnapshot
+	}
+	if v := d.uvarint(); d.err == nil && v != version {
+		return nil, Meta{}, fmt.Errorf("unsupported snapshot version %d (want %d)", v, version)
+	}
+
+	meta := d.meta()
+	root := d.node(nil)
+	if d.err != nil {
+		return nil, Meta{}, fmt.Errorf("corrupt snapshot: %w", d.err)
+	}
+	return root, meta, nil
+}
+
+// encoder writes primitives, remembering the first error so callers can
+// check once at the end.
+type encoder struct {
+	w   *bufio.Writer
+	buf [binary.MaxVarintLen64]byte
+	err error
+}
+
+func (e *encoder) bytes(b []byte) {
+	if e.err == nil {
+		_, e.err = e.w.Write(b)
+	}
+}
+
+func (e *encoder) uvarint(v uint64) {
+	e.bytes(e.buf[:binary.PutUvarint(e.buf[:], v)])
+}
+
+func (e *encoder) varint(v int64) {
+	e.bytes(e.buf[:binary.PutVarint(e.buf[:], v)])
+}
+
+func (e *encoder) string(s string) {
+	e.uvarint(uint64(len(s)))
+	e.bytes([]byte(s))
+}
+
+func (e *encoder) bool(b bool) {
+	if b {
+		e.uvarint(1)
+	} else {
+		e.uvarint(0)
+	}
+}
+
+func (e *encoder) meta(m Meta) {
+	e.string(m.Root)
+	e.varint(m.ScannedAt.UnixNano())
+	e.uvarint(uint64(len(m.Options.Exclude)))
+	for _, p := range m.Options.Exclude {
+		e.string(p)
+	}
+	e.varint(int64(m.Options.MaxDepth))
+	e.bool(m.Options.SkipHidden)
+	e.bool(m.Options.SkipSymlinks)
+	e.bool(m.Options.FollowSymlinks)
+	e.bool(m.Options.OneFileSystem)
+}
+
+func (e *encoder) node(n *scanner.Node) {
+	var flags uint64
+	if n.IsDir {
+		flags |= flagDir
+	}
+	if n.IsHardlink {
+		flags |= flagHardlink
+	}
+	if n.IsMount {
+		flags |= flagMount
+	}
+	if n.Err != nil {
+		flags |= flagErr
+	}
+	if n.LinkTarget != "" {
+		flags |= flagLink
+	}
+	if n.Inode != nil {
+		flags |= flagInode
+	}
+	if n.Type != impliedType(n.IsDir) {
+		flags |= flagType
+	}
+
+	e.string(n.Name)
+	e.uvarint(flags)
+	e.varint(n.Size())
+	e.varint(n.Usage())
+	if n.Err != nil {
+		e.string(n.Err.Error())
+	}
+	if n.LinkTarget != "" {
+		e.string(n.LinkTarget)
+	}
+	if n.Inode != nil {
+		e.uvarint(n.Inode.Dev)
+		e.uvarint(n.Inode.Ino)
+		e.varint(n.Inode.Size)
+		e.varint(n.Inode.Usage)
+	}
+	if flags&flagType != 0 {
+		e.uvarint(uint64(n.Type))
+	}
+	e.varint(n.ModTime)
+	if !n.IsDir {
+		e.varint(n.AccessTime)
+	}
+	if n.IsDir {
+		e.varint(n.Files())
+		e.varint(n.Dirs())
+		e.varint(n.Errors())
+		e.uvarint(uint64(len(n.Children)))
+		for _, c := range n.Children {
+			e.node(c)
+		}
+	}
+}
+
+// decoder mirrors encoder; after the first error every read returns zero.
+type decoder struct {
+	r   *bufio.Reader
+	err error
+}
+
+func (d *decoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, err := binary.ReadUvarint(d.r)
+	d.err = err
+	return v
+}
+
+func (d *decoder) varint() int64 {
+	if d.err != nil {
+		return 0
+	}
+	v, err := binary.ReadVarint(d.r)
+	d.err = err
+	return v
+}
+
+func (d *decoder) string() string {
+	n := d.uvarint()
+	if d.err != nil {
+		return ""
+	}
+	if n > maxStringLen {
+		d.err = fmt.Errorf("string length %d exceeds limit", n)
+		return ""
+	}
+	b := make([]byte, n)
+	_, d.err = io.ReadFull(d.r, b)
+	return string(b)
+}
+
+func (d *decoder) bool() bool {
+	return d.uvarint() != 0
+}
+
+func (d *decoder) meta() Meta {
+	var m Meta
+	m.Root = d.string()
+	m.ScannedAt = time.Unix(0, d.varint())
+	n := d.uvarint()
+	for i := uint64(0); i < n && d.err == nil; i++ {
+		m.Options.Exclude = append(m.Options.Exclude, d.string())
+	}
+	m.Options.MaxDepth = int(d.varint()) // #nosec G115 -- written from an int by encoder.meta
+	m.Options.SkipHidden = d.bool()
+	m.Options.SkipSymlinks = d.bool()
+	m.Options.FollowSymlinks = d.bool()
+	m.Options.OneFileSystem = d.bool()
+	return m
+}
+
+func (d *decoder) node(parent *scanner.Node) *scanner.Node {
+	n := &scanner.Node{Parent: parent, Name: d.string()}
+	flags := d.uvarint()
+	n.IsDir = flags&flagDir != 0
+	n.IsHardlink = flags&flagHardlink != 0
+	n.IsMount = flags&flagMount != 0
+	n.SetSize(d.varint())
+	n.SetUsage(d.varint())
+	if flags&flagErr != 0 {
+		n.Err = errors.New(d.string())
+	}
+	if flags&flagLink != 0 {
+		n.LinkTarget = d.string()
+	}
+	if flags&flagInode != 0 {
+		n.Inode = &scanner.Inode{Dev: d.uvarint(), Ino: d.uvarint(), Size: d.varint(), Usage: d.varint()}
+	}
+	n.Type = impliedType(n.IsDir)
+	if flags&flagType != 0 {
+		n.Type = fs.FileMode(d.uvarint()) // #nosec G115 -- written from an fs.FileMode by encoder.node
+	}
+	n.ModTime = d.varint()
+	if !n.IsDir {
+		n.AccessTime = d.varint()
+	}
+	newest := n.ModTime
+	if n.IsDir {
+		// Stored rather than summed: a directory collapsed by MaxDepth has
+		// no children to count.
+		n.SetCounts(d.varint(), d.varint())
+		n.SetErrors(d.varint())
+		count := d.uvarint()
+		// Don't trust the count for preallocation; a corrupt value would
+		// otherwise allocate before the stream runs dry.
+		n.Children = make([]*scanner.Node, 0, min(count, 1024))
+		for i := uint64(0); i < count && d.err == nil; i++ {
+			c := d.node(n)
+			n.Children = append(n.Children, c)
+			newest = max(newest, c.Newest())
+		}
+	}
+	n.SetNewest(newest)
+	return n
+}
@@ -0,0 +1,175 @@
+package snapshot_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mobanhawi/aster/internal/scanner"
+	"github.com/mobanhawi/aster/internal/scanner/scannertest"
+	"github.com/mobanhawi/aster/internal/snapshot"
+)
+
+// ── Helpers ──────────────────────────────────────────────────────────────────
+
+func sampleTree() *scanner.Node {
+	link := scannertest.Node("link", false, 0)
+	link.LinkTarget = "../elsewhere"
+	link.Type = fs.ModeSymlink
+	pipe := scannertest.Node("pipe", false, 0)
+	pipe.Type = fs.ModeNamedPipe
+	shared := scannertest.Node("shared.bin", false, 0)
+	shared.IsHardlink = true
+	shared.Inode = &scanner.Inode{Dev: 2049, Ino: 1 << 40, Size: 300, Usage: 4096}
+	mnt := scannertest.Node("mnt", true, 0)
+	mnt.IsMount = true
+	locked := scannertest.Node("locked", true, 0)
+	locked.Err = errors.New("permission denied")
+	collapsed := scannertest.Node("deep", true, 0) // cut off by MaxDepth
+	collapsed.SetCounts(3, 1)
+	collapsed.SetErrors(1)
+
+	big := scannertest.Node("big.bin", false, 1000)
+	big.ModTime = time.Date(2026, 9, 30, 9, 0, 0, 0, time.UTC).UnixNano()
+	big.AccessTime = time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC).UnixNano()
+	sub := scannertest.Node("sub", true, 1000, big, shared)
+	sub.ModTime = time.Date(2026, 9, 30, 8, 0, 0, 1, time.UTC).UnixNano()
+
+	root := scannertest.Node("/data", true, 1500,
+		sub,
+		scannertest.Node("small.txt", false, 500),
+		link, pipe, mnt, locked, collapsed,
+	)
+	// Newest is derived on load, from big.bin up.
+	for _, n := range []*scanner.Node{big, sub, root} {
+		n.SetNewest(big.ModTime)
+	}
+	return root
+}
+
+// assertSameTree compares the persisted fields of two trees recursively.
+func assertSameTree(t *testing.T, want, got *scanner.Node) {
+	t.Helper()
+	if got.Name != want.Name || got.IsDir != want.IsDir || got.IsHardlink != want.IsHardlink ||
+		got.IsMount != want.IsMount || got.LinkTarget != want.LinkTarget || got.Type != want.Type || got.ModTime != want.ModTime ||
+		got.AccessTime != want.AccessTime || got.Newest() != want.Newest() {
+		t.Fatalf("node %q: got %+v", want.Name, got)
+	}
+	if (got.Inode == nil) != (want.Inode == nil) || (got.Inode != nil && *got.Inode != *want.Inode) {
+		t.Errorf("%s: Inode = %+v, want %+v", want.Name, got.Inode, want.Inode)
+	}
+	if got.Files() != want.Files() || got.Dirs() != want.Dirs() || got.Errors() != want.Errors() {
+		t.Errorf("%s: files/dirs/errors = %d/%d/%d, want %d/%d/%d", want.Name,
+			got.Files(), got.Dirs(), got.Errors(), want.Files(), want.Dirs(), want.Errors())
+	}
+	if got.Size() != want.Size() || got.Usage() != want.Usage() {
+		t.Errorf("%s: size/usage = %d/%d, want %d/%d", want.Name, got.Size(), got.Usage(), want.Size(), want.Usage())
+	}
+	if (got.Err == nil) != (want.Err == nil) || (got.Err != nil && got.Err.Error() != want.Err.Error()) {
+		t.Errorf("%s: Err = %v, want %v", want.Name, got.Err, want.Err)
+	}
+	if len(got.Children) != len(want.Children) {
+		t.Fatalf("%s: %d children, want %d", want.Name, len(got.Children), len(want.Children))
+	}
+	for i := range want.Children {
+		if got.Children[i].Parent != got {
+			t.Errorf("%s: child %d has wrong Parent", want.Name, i)
+		}
+		assertSameTree(t, want.Children[i], got.Children[i])
+	}
+}
+
+// ── Tests ────────────────────────────────────────────────────────────────────
+
+func TestRoundTrip(t *testing.T) {
+	t.Run("GivenTreeAndMeta_WhenWrittenAndRead_ThenEverythingRestored", func(t *testing.T) {
+		tree := sampleTree()
+		meta := snapshot.Meta{
+			Root:      "/data",
+			ScannedAt: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC),
+			Options:   scanner.Options{Exclude: []string{"*.git"}, MaxDepth: 3, OneFileSystem: true},
+		}
+
+		var buf bytes.Buffer
+		if err := snapshot.Write(&buf, tree, meta); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+		got, gotMeta, err := snapshot.Read(&buf)
+		if err != nil {
+			t.Fatalf("Read: %v", err)
+		}
+
+		assertSameTree(t, tree, got)
+		if got.FullPath() != "/data" || got.Children[0].Children[0].FullPath() != "/data/sub/big.bin" {
+			t.Errorf("FullPath not reconstructed: %q", got.Children[0].Children[0].FullPath())
+		}
+		if gotMeta.Root != meta.Root || !gotMeta.ScannedAt.Equal(meta.ScannedAt) {
+			t.Errorf("meta = %+v, want %+v", gotMeta, meta)
+		}
+		if gotMeta.Options.MaxDepth != 3 || !gotMeta.Options.OneFileSystem ||
+			len(gotMeta.Options.Exclude) != 1 || gotMeta.Options.Exclude[0] != "*.git" {
+			t.Errorf("options = %+v, want %+v", gotMeta.Options, meta.Options)
+		}
+	})
+
+	t.Run("GivenSavedFile_WhenLoaded_ThenTreeRestored", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "snap.aster")
+		tree := sampleTree()
+		if err := snapshot.Save(path, tree, snapshot.Meta{Root: "/data"}); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+		got, _, err := snapshot.Load(path)
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		assertSameTree(t, tree, got)
+	})
+}
+
+func TestReadRejectsBadInput(t *testing.T) {
+	t.Run("GivenPlainText_WhenRead_ThenErrNotSnapshot", func(t *testing.T) {
+		_, _, err := snapshot.Read(strings.NewReader("hello"))
+		if !errors.Is(err, snapshot.ErrNotSnapshot) {
+			t.Errorf("err = %v, want ErrNotSnapshot", err)
+		}
+	})
+
+	t.Run("GivenTruncatedStream_WhenRead_ThenReturnsError", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := snapshot.Write(&buf, sampleTree(), snapshot.Meta{}); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+		truncated := buf.Bytes()[:buf.Len()/2]
+		if _, _, err := snapshot.Read(bytes.NewReader(truncated)); err == nil {
+			t.Error("expected error for truncated snapshot")
+		}
+	})
+
+	t.Run("GivenUnknownVersion_WhenRead_ThenReturnsError", func(t *testing.T) {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		raw := binary.AppendUvarint([]byte("ASTERSNP"), 99)
+		if _, err := zw.Write(raw); err != nil {
+			t.Fatal(err)
+		}
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		_, _, err := snapshot.Read(&buf)
+		if err == nil || !strings.Contains(err.Error(), "unsupported snapshot version 99") {
+			t.Errorf("err = %v, want unsupported version", err)
+		}
+	})
+
+	t.Run("GivenMissingFile_WhenLoaded_ThenReturnsError", func(t *testing.T) {
+		if _, _, err := snapshot.Load(filepath.Join(t.TempDir(), "missing")); err == nil {
+			t.Error("expected error for missing file")
+		}
+	})
+}
@@ -0,0 +1,11 @@
+// Package trash moves files to the desktop's trash instead of deleting them,
+// so the user can still restore them from Finder or their file manager.
+//
+// The implementation is chosen by build tag: Finder on macOS, the
+// freedesktop.org Trash specification on other Unix systems.
+package trash
+
+import "errors"
+
+// ErrUnsupported is returned by Move on platforms without a known trash.
+var ErrUnsupported = errors.New("trash is not supported on this platform")
@@ -0,0 +1,58 @@
+//go:build darwin
+
+package trash
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const cmdOsascript = "osascript"
+
+// moveScript has Finder delete the item and then, as a separate step, look up
+// where it went. The reference delete returns often can't be turned into a
+// path once the item is in the Trash; the lookup failing then yields an empty
+// path rather than failing a move that already happened.
+const moveScript = `tell application "Finder"
+	set trashed to delete (POSIX file %q as alias)
+	try
+		return POSIX path of (trashed as alias)
+	end try
+end tell
+return ""`
+
+// Move moves a file or directory to the macOS Trash via Finder, which records
+// where it came from so "Put Back" works, and returns where it now lives.
+// Finder renames the item if the Trash already holds one with the same name.
+// The returned path is empty when Finder can't tell where the item went; it
+// is trashed all the same, but Restore can't bring it back.
+func Move(path string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	cleanedPath := filepath.Clean(path)
+	script := fmt.Sprintf(moveScript, cleanedPath)
+
+	// #nosec G204 -- The application intentionally constructs commands based on user input, and we've verified sanitization
+	out, err := exec.CommandContext(ctx, cmdOsascript, "-e", script).Output()
+	if err != nil {
+		return "", err
+	}
+	// Finder reports directories with a trailing slash.
+	return strings.TrimSuffix(strings.TrimSpace(string(out)), "/"), nil
+}
+
+// Restore moves an item returned by Move back to original. It refuses to
+// overwrite anything now at original.
+func Restore(trashed, original string) error {
+	if _, err := os.Lstat(original); err == nil {
+		return &fs.PathError{Op: "restore", Path: original, Err: fs.ErrExist}
+	}
+	return os.Rename(trashed, original)
+}
@@ -0,0 +1,75 @@
+//go:build darwin
+
+package trash_test
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mobanhawi/aster/internal/trash"
+)
+
+// ── Helpers ──────────────────────────────────────────────────────────────────
+
+// requireFinder skips unless Finder is running and answers scripts, which it
+// doesn't on headless machines or without automation permission.
+func requireFinder(t *testing.T) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "osascript", "-e", `application "Finder" is running`).Output()
+	if err != nil || strings.TrimSpace(string(out)) != "true" {
+		t.Skip("Finder is not scriptable here")
+	}
+}
+
+// ── Tests ────────────────────────────────────────────────────────────────────
+
+func TestMoveAndRestore(t *testing.T) {
+	t.Run("GivenFile_WhenMovedAndRestored_ThenBackInPlace", func(t *testing.T) {
+		requireFinder(t)
+		// Finder won't trash from the per-user temp dir on every setup; the
+		// home directory is always on a volume with a Trash.
+		home, err := os.UserHomeDir()
+		if err != nil {
+			t.Skip("no home directory:", err)
+		}
+		dir, err := os.MkdirTemp(home, ".aster-trash-test")
+		if err != nil {
+			t.Skip("home directory not writable:", err)
+		}
+		t.Cleanup(func() {
+			if err := os.RemoveAll(dir); err != nil {
+				t.Log(err)
+			}
+		})
+		src := filepath.Join(dir, "old report.txt")
+		if err := os.WriteFile(src, []byte("hello"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		dest, err := trash.Move(src)
+		if err != nil {
+			t.Fatalf("Move: %v", err)
+		}
+		if _, err := os.Lstat(src); !errors.Is(err, fs.ErrNotExist) {
+			t.Fatalf("source still present after Move: %v", err)
+		}
+		if dest == "" {
+			t.Skip("Finder did not report where the item went; nothing to restore")
+		}
+		if err := trash.Restore(dest, src); err != nil {
+			t.Fatalf("Restore: %v", err)
+		}
+		if b, err := os.ReadFile(src); err != nil || string(b) != "hello" {
+			t.Errorf("restored contents = %q, %v; want hello", b, err)
+		}
+	})
+}
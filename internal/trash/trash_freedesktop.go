@@ -0,0 +1,211 @@
+//go:build unix && !darwin
+
+package trash
+
+import (
+	"errors"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// Move moves a file or directory into the freedesktop.org trash
+// (https://specifications.freedesktop.org/trash-spec/latest/) and returns
+// where it now lives. Items on the same filesystem as the home trash go
+// there; items on other volumes go to a per-volume trash at the top of their
+// mount, so nothing is ever copied across devices.
+func Move(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	dev, err := deviceOf(abs)
+	if err != nil {
+		return "", err
+	}
+	dir, top, err := trashFor(abs, dev)
+	if err != nil {
+		return "", err
+	}
+	return moveInto(dir, top, abs)
+}
+
+// Restore moves an item returned by Move back to original and drops its
+// .trashinfo record. It refuses to overwrite anything now at original.
+func Restore(trashed, original string) error {
+	if _, err := os.Lstat(original); err == nil {
+		return &fs.PathError{Op: "restore", Path: original, Err: fs.ErrExist}
+	}
+	if err := os.Rename(trashed, original); err != nil {
+		return err
+	}
+	info := filepath.Join(filepath.Dir(filepath.Dir(trashed)), "info", filepath.Base(trashed)+".trashinfo")
+	if err := os.Remove(info); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// homeTrash returns $XDG_DATA_HOME/Trash, defaulting to ~/.local/share/Trash.
+func homeTrash() (string, error) {
+	if data := os.Getenv("XDG_DATA_HOME"); filepath.IsAbs(data) {
+		return filepath.Join(data, "Trash"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share", "Trash"), nil
+}
+
+// trashFor picks the trash directory for abs, which lives on device dev. top
+// is the directory that paths in .trashinfo files are relative to, or "" when
+// they are absolute. When the volume has no trash this user can safely
+// write to, the home trash is returned; the move then fails as a
+// cross-device rename rather than writing through someone else's directory.
+func trashFor(abs string, dev uint64) (dir, top string, err error) {
+	home, err := homeTrash()
+	if err != nil {
+		return "", "", err
+	}
+	if err := makeTrashDir(home); err != nil {
+		return "", "", err
+	}
+	if homeDev, err := deviceOf(home); err == nil && homeDev == dev {
+		return home, "", nil
+	}
+
+	top = mountTop(abs, dev)
+	uid := strconv.Itoa(os.Getuid())
+
+	// An administrator-created $top/.Trash is only usable when it is a real
+	// directory with the sticky bit set; otherwise the spec says to skip it.
+	shared := filepath.Join(top, ".Trash")
+	if info, err := os.Lstat(shared); err == nil && info.IsDir() && info.Mode()&fs.ModeSticky != 0 {
+		if dir = filepath.Join(shared, uid); ownTrashDir(dir) {
+			return dir, top, nil
+		}
+	}
+	if dir = filepath.Join(top, ".Trash-"+uid); ownTrashDir(dir) {
+		return dir, top, nil
+	}
+	return home, "", nil
+}
+
+// ownTrashDir creates dir if it is missing and reports whether it is a trash
+// the current user can use: a real directory, not a symlink, owned by them.
+// Anyone who can write to the top of a volume could otherwise plant a
+// .Trash-$uid that leads elsewhere.
+func ownTrashDir(dir string) bool {
+	if err := os.Mkdir(dir, 0o700); err != nil && !errors.Is(err, fs.ErrExist) {
+		return false
+	}
+	info, err := os.Lstat(dir)
+	if err != nil || !info.IsDir() {
+		return false
+	}
+	st, ok := info.Sys().(*syscall.Stat_t)
+	return ok && int64(st.Uid) == int64(os.Getuid()) && makeTrashDir(dir) == nil
+}
+
+// makeTrashDir creates dir with its files and info subdirectories.
+func makeTrashDir(dir string) error {
+	for _, sub := range []string{"files", "info"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o700); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mountTop returns the topmost ancestor of abs that is still on device dev.
+func mountTop(abs string, dev uint64) string {
+	top := filepath.Dir(abs)
+	for {
+		parent := filepath.Dir(top)
+		if parent == top {
+			return top
+		}
+		if d, err := deviceOf(parent); err != nil || d != dev {
+			return top
+		}
+		top = parent
+	}
+}
+
+// moveInto writes the .trashinfo record for abs into dir and then renames abs
+// into dir/files, returning the new path. The info file is created
+// exclusively first, which is how the spec reserves a name against concurrent
+// trashers.
+func moveInto(dir, top, abs string) (string, error) {
+	recorded := abs
+	if top != "" {
+		rel, err := filepath.Rel(top, abs)
+		if err != nil {
+			return "", err
+		}
+		recorded = rel
+	}
+	info := "[Trash Info]\nPath=" + (&url.URL{Path: recorded}).EscapedPath() +
+		"\nDeletionDate=" + time.Now().Format("2006-01-02T15:04:05") + "\n"
+
+	base := filepath.Base(abs)
+	for i := 1; ; i++ {
+		name := base
+		if i > 1 {
+			name = base + "." + strconv.Itoa(i)
+		}
+		infoPath := filepath.Join(dir, "info", name+".trashinfo")
+		dest := filepath.Join(dir, "files", name)
+
+		reserved, err := reserve(infoPath, info)
+		if err != nil {
+			return "", err
+		}
+		if !reserved {
+			continue
+		}
+		if _, err := os.Lstat(dest); err == nil {
+			// A stale entry without metadata; leave it alone and try another name.
+			if err := os.Remove(infoPath); err != nil {
+				return "", err
+			}
+			continue
+		}
+		if err := os.Rename(abs, dest); err != nil {
+			return "", errors.Join(err, os.Remove(infoPath))
+		}
+		return dest, nil
+	}
+}
+
+// reserve creates path exclusively with contents. It reports false when the
+// name is already taken.
+func reserve(path, contents string) (bool, error) {
+	// #nosec G304 -- the name is derived from the item being trashed inside the trash directory
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if errors.Is(err, fs.ErrExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if _, err := f.WriteString(contents); err != nil {
+		return false, errors.Join(err, f.Close(), os.Remove(path))
+	}
+	return true, f.Close()
+}
+
+// deviceOf returns the ID of the device holding path, without following a
+// final symlink.
+func deviceOf(path string) (uint64, error) {
+	var st syscall.Stat_t
+	if err := syscall.Lstat(path, &st); err != nil {
+		return 0, &fs.PathError{Op: "lstat", Path: path, Err: err}
+	}
+	return uint64(st.Dev), nil //nolint:unconvert // Dev is narrower on some BSDs
+}
@@ -0,0 +1,88 @@
+//go:build unix && !darwin
+
+package trash
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestTrashFor(t *testing.T) {
+	// volume sets up a home trash and a directory standing in for the top
+	// of another volume, and returns the home trash, the volume and a path
+	// on it. The item is placed on a device number that nothing else has,
+	// so the volume's top is the item's parent.
+	volume := func(t *testing.T) (home, top, item string, dev uint64) {
+		t.Helper()
+		base := t.TempDir()
+		t.Setenv("XDG_DATA_HOME", filepath.Join(base, "data"))
+		top = filepath.Join(base, "volume")
+		if err := os.Mkdir(top, 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		homeDev, err := deviceOf(base)
+		if err != nil {
+			t.Fatalf("device: %v", err)
+		}
+		return filepath.Join(base, "data", "Trash"), top, filepath.Join(top, "item"), homeDev + 1
+	}
+	uid := strconv.Itoa(os.Getuid())
+
+	t.Run("GivenOtherVolume_WhenPicked_ThenItsUserTrashCreated", func(t *testing.T) {
+		_, top, item, dev := volume(t)
+		dir, gotTop, err := trashFor(item, dev)
+		if err != nil {
+			t.Fatalf("trashFor: %v", err)
+		}
+		if want := filepath.Join(top, ".Trash-"+uid); dir != want || gotTop != top {
+			t.Errorf("trashFor() = %q, %q; want %q, %q", dir, gotTop, want, top)
+		}
+		if info, err := os.Stat(filepath.Join(dir, "files")); err != nil || !info.IsDir() {
+			t.Errorf("files subdirectory missing: %v", err)
+		}
+	})
+
+	t.Run("GivenSymlinkedUserTrash_WhenPicked_ThenFallsBackToHomeTrash", func(t *testing.T) {
+		home, top, item, dev := volume(t)
+		elsewhere := t.TempDir()
+		if err := os.Symlink(elsewhere, filepath.Join(top, ".Trash-"+uid)); err != nil {
+			t.Fatalf("symlink: %v", err)
+		}
+		dir, gotTop, err := trashFor(item, dev)
+		if err != nil {
+			t.Fatalf("trashFor: %v", err)
+		}
+		if dir != home || gotTop != "" {
+			t.Errorf("trashFor() = %q, %q; want the home trash %q", dir, gotTop, home)
+		}
+		if entries, err := os.ReadDir(elsewhere); err != nil || len(entries) != 0 {
+			t.Errorf("the symlink target got %d entries (%v), want none", len(entries), err)
+		}
+	})
+
+	t.Run("GivenUserTrashIsAFile_WhenPicked_ThenFallsBackToHomeTrash", func(t *testing.T) {
+		home, top, item, dev := volume(t)
+		if err := os.WriteFile(filepath.Join(top, ".Trash-"+uid), nil, 0o600); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		if dir, _, err := trashFor(item, dev); err != nil || dir != home {
+			t.Errorf("trashFor() = %q, %v; want the home trash %q", dir, err, home)
+		}
+	})
+
+	t.Run("GivenUserTrashOwnedBySomeoneElse_WhenPicked_ThenFallsBackToHomeTrash", func(t *testing.T) {
+		home, top, item, dev := volume(t)
+		planted := filepath.Join(top, ".Trash-"+uid)
+		if err := os.Mkdir(planted, 0o777); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.Chown(planted, os.Getuid()+1, -1); err != nil {
+			t.Skipf("can't hand the directory to another user: %v", err)
+		}
+		if dir, _, err := trashFor(item, dev); err != nil || dir != home {
+			t.Errorf("trashFor() = %q, %v; want the home trash %q", dir, err, home)
+		}
+	})
+}
@@ -0,0 +1,159 @@
+//go:build unix && !darwin
+
+package trash_test
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mobanhawi/aster/internal/trash"
+)
+
+// ── Helpers ──────────────────────────────────────────────────────────────────
+
+// setup points XDG_DATA_HOME at a temp dir and returns the home trash and a
+// directory of files to delete, both on the same filesystem.
+func setup(t *testing.T) (trashDir, work string) {
+	t.Helper()
+	base := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", filepath.Join(base, "data"))
+	work = filepath.Join(base, "work")
+	if err := os.Mkdir(work, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	return filepath.Join(base, "data", "Trash"), work
+}
+
+func writeFile(t *testing.T, path, contents string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	return string(b)
+}
+
+// ── Tests ────────────────────────────────────────────────────────────────────
+
+func TestMove(t *testing.T) {
+	t.Run("GivenFile_WhenMoved_ThenInTrashWithInfo", func(t *testing.T) {
+		trashDir, work := setup(t)
+		src := filepath.Join(work, "old report.txt")
+		writeFile(t, src, "hello")
+
+		dest, err := trash.Move(src)
+		if err != nil {
+			t.Fatalf("Move: %v", err)
+		}
+		if want := filepath.Join(trashDir, "files", "old report.txt"); dest != want {
+			t.Errorf("Move() = %q, want %q", dest, want)
+		}
+		if _, err := os.Lstat(src); !os.IsNotExist(err) {
+			t.Errorf("source still exists: %v", err)
+		}
+		if got := readFile(t, filepath.Join(trashDir, "files", "old report.txt")); got != "hello" {
+			t.Errorf("trashed contents = %q, want hello", got)
+		}
+
+		info := readFile(t, filepath.Join(trashDir, "info", "old report.txt.trashinfo"))
+		wantPath := "Path=" + strings.ReplaceAll(src, " ", "%20") + "\n"
+		if !strings.HasPrefix(info, "[Trash Info]\n") || !strings.Contains(info, wantPath) || !strings.Contains(info, "DeletionDate=") {
+			t.Errorf("trashinfo = %q, want header, %q and a deletion date", info, wantPath)
+		}
+	})
+
+	t.Run("GivenNameAlreadyTrashed_WhenMoved_ThenSuffixAdded", func(t *testing.T) {
+		trashDir, work := setup(t)
+		for _, contents := range []string{"first", "second"} {
+			src := filepath.Join(work, "dup.log")
+			writeFile(t, src, contents)
+			if _, err := trash.Move(src); err != nil {
+				t.Fatalf("Move: %v", err)
+			}
+		}
+		if got := readFile(t, filepath.Join(trashDir, "files", "dup.log.2")); got != "second" {
+			t.Errorf("second copy = %q, want second", got)
+		}
+		if _, err := os.Stat(filepath.Join(trashDir, "info", "dup.log.2.trashinfo")); err != nil {
+			t.Errorf("second trashinfo missing: %v", err)
+		}
+	})
+
+	t.Run("GivenDirectory_WhenMoved_ThenWholeTreeTrashed", func(t *testing.T) {
+		trashDir, work := setup(t)
+		dir := filepath.Join(work, "build")
+		if err := os.MkdirAll(filepath.Join(dir, "obj"), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		writeFile(t, filepath.Join(dir, "obj", "a.o"), "x")
+
+		if _, err := trash.Move(dir); err != nil {
+			t.Fatalf("Move: %v", err)
+		}
+		if got := readFile(t, filepath.Join(trashDir, "files", "build", "obj", "a.o")); got != "x" {
+			t.Errorf("nested file = %q, want x", got)
+		}
+	})
+
+	t.Run("GivenMissingPath_WhenMoved_ThenErrorAndNoInfoLeft", func(t *testing.T) {
+		trashDir, work := setup(t)
+		if _, err := trash.Move(filepath.Join(work, "nope")); err == nil {
+			t.Fatal("expected error")
+		}
+		entries, err := os.ReadDir(filepath.Join(trashDir, "info"))
+		if err == nil && len(entries) > 0 {
+			t.Errorf("stray trashinfo left behind: %v", entries)
+		}
+	})
+}
+
+func TestRestore(t *testing.T) {
+	t.Run("GivenTrashedFile_WhenRestored_ThenBackInPlaceAndInfoRemoved", func(t *testing.T) {
+		trashDir, work := setup(t)
+		src := filepath.Join(work, "notes.md")
+		writeFile(t, src, "keep me")
+		dest, err := trash.Move(src)
+		if err != nil {
+			t.Fatalf("Move: %v", err)
+		}
+
+		if err := trash.Restore(dest, src); err != nil {
+			t.Fatalf("Restore: %v", err)
+		}
+		if got := readFile(t, src); got != "keep me" {
+			t.Errorf("restored contents = %q, want keep me", got)
+		}
+		if _, err := os.Stat(filepath.Join(trashDir, "info", "notes.md.trashinfo")); !os.IsNotExist(err) {
+			t.Errorf("trashinfo still present: %v", err)
+		}
+	})
+
+	t.Run("GivenOriginalRecreated_WhenRestored_ThenRefusesToOverwrite", func(t *testing.T) {
+		_, work := setup(t)
+		src := filepath.Join(work, "notes.md")
+		writeFile(t, src, "old")
+		dest, err := trash.Move(src)
+		if err != nil {
+			t.Fatalf("Move: %v", err)
+		}
+		writeFile(t, src, "new")
+
+		if err := trash.Restore(dest, src); !errors.Is(err, fs.ErrExist) {
+			t.Errorf("Restore() error = %v, want ErrExist", err)
+		}
+		if got := readFile(t, src); got != "new" {
+			t.Errorf("original overwritten: %q", got)
+		}
+	})
+}
@@ -0,0 +1,13 @@
+//go:build !unix
+
+package trash
+
+// Move reports ErrUnsupported; there is no trash this package knows about.
+func Move(string) (string, error) {
+	return "", ErrUnsupported
+}
+
+// Restore reports ErrUnsupported.
+func Restore(string, string) error {
+	return ErrUnsupported
+}
@@ -0,0 +1,80 @@
+// Package treemap lays out weighted items as a squarified treemap (Bruls,
+// Huizing and van Wijk, 2000): a rectangle divided into one rectangle per
+// item, each with an area proportional to its weight and kept as close to a
+// square as the weights allow, so sizes can be compared at a glance.
+package treemap
+
+// Rect is an axis-aligned rectangle with its origin at the top left.
+type Rect struct {
+	X, Y, W, H float64
+}
+
+// Squarify divides r among weights in proportion, returning one rectangle
+// per weight in the same order. Weights must be sorted largest first; from
+// the first weight that is not positive on, the rectangles are left empty.
+//
+// Items are laid out in rows along the shorter side of the space left, each
+// row grown for as long as that improves its worst aspect ratio.
+func Squarify(weights []float64, r Rect) []Rect {
+	out := make([]Rect, len(weights))
+	var total float64
+	for _, w := range weights {
+		if w > 0 {
+			total += w
+		}
+	}
+	if total <= 0 || r.W <= 0 || r.H <= 0 {
+		return out
+	}
+
+	scale := r.W * r.H / total
+	for i := 0; i < len(weights) && weights[i] > 0; {
+		side := min(r.W, r.H)
+		sum := weights[i] * scale
+		j := i + 1
+		for ; j < len(weights) && weights[j] > 0; j++ {
+			next := sum + weights[j]*scale
+			// The row is sorted, so its largest area is the first and its
+			// smallest the last.
+			if worst(weights[i]*scale, weights[j]*scale, next, side) > worst(weights[i]*scale, weights[j-1]*scale, sum, side) {
+				break
+			}
+			sum = next
+		}
+		r = layoutRow(weights[i:j], scale, sum, r, out[i:j])
+		i = j
+	}
+	return out
+}
+
+// worst returns the worst aspect ratio in a row of total area sum, largest
+// area hi and smallest lo, laid along a side of the given length.
+func worst(hi, lo, sum, side float64) float64 {
+	s2, w2 := sum*sum, side*side
+	return max(w2*hi/s2, s2/(w2*lo))
+}
+
+// layoutRow places a row of total area sum along the shorter side of r,
+// writing the items' rectangles to out, and returns the space left.
+func layoutRow(row []float64, scale, sum float64, r Rect, out []Rect) Rect {
+	if r.W >= r.H {
+		// A column at the left edge.
+		thick := sum / r.H
+		y := r.Y
+		for k, w := range row {
+			h := w * scale / thick
+			out[k] = Rect{X: r.X, Y: y, W: thick, H: h}
+			y += h
+		}
+		return Rect{X: r.X + thick, Y: r.Y, W: r.W - thick, H: r.H}
+	}
+	// A row along the top edge.
+	thick := sum / r.W
+	x := r.X
+	for k, w := range row {
+		wd := w * scale / thick
+		out[k] = Rect{X: x, Y: r.Y, W: wd, H: thick}
+		x += wd
+	}
+	return Rect{X: r.X, Y: r.Y + thick, W: r.W, H: r.H - thick}
+}
@@ -0,0 +1,83 @@
+package treemap_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/mobanhawi/aster/internal/treemap"
+)
+
+// ── Helpers ──────────────────────────────────────────────────────────────────
+
+const eps = 1e-9
+
+func near(a, b float64) bool { return math.Abs(a-b) < eps }
+
+// overlap returns the area shared by a and b.
+func overlap(a, b treemap.Rect) float64 {
+	w := min(a.X+a.W, b.X+b.W) - max(a.X, b.X)
+	h := min(a.Y+a.H, b.Y+b.H) - max(a.Y, b.Y)
+	if w <= eps || h <= eps {
+		return 0
+	}
+	return w * h
+}
+
+// ── Tests ────────────────────────────────────────────────────────────────────
+
+func TestSquarify(t *testing.T) {
+	testCases := []struct {
+		name    string
+		weights []float64
+		bounds  treemap.Rect
+	}{
+		{"GivenPaperExample_WhenSquarified_ThenAreasProportional", []float64{6, 6, 4, 3, 2, 2, 1}, treemap.Rect{W: 6, H: 4}},
+		{"GivenSkewedWeights_WhenSquarified_ThenAreasProportional", []float64{1000, 10, 5, 1, 1}, treemap.Rect{X: 3, Y: 2, W: 80, H: 40}},
+		{"GivenEqualWeights_WhenSquarified_ThenAreasProportional", []float64{1, 1, 1, 1, 1, 1, 1, 1, 1}, treemap.Rect{W: 9, H: 9}},
+		{"GivenTrailingZero_WhenSquarified_ThenZeroLeftEmpty", []float64{3, 1, 0}, treemap.Rect{W: 4, H: 1}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := treemap.Squarify(tc.weights, tc.bounds)
+			if len(got) != len(tc.weights) {
+				t.Fatalf("%d rects for %d weights", len(got), len(tc.weights))
+			}
+			var total float64
+			for _, w := range tc.weights {
+				total += w
+			}
+			b := tc.bounds
+			for i, r := range got {
+				if want := tc.weights[i] / total * b.W * b.H; !near(r.W*r.H, want) {
+					t.Errorf("rect %d area = %v, want %v", i, r.W*r.H, want)
+				}
+				if r.X < b.X-eps || r.Y < b.Y-eps || r.X+r.W > b.X+b.W+eps || r.Y+r.H > b.Y+b.H+eps {
+					t.Errorf("rect %d = %+v outside %+v", i, r, b)
+				}
+				for j := range i {
+					if a := overlap(r, got[j]); a > 0 {
+						t.Errorf("rects %d and %d overlap by %v", j, i, a)
+					}
+				}
+			}
+		})
+	}
+
+	t.Run("GivenPaperExample_WhenSquarified_ThenFirstRowIsTwoSquarishHalves", func(t *testing.T) {
+		got := treemap.Squarify([]float64{6, 6, 4, 3, 2, 2, 1}, treemap.Rect{W: 6, H: 4})
+		want := []treemap.Rect{{X: 0, Y: 0, W: 3, H: 2}, {X: 0, Y: 2, W: 3, H: 2}}
+		for i, w := range want {
+			if r := got[i]; !near(r.X, w.X) || !near(r.Y, w.Y) || !near(r.W, w.W) || !near(r.H, w.H) {
+				t.Errorf("rect %d = %+v, want %+v", i, r, w)
+			}
+		}
+	})
+
+	t.Run("GivenNothingToShow_WhenSquarified_ThenAllEmpty", func(t *testing.T) {
+		for _, r := range treemap.Squarify([]float64{0, 0}, treemap.Rect{W: 10, H: 10}) {
+			if r != (treemap.Rect{}) {
+				t.Errorf("rect = %+v, want empty", r)
+			}
+		}
+	})
+}
@@ -0,0 +1,74 @@
+package ui
+
+import (
+	"strconv"
+	"time"
+)
+
+// defaultOlderThan is the age filter's threshold unless --older-than sets
+// another.
+const defaultOlderThan = 180 * 24 * time.Hour
+
+// now is injected for testing.
+var now = time.Now
+
+// stale reports whether nothing in n was modified within m.olderThan.
+// Entries with unknown times are kept, so nothing vanishes unexplained.
+func (m *Model) stale(n *Node) bool {
+	t := n.Newest()
+	return t == 0 || now().Sub(time.Unix(0, t)) > m.olderThan
+}
+
+// filterStale returns the stale entries of children. The result is a new
+// slice; children is the directory's own, which must not be reordered.
+func (m *Model) filterStale(children []*Node) []*Node {
+	kept := make([]*Node, 0, len(children))
+	for _, c := range children {
+		if m.stale(c) {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
+// showAgeColumn reports whether rows carry the age column: when asked for,
+// and whenever age drives the listing.
+func (m *Model) showAgeColumn() bool {
+	return m.showAge || m.sort == SortByAge || m.ageFilter
+}
+
+// handleAgeFilterToggle hides or shows entries modified within m.olderThan.
+func (m *Model) handleAgeFilterToggle() {
+	sel := m.selected()
+	m.ageFilter = !m.ageFilter
+	m.reselect(sel)
+}
+
+// ageCell renders the right-aligned time since n, or its newest entry, was
+// modified.
+func ageCell(n *Node) string {
+	return styleAge.Render(formatAge(n.Newest()))
+}
+
+// formatAge formats the time since t, in Unix nanoseconds, in the largest
+// whole unit: days, months or years. It returns "" when t is unknown.
+func formatAge(t int64) string {
+	if t == 0 {
+		return ""
+	}
+	days := int(now().Sub(time.Unix(0, t)).Hours() / 24)
+	switch {
+	case days < 1:
+		return "today"
+	case days < 60:
+		return strconv.Itoa(days) + "d"
+	case days < 730:
+		return strconv.Itoa(days/30) + "mo"
+	}
+	return strconv.Itoa(days/365) + "y"
+}
+
+// formatDays formats d as whole days, as --older-than takes it.
+func formatDays(d time.Duration) string {
+	return strconv.Itoa(int(d.Hours()/24)) + "d"
+}
@@ -0,0 +1,96 @@
+package ui
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestAgeFilterAndColumn(t *testing.T) {
+	oldNow := now
+	t.Cleanup(func() { now = oldNow })
+	today := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	now = func() time.Time { return today }
+	daysAgo := func(d int) int64 { return today.AddDate(0, 0, -d).UnixNano() }
+
+	newTree := func() *Node {
+		root := nodeWithSize("root", true, 1000,
+			nodeWithSize("archive", true, 600),
+			nodeWithSize("active.log", false, 300),
+			nodeWithSize("unknown.bin", false, 100),
+		)
+		root.Children[0].SetNewest(daysAgo(400))
+		root.Children[1].SetNewest(daysAgo(3))
+		return root
+	}
+	key := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+
+	t.Run("GivenOPressed_WhenRendered_ThenRecentEntriesHiddenAndAgesShown", func(t *testing.T) {
+		next, _ := browsingModel(newTree()).Update(key("O"))
+		m := next.(Model)
+		var names []string
+		for _, c := range m.visibleChildren() {
+			names = append(names, c.Name)
+		}
+		if want := []string{"archive", "unknown.bin"}; !slices.Equal(names, want) {
+			t.Errorf("visible = %v, want %v", names, want)
+		}
+		view := m.View()
+		for _, want := range []string{"older than 180d", "13mo", "2 items"} {
+			if !strings.Contains(view, want) {
+				t.Errorf("view missing %q", want)
+			}
+		}
+		next, _ = m.Update(key("O"))
+		if m = next.(Model); len(m.visibleChildren()) != 3 {
+			t.Errorf("second O should show everything again, got %d items", len(m.visibleChildren()))
+		}
+	})
+
+	t.Run("GivenConfiguredThreshold_WhenStarted_ThenFilterOnWithIt", func(t *testing.T) {
+		m := NewWithConfig("root", Config{Tree: newTree(), OlderThan: 2 * 24 * time.Hour})
+		m.width, m.height = 120, 40
+		if len(m.visibleChildren()) != 3 || !strings.Contains(m.View(), "older than 2d") {
+			t.Errorf("visible %d, want all three older than 2 days", len(m.visibleChildren()))
+		}
+	})
+
+	t.Run("GivenMPressed_WhenRendered_ThenAgeColumnToggles", func(t *testing.T) {
+		m := browsingModel(newTree())
+		if strings.Contains(m.View(), "3d") {
+			t.Fatal("age column should be hidden by default")
+		}
+		next, _ := m.Update(key("m"))
+		if !strings.Contains(next.(Model).View(), "3d") {
+			t.Error("m should show the age column")
+		}
+	})
+}
+
+func TestFormatAge(t *testing.T) {
+	oldNow := now
+	t.Cleanup(func() { now = oldNow })
+	today := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	now = func() time.Time { return today }
+
+	testCases := []struct {
+		ago  time.Duration
+		want string
+	}{
+		{time.Hour, "today"},
+		{3 * 24 * time.Hour, "3d"},
+		{90 * 24 * time.Hour, "3mo"},
+		{800 * 24 * time.Hour, "2y"},
+	}
+	for _, tc := range testCases {
+		if got := formatAge(today.Add(-tc.ago).UnixNano()); got != tc.want {
+			t.Errorf("formatAge(now-%v) = %q, want %q", tc.ago, got, tc.want)
+		}
+	}
+	if got := formatAge(0); got != "" {
+		t.Errorf("formatAge(0) = %q, want empty", got)
+	}
+}
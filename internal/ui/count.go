@@ -0,0 +1,42 @@
+package ui
+
+import "strconv"
+
+// showCountColumn reports whether rows carry the entry count column: when
+// asked for, and whenever counts drive the listing.
+func (m *Model) showCountColumn() bool {
+	return m.showCount || m.sort == SortByCount
+}
+
+// countCell renders the right-aligned number of entries below n; blank for
+// files, which hold none.
+func countCell(n *Node) string {
+	if !n.IsDir {
+		return styleCount.Render("")
+	}
+	return styleCount.Render(formatCount(n.Items()))
+}
+
+// formatCount formats n in at most five characters: exactly below ten
+// thousand, then with a k, M or G suffix.
+func formatCount(n int64) string {
+	switch {
+	case n < 10_000:
+		return strconv.FormatInt(n, 10)
+	case n < 1_000_000:
+		return scaled(n, 1_000) + "k"
+	case n < 1_000_000_000:
+		return scaled(n, 1_000_000) + "M"
+	}
+	return scaled(n, 1_000_000_000) + "G"
+}
+
+// scaled formats n/unit, truncated, with one decimal below 100 and none
+// above.
+func scaled(n, unit int64) string {
+	v := float64(n/(unit/10)) / 10
+	if v < 100 {
+		return strconv.FormatFloat(v, 'f', 1, 64)
+	}
+	return strconv.FormatInt(n/unit, 10)
+}
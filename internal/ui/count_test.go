@@ -0,0 +1,46 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestCountColumn(t *testing.T) {
+	t.Run("GivenCPressed_WhenRendered_ThenDirectoryCountsShown", func(t *testing.T) {
+		root := nodeWithSize("root", true, 1000,
+			nodeWithSize("cache", true, 900),
+			nodeWithSize("notes.txt", false, 100),
+		)
+		root.Children[0].SetCounts(48_211, 1_302)
+		m := browsingModel(root)
+		if strings.Contains(m.View(), "49.5k") {
+			t.Fatal("count column should be hidden by default")
+		}
+		next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("c")})
+		if !strings.Contains(next.(Model).View(), "49.5k") {
+			t.Error("c should show the count column")
+		}
+	})
+}
+
+func TestFormatCount(t *testing.T) {
+	testCases := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0"},
+		{9_999, "9999"},
+		{12_345, "12.3k"},
+		{99_999, "99.9k"},
+		{250_000, "250k"},
+		{2_000_000, "2.0M"},
+		{3_400_000_000, "3.4G"},
+	}
+	for _, tc := range testCases {
+		if got := formatCount(tc.n); got != tc.want {
+			t.Errorf("formatCount(%d) = %q, want %q", tc.n, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package ui
+
+import (
+	humanize "github.com/dustin/go-humanize"
+	"github.com/mobanhawi/aster/internal/diff"
+)
+
+// isRemoved reports whether node only exists in the older side of a comparison.
+func (m Model) isRemoved(node *Node) bool {
+	return m.diff != nil && m.diff.Entry(node).Kind == diff.Removed
+}
+
+// entryDelta returns node's change in the active SizeMode.
+func (m Model) entryDelta(node *Node) int64 {
+	if m.sizeMode == SizeAllocated {
+		return m.diff.Entry(node).UsageDelta()
+	}
+	return m.diff.Entry(node).Delta()
+}
+
+// renderDiffRow renders a row of a comparison: the bar shows the item's share
+// of all change in the directory, coloured by direction, and the last column
+// is the signed delta instead of a percentage.
+func (m Model) renderDiffRow(node *Node, changeTotal int64, barMaxW int, selected bool) string {
+	delta := m.entryDelta(node)
+
+	pct := 0.0
+	if changeTotal > 0 {
+		pct = float64(absInt64(delta)) / float64(changeTotal)
+	}
+	barColor := styleBarDim
+	deltaColor := styleFile
+	switch {
+	case delta > 0:
+		barColor, deltaColor = styleGrow, styleGrow
+	case delta < 0:
+		barColor, deltaColor = styleShrink, styleShrink
+	}
+	bar := renderBar(pct, delta != 0, barMaxW, barColor)
+
+	nameW := m.width - barMaxW - 23 // 23 = size(9) + delta(10) + gaps
+	name := m.nameCell(node, nameW, selected)
+	deltaStr := styleDelta.Inherit(deltaColor).Render(formatDelta(delta))
+
+	row := bar + " " + name + sizeCell(node, m.nodeSize(node)) + deltaStr
+	if selected {
+		return styleSelected.Width(m.width).Render(row)
+	}
+	return row
+}
+
+// diffBarTotal sums the absolute change of children, the denominator for
+// diff bars. Growth and shrinkage in siblings don't cancel out this way.
+func (m Model) diffBarTotal(children []*Node) int64 {
+	var total int64
+	for _, c := range children {
+		total += absInt64(m.entryDelta(c))
+	}
+	return total
+}
+
+// formatDelta renders a signed byte delta such as "+3.2 GB" or "-120 MB".
+func formatDelta(d int64) string {
+	switch {
+	case d > 0:
+		return "+" + humanize.Bytes(uint64(d))
+	case d < 0:
+		return "-" + humanize.Bytes(uint64(-d)) // #nosec G115 -- negated negative is positive
+	}
+	return "0 B"
+}
+
+func absInt64(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,93 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mobanhawi/aster/internal/diff"
+)
+
+func TestDiffMode(t *testing.T) {
+	oldRoot := nodeWithSize("/data", true, 1100,
+		nodeWithSize("logs", true, 100),
+		nodeWithSize("videos", true, 1000),
+	)
+	newRoot := nodeWithSize("/data", true, 4600,
+		nodeWithSize("logs", true, 4000),
+		nodeWithSize("videos", true, 400),
+		nodeWithSize("cache", true, 200),
+	)
+
+	t.Run("GivenComparison_WhenBrowsing_ThenSortedByAbsoluteDelta", func(t *testing.T) {
+		m := NewWithConfig(newRoot.Name, Config{Diff: diff.Compare(oldRoot, newRoot)})
+		m.width, m.height = 120, 40
+
+		want := []string{"logs", "videos", "cache"}
+		for i, c := range m.visibleChildren() {
+			if c.Name != want[i] {
+				t.Errorf("children[%d] = %q, want %q", i, c.Name, want[i])
+			}
+		}
+
+		out := m.View()
+		for _, s := range []string{"+3.9 kB", "-600 B", "+200 B", "change: +3.5 kB"} {
+			if !strings.Contains(out, s) {
+				t.Errorf("expected View() output to contain %q", s)
+			}
+		}
+	})
+
+	t.Run("GivenComparison_WhenAPressed_ThenDeltasFollowDiskUsage", func(t *testing.T) {
+		oldLogs, newLogs := nodeWithSize("logs", true, 100), nodeWithSize("logs", true, 4000)
+		oldLogs.SetUsage(4096)
+		newLogs.SetUsage(12288)
+		m := NewWithConfig("/data", Config{Diff: diff.Compare(
+			nodeWithSize("/data", true, 100, oldLogs), nodeWithSize("/data", true, 4000, newLogs))})
+		m.width, m.height = 120, 40
+		next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("A")})
+		if out := next.(Model).View(); !strings.Contains(out, "+8.2 kB") || strings.Contains(out, "+3.9 kB") {
+			t.Error("in disk usage mode the delta column should show the allocated change")
+		}
+	})
+
+	t.Run("GivenComparison_WhenSortedByName_ThenAlphabetical", func(t *testing.T) {
+		m := NewWithConfig(newRoot.Name, Config{Diff: diff.Compare(oldRoot, newRoot)})
+
+		newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+		got := newModel.(Model)
+
+		if first := got.visibleChildren()[0].Name; first != "cache" {
+			t.Errorf("first child = %q, want %q", first, "cache")
+		}
+	})
+
+	t.Run("GivenComparison_WhenDeleteUndoOrMarkPressed_ThenRefused", func(t *testing.T) {
+		for _, k := range []string{"d", "u", " ", "a"} {
+			m := NewWithConfig(newRoot.Name, Config{Diff: diff.Compare(oldRoot, newRoot)})
+			next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)})
+			got := next.(Model)
+			if got.state != StateBrowsing || got.confirmPath != "" || len(got.marked) != 0 ||
+				!strings.Contains(got.notice, "not available") {
+				t.Errorf("%q: state %v, confirm %q, %d marked, notice %q",
+					k, got.state, got.confirmPath, len(got.marked), got.notice)
+			}
+		}
+	})
+}
+
+func TestFormatDelta(t *testing.T) {
+	testCases := []struct {
+		in   int64
+		want string
+	}{
+		{3_200_000_000, "+3.2 GB"},
+		{-120_000_000, "-120 MB"},
+		{0, "0 B"},
+	}
+	for _, tc := range testCases {
+		if got := formatDelta(tc.in); got != tc.want {
+			t.Errorf("formatDelta(%d) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+package ui
+
+import (
+	"context"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mobanhawi/aster/internal/dupes"
+	"github.com/mobanhawi/aster/internal/format"
+)
+
+// findDupes is injected for testing.
+var findDupes = dupes.Find
+
+// dupesView is the duplicate finder's state while StateDuplicates is shown.
+type dupesView struct {
+	scope  *Node // the directory searched
+	cancel context.CancelFunc
+	hashed *atomic.Int64 // bytes read so far, for the progress line
+	done   bool
+	groups []dupes.Group
+	rows   []dupeRow
+	cursor int // index into rows; always on a file row once done
+	// confirm is set while asking to keep the selected copy.
+	confirm bool
+}
+
+// dupeRow is one line of the listing: a group header or one of its files.
+type dupeRow struct {
+	group  int
+	file   int // -1 for the group's header
+	copies int // for a header, how many copies are still in the tree
+}
+
+// dupesDoneMsg carries the groups found for view.
+type dupesDoneMsg struct {
+	view   *dupesView
+	groups []dupes.Group
+	err    error
+}
+
+// startDupes searches the current directory for duplicate files in the
+// background. Candidates are collected here, on the UI goroutine; hashing
+// then works on paths only, so the tree may keep changing meanwhile.
+func (m *Model) startDupes() tea.Cmd {
+	if m.diff != nil {
+		m.notice = "Duplicate search is not available when comparing scans"
+		return nil
+	}
+	scope := m.currentDir()
+	if scope == nil {
+		return nil
+	}
+	files := dupes.Candidates(scope, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	v := &dupesView{scope: scope, cancel: cancel, hashed: new(atomic.Int64)}
+	m.dupes = v
+	m.state = StateDuplicates
+
+	return tea.Batch(m.sp.Tick, func() tea.Msg {
+		groups, err := findDupes(ctx, files, v.hashed)
+		return dupesDoneMsg{view: v, groups: groups, err: err}
+	})
+}
+
+// finishDupes shows the search result, unless the view was left meanwhile.
+func (m *Model) finishDupes(msg dupesDoneMsg) {
+	v := msg.view
+	v.cancel()
+	if v != m.dupes {
+		return
+	}
+	if msg.err != nil {
+		m.notice = "Duplicate search failed: " + msg.err.Error()
+		m.leaveDupes()
+		return
+	}
+	v.done = true
+	v.groups = msg.groups
+	m.pruneDupes()
+}
+
+// leaveDupes returns to the browser, abandoning any search in flight.
+func (m *Model) leaveDupes() {
+	if m.dupes != nil {
+		m.dupes.cancel()
+		m.dupes = nil
+	}
+	m.state = StateBrowsing
+	m.clampCursor()
+}
+
+// pruneDupes rebuilds the rows from the copies still in the tree. Groups
+// left with a single copy are hidden; an undo may bring them back.
+func (m *Model) pruneDupes() {
+	v := m.dupes
+	v.rows = v.rows[:0]
+	for gi, g := range v.groups {
+		header := len(v.rows)
+		v.rows = append(v.rows, dupeRow{group: gi, file: -1})
+		for fi, f := range g.Files {
+			if attached(m.root, f.Node) {
+				v.rows = append(v.rows, dupeRow{group: gi, file: fi})
+			}
+		}
+		if copies := len(v.rows) - header - 1; copies < 2 {
+			v.rows = v.rows[:header]
+		} else {
+			v.rows[header].copies = copies
+		}
+	}
+	v.cursor = min(v.cursor, len(v.rows)-1)
+	v.moveCursor(0)
+}
+
+// moveCursor moves by delta rows and then off any header, in the direction
+// of travel where possible.
+func (v *dupesView) moveCursor(delta int) {
+	if len(v.rows) == 0 {
+		v.cursor = 0
+		return
+	}
+	v.cursor = min(max(v.cursor+delta, 0), len(v.rows)-1)
+	step := 1
+	if delta < 0 && v.cursor > 0 {
+		step = -1
+	}
+	for v.rows[v.cursor].file < 0 {
+		if next := v.cursor + step; next >= 0 && next < len(v.rows) {
+			v.cursor = next
+		} else {
+			step = -step
+		}
+	}
+}
+
+// selectedDupe returns the group and file under the cursor.
+func (v *dupesView) selectedDupe() (dupes.Group, dupes.File, bool) {
+	if !v.done || v.cursor >= len(v.rows) {
+		return dupes.Group{}, dupes.File{}, false
+	}
+	r := v.rows[v.cursor]
+	if r.file < 0 {
+		return dupes.Group{}, dupes.File{}, false
+	}
+	g := v.groups[r.group]
+	return g, g.Files[r.file], true
+}
+
+// keepSelectedDupe trashes every copy in the selected group except the one
+// under the cursor, as one undoable action.
+func (m *Model) keepSelectedDupe() {
+	g, keep, ok := m.dupes.selectedDupe()
+	if !ok {
+		return
+	}
+	var step []deletion
+	var failures []failure
+	for _, f := range g.Files {
+		if f.Node == keep.Node || !attached(m.root, f.Node) {
+			continue
+		}
+		d, err := m.trashNode(f.Node)
+		if err != nil {
+			failures = append(failures, failure{path: f.Path, err: err})
+			continue
+		}
+		step = append(step, d)
+	}
+	if len(step) > 0 {
+		m.leaveDeletedDirs()
+	}
+
+	m.notice = "Kept " + keep.Node.Name + ", trashed " + strconv.Itoa(len(step)) +
+		" copies (" + format.Bytes(int64(len(step))*g.Size) + ")"
+	m.notice += m.pushUndo(step)
+	m.reportFailures(failures)
+	m.pruneDupes()
+}
+
+func (m Model) handleKeyDupes(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	v := m.dupes
+	key := msg.String()
+	if v.confirm {
+		switch key {
+		case "d", "y", "enter":
+			m.keepSelectedDupe()
+		}
+		v.confirm = false
+		return m, nil
+	}
+
+	switch key {
+	case "ctrl+c", "q":
+		return m, tea.Quit
+	case "esc", "left", "backspace", "h":
+		m.leaveDupes()
+	case "up", "k":
+		v.moveCursor(-1)
+	case "down", "j":
+		v.moveCursor(1)
+	case "g", "home":
+		v.cursor = 0
+		v.moveCursor(0)
+	case "G", "end":
+		v.cursor = len(v.rows) - 1
+		v.moveCursor(0)
+	case "d":
+		if _, _, ok := v.selectedDupe(); ok {
+			v.confirm = true
+		}
+	case "u":
+		m.undoDelete()
+		m.pruneDupes()
+	case "e":
+		m.showFailures()
+	}
+	return m, nil
+}
+
+// viewDupes renders the duplicate groups, biggest saving first, each headed
+// by a bar showing its share of everything reclaimable.
+func (m Model) viewDupes() string {
+	v := m.dupes
+	lv := listView{
+		title:    "duplicates",
+		subtitle: v.scope.FullPath(),
+		total:    len(v.rows),
+		cursor:   v.cursor,
+		hints: keyHint("↑↓/jk", "move") + keyHint("d", "keep this copy, trash the others") +
+			keyHint("u", "undo") + keyHint("esc", "back") + keyHint("q", "quit"),
+	}
+	if !v.done {
+		lv.status = " " + m.sp.View() + " Comparing files… " + format.Bytes(v.hashed.Load()) + " read"
+		lv.hints = keyHint("esc", "cancel") + keyHint("q", "quit")
+		return m.viewList(lv)
+	}
+
+	var groups int
+	var total int64
+	for _, r := range v.rows {
+		if r.file < 0 {
+			groups++
+			total += v.groups[r.group].Size * int64(r.copies-1)
+		}
+	}
+	lv.status = " " + itoa(groups) + " groups  reclaimable: " + format.Bytes(total)
+	if groups == 0 {
+		lv.status = " No duplicates found"
+	}
+	if m.notice != "" {
+		lv.status += "  " + styleNotice.Render(m.notice)
+	}
+	if _, keep, ok := v.selectedDupe(); ok && v.confirm {
+		lv.prompt = "  ⚠  Keep " + truncate(keep.Node.Name, m.width-60) +
+			" and move the other copies to Trash? [d/y/enter = yes  esc/n = no]"
+	}
+
+	root := v.scope.FullPath()
+	lv.row = func(i int) string {
+		r := v.rows[i]
+		g := v.groups[r.group]
+		if r.file < 0 {
+			reclaimable := g.Size * int64(r.copies-1)
+			label := strconv.Itoa(r.copies) + " copies of " + format.Bytes(g.Size)
+			return m.renderTotalRow(label, styleDir, reclaimable, share(reclaimable, total), barStyle(r.group, len(v.groups)), false)
+		}
+		return m.renderDupeFile(g.Files[r.file], root, i == v.cursor)
+	}
+	return m.viewList(lv)
+}
+
+// renderDupeFile renders one copy, indented under its header, by its path
+// relative to the searched directory.
+func (m Model) renderDupeFile(f dupes.File, root string, selected bool) string {
+	label := f.Path
+	if rel, err := filepath.Rel(root, f.Path); err == nil {
+		label = rel
+	}
+	indent := strings.Repeat(" ", m.barWidth()+3)
+	row := styleRow.Render(indent + truncate(label, m.width-len(indent)-1))
+	if selected {
+		return styleSelected.Width(m.width).Render(row)
+	}
+	return row
+}
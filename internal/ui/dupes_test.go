@@ -0,0 +1,170 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mobanhawi/aster/internal/diff"
+	"github.com/mobanhawi/aster/internal/dupes"
+)
+
+func TestDuplicatesView(t *testing.T) {
+	oldFind, oldTrash, oldRestore := findDupes, trashItem, restoreItem
+	t.Cleanup(func() { findDupes, trashItem, restoreItem = oldFind, oldTrash, oldRestore })
+	restoreItem = func(string, string) error { return nil }
+	// Treat files of equal size as identical; the dupes package tests the
+	// hashing.
+	findDupes = func(ctx context.Context, files []dupes.File, _ *atomic.Int64) ([]dupes.Group, error) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		bySize := make(map[int64][]dupes.File)
+		for _, f := range files {
+			bySize[f.Size] = append(bySize[f.Size], f)
+		}
+		var groups []dupes.Group
+		for size, fs := range bySize {
+			groups = append(groups, dupes.Group{Size: size, Files: fs})
+		}
+		slices.SortFunc(groups, func(a, b dupes.Group) int { return int(b.Reclaimable() - a.Reclaimable()) })
+		return groups, nil
+	}
+
+	key := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+	press := func(m Model, keys ...tea.KeyMsg) Model {
+		for _, k := range keys {
+			next, cmd := m.Update(k)
+			m = runBatch(next.(Model), cmd)
+		}
+		return m
+	}
+	newTree := func() *Node {
+		return nodeWithSize("root", true, 1050,
+			nodeWithSize("sub", true, 500,
+				nodeWithSize("video copy.mp4", false, 400),
+				nodeWithSize("notes.txt", false, 100),
+			),
+			nodeWithSize("video.mp4", false, 400),
+			nodeWithSize("notes.txt", false, 100),
+			nodeWithSize("unique.bin", false, 50),
+		)
+	}
+
+	t.Run("GivenDuplicates_WhenDPressed_ThenGroupsListedBiggestSavingFirst", func(t *testing.T) {
+		m := press(browsingModel(newTree()), key("D"))
+		if m.state != StateDuplicates || !m.dupes.done {
+			t.Fatalf("state %v, want finished duplicate search", m.state)
+		}
+		view := m.View()
+		for _, want := range []string{"2 groups  reclaimable: 500 B", "2 copies of 400 B", "sub/video copy.mp4"} {
+			if !strings.Contains(view, want) {
+				t.Errorf("view missing %q", want)
+			}
+		}
+		if strings.Contains(view, "unique.bin") {
+			t.Error("a file without copies should not be listed")
+		}
+		if _, f, ok := m.dupes.selectedDupe(); !ok || f.Size != 400 {
+			t.Errorf("cursor should start on the first copy of the biggest group, got %+v", f)
+		}
+	})
+
+	t.Run("GivenCursor_WhenMoved_ThenHeadersSkipped", func(t *testing.T) {
+		m := press(browsingModel(newTree()), key("D"), key("j"), key("j"))
+		if r := m.dupes.rows[m.dupes.cursor]; r.file < 0 || r.group != 1 {
+			t.Errorf("cursor on row %+v, want the first file of the second group", r)
+		}
+		m = press(m, key("k"))
+		if r := m.dupes.rows[m.dupes.cursor]; r.file != 1 || r.group != 0 {
+			t.Errorf("cursor on row %+v, want the last file of the first group", r)
+		}
+		m = press(m, key("G"))
+		if m.dupes.cursor != len(m.dupes.rows)-1 {
+			t.Errorf("cursor = %d, want the last row", m.dupes.cursor)
+		}
+		m = press(m, key("g"))
+		if m.dupes.cursor != 1 {
+			t.Errorf("cursor = %d, want 1", m.dupes.cursor)
+		}
+	})
+
+	t.Run("GivenCopySelected_WhenKept_ThenOthersTrashedAsOneUndoableAction", func(t *testing.T) {
+		var trashed []string
+		trashItem = func(path string) (string, error) {
+			trashed = append(trashed, path)
+			return "/trash/" + path, nil
+		}
+		root := newTree()
+		m := press(browsingModel(root), key("D"), key("d"))
+		if !strings.Contains(m.View(), "and move the other copies to Trash?") {
+			t.Fatal("d should ask for confirmation")
+		}
+		_, keep, _ := m.dupes.selectedDupe()
+		m = press(m, key("y"))
+
+		if len(trashed) != 1 || trashed[0] == keep.Path || root.Size() != 650 || len(m.undo) != 1 {
+			t.Errorf("trashed %v keeping %s, root %d, undo %d", trashed, keep.Path, root.Size(), len(m.undo))
+		}
+		if strings.Contains(m.View(), "copies of 400 B") || !strings.Contains(m.notice, "trashed 1 copies (400 B)") {
+			t.Errorf("resolved group still listed, notice %q", m.notice)
+		}
+
+		m = press(m, key("u"))
+		if root.Size() != 1050 || !strings.Contains(m.View(), "2 copies of 400 B") {
+			t.Errorf("after undo: root %d, group listed %v", root.Size(), strings.Contains(m.View(), "2 copies of 400 B"))
+		}
+	})
+
+	t.Run("GivenCopyFailing_WhenKept_ThenFailureListedFromTheDupes", func(t *testing.T) {
+		trashItem = func(string) (string, error) { return "", errors.New("read-only file system") }
+		m := press(browsingModel(newTree()), key("D"), key("d"), key("y"))
+		if !strings.Contains(m.notice, "1 failed: ") || !strings.Contains(m.notice, "read-only file system") {
+			t.Errorf("notice = %q", m.notice)
+		}
+		m = press(m, key("e"))
+		if m.state != StateFailures || !strings.Contains(m.View(), "read-only file system") {
+			t.Fatalf("state %v, want the failure listed", m.state)
+		}
+		if m = press(m, key("e")); m.state != StateDuplicates {
+			t.Errorf("state %v, want back to the duplicates", m.state)
+		}
+	})
+
+	t.Run("GivenConfirm_WhenDeclined_ThenNothingTrashed", func(t *testing.T) {
+		trashItem = func(path string) (string, error) {
+			t.Errorf("trashed %s", path)
+			return "", nil
+		}
+		m := press(browsingModel(newTree()), key("D"), key("d"), tea.KeyMsg{Type: tea.KeyEsc})
+		if m.state != StateDuplicates || m.dupes.confirm {
+			t.Errorf("state %v, confirm %v; want the list back", m.state, m.dupes.confirm)
+		}
+	})
+
+	t.Run("GivenSearchRunning_WhenLeft_ThenCancelledAndResultIgnored", func(t *testing.T) {
+		next, cmd := browsingModel(newTree()).Update(key("D"))
+		m := next.(Model)
+		if !strings.Contains(m.View(), "Comparing files") {
+			t.Error("view should show the search in progress")
+		}
+		m = press(m, tea.KeyMsg{Type: tea.KeyEsc})
+		m = runBatch(m, cmd)
+		if m.state != StateBrowsing || m.dupes != nil || m.notice != "" {
+			t.Errorf("state %v, notice %q; want browsing, result dropped", m.state, m.notice)
+		}
+	})
+
+	t.Run("GivenDiffMode_WhenDPressed_ThenRefused", func(t *testing.T) {
+		m := browsingModel(newTree())
+		m.diff = &diff.Result{}
+		m = press(m, key("D"))
+		if m.state != StateBrowsing || !strings.Contains(m.notice, "not available") {
+			t.Errorf("state %v, notice %q", m.state, m.notice)
+		}
+	})
+}
@@ -0,0 +1,92 @@
+package ui
+
+import (
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// failure is an item a batch delete couldn't move to the trash.
+type failure struct {
+	path string
+	err  error
+}
+
+// failuresView lists the failures of the last batch delete in
+// StateFailures; the status line only has room for the first.
+type failuresView struct {
+	items  []failure
+	cursor int
+	back   AppState // the screen the list was opened from
+}
+
+// reportFailures appends the number of failures and the first of them to the
+// notice and keeps them all for the failures screen. A batch without
+// failures forgets those of the previous one.
+func (m *Model) reportFailures(items []failure) {
+	m.failures = nil
+	if len(items) == 0 {
+		return
+	}
+	m.failures = &failuresView{items: items}
+	first := items[0]
+	m.notice += "; " + itoa(len(items)) + " failed: " + filepath.Base(first.path) + ": " + first.err.Error()
+	if len(items) > 1 {
+		m.notice += " (e lists all)"
+	}
+}
+
+// showFailures opens the failures of the last batch delete, if any.
+func (m *Model) showFailures() {
+	if m.failures == nil {
+		return
+	}
+	m.failures.cursor = 0
+	m.failures.back = m.state
+	m.state = StateFailures
+}
+
+func (m Model) handleKeyFailures(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	v := m.failures
+	switch msg.String() {
+	case "ctrl+c", "q":
+		return m, tea.Quit
+	case "up", "k":
+		v.cursor = max(v.cursor-1, 0)
+	case "down", "j":
+		v.cursor = min(v.cursor+1, len(v.items)-1)
+	case "g", "home":
+		v.cursor = 0
+	case "G", "end":
+		v.cursor = len(v.items) - 1
+	case "esc", "e", "left", "backspace", "h":
+		m.state = v.back
+	}
+	return m, nil
+}
+
+// viewFailures lists every item the last batch delete left behind, with the
+// reason.
+func (m Model) viewFailures() string {
+	v := m.failures
+	return m.viewList(listView{
+		title:    "failed to trash",
+		subtitle: "Left where they were",
+		total:    len(v.items),
+		cursor:   v.cursor,
+		row: func(i int) string {
+			f := v.items[i]
+			// The path gives way to the reason down to half the width.
+			reason := f.err.Error()
+			path := truncate(f.path, max(m.width-3-len([]rune(reason)), m.width/2))
+			reason = truncate(reason, m.width-3-len([]rune(path)))
+			row := " " + styleRow.Render(path) + "  " + styleError.Render(reason)
+			if i == v.cursor {
+				return styleSelected.Width(m.width).Render(row)
+			}
+			return row
+		},
+		status: " " + itoa(len(v.items)) + " failed",
+		hints:  keyHint("↑↓/jk", "move") + keyHint("esc/e", "back") + keyHint("q", "quit"),
+	})
+}
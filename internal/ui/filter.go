@@ -0,0 +1,152 @@
+package ui
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// newFilterInput returns the text input behind the / key.
+func newFilterInput() textinput.Model {
+	ti := textinput.New()
+	ti.Prompt = " / "
+	ti.Placeholder = "filter by name"
+	ti.PromptStyle = styleMatch
+	return ti
+}
+
+// query is the active name filter, empty when there is none.
+func (m *Model) query() string {
+	return m.filter.Value()
+}
+
+// startFilter opens the filter input, keeping the previous query so it can
+// be refined.
+func (m *Model) startFilter() tea.Cmd {
+	m.filtering = true
+	m.filter.CursorEnd()
+	return m.filter.Focus()
+}
+
+// filterByName returns the entries of children whose names match the query.
+// The result is a new slice; children is the directory's own, which must not
+// be reordered.
+func (m *Model) filterByName(children []*Node) []*Node {
+	q := m.query()
+	kept := make([]*Node, 0, len(children))
+	for _, c := range children {
+		if matchName(c.Name, q) != nil {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
+// handleKeyFilter edits the query while the filter input is open. Enter
+// closes it keeping the query for n/N; esc drops the query. Either way the
+// cursor stays on the entry it was on.
+func (m Model) handleKeyFilter(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "up":
+		m.cursor = max(m.cursor-1, 0)
+		return m, nil
+	case "down":
+		m.cursor = max(min(m.cursor+1, len(m.visibleChildren())-1), 0)
+		return m, nil
+	case "enter", "esc":
+		sel := m.selected()
+		if msg.String() == "esc" {
+			m.filter.Reset()
+		}
+		m.filtering = false
+		m.filter.Blur()
+		m.reselect(sel)
+		return m, nil
+	}
+
+	prev := m.query()
+	var cmd tea.Cmd
+	m.filter, cmd = m.filter.Update(msg)
+	if m.query() != prev {
+		m.cursor = 0
+	}
+	return m, cmd
+}
+
+// jumpToMatch moves the cursor to the next entry matching the query, or the
+// previous one when delta is -1, wrapping around the listing.
+func (m *Model) jumpToMatch(delta int) {
+	q := m.query()
+	if q == "" {
+		return
+	}
+	children := m.visibleChildren()
+	for i := 1; i <= len(children); i++ {
+		j := ((m.cursor+delta*i)%len(children) + len(children)) % len(children)
+		if matchName(children[j].Name, q) != nil {
+			m.cursor = j
+			return
+		}
+	}
+	m.notice = "No match for " + q
+}
+
+// matchName reports where query matches name, ignoring case, as the rune
+// indices of name to highlight: a substring if there is one, else the
+// characters of query in order, as close to the start as possible. It
+// returns nil when query does not match, and an empty slice for an empty
+// query.
+func matchName(name, query string) []int {
+	if query == "" {
+		return []int{}
+	}
+	n, q := foldRunes(name), foldRunes(query)
+	if i := strings.Index(string(n), string(q)); i >= 0 {
+		start := len([]rune(string(n)[:i]))
+		pos := make([]int, len(q))
+		for k := range pos {
+			pos[k] = start + k
+		}
+		return pos
+	}
+	pos := make([]int, 0, len(q))
+	for i, r := range n {
+		if len(pos) < len(q) && r == q[len(pos)] {
+			pos = append(pos, i)
+		}
+	}
+	if len(pos) < len(q) {
+		return nil
+	}
+	return pos
+}
+
+// foldRunes lower-cases s rune by rune, so indices into the result are
+// indices into s.
+func foldRunes(s string) []rune {
+	r := []rune(s)
+	for i := range r {
+		r[i] = unicode.ToLower(r[i])
+	}
+	return r
+}
+
+// highlight renders text in style with the runes at pos, in increasing order
+// and all within text, in styleMatch.
+func highlight(text string, pos []int, style lipgloss.Style) string {
+	var sb strings.Builder
+	runes := []rune(text)
+	start := 0
+	for _, p := range pos {
+		sb.WriteString(style.Render(string(runes[start:p])))
+		sb.WriteString(styleMatch.Inherit(style).Render(string(runes[p])))
+		start = p + 1
+	}
+	sb.WriteString(style.Render(string(runes[start:])))
+	return sb.String()
+}
@@ -0,0 +1,116 @@
+package ui
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestNameFilter(t *testing.T) {
+	newTree := func() *Node {
+		return nodeWithSize("root", true, 1000,
+			nodeWithSize("Documents", true, 400),
+			nodeWithSize("build", true, 300),
+			nodeWithSize("readme.md", false, 200),
+			nodeWithSize("photos", true, 100),
+		)
+	}
+	typeKeys := func(m Model, keys ...string) Model {
+		for _, k := range keys {
+			msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+			switch k {
+			case "enter":
+				msg = tea.KeyMsg{Type: tea.KeyEnter}
+			case "esc":
+				msg = tea.KeyMsg{Type: tea.KeyEsc}
+			}
+			next, _ := m.Update(msg)
+			m = next.(Model)
+		}
+		return m
+	}
+	names := func(m Model) []string {
+		var got []string
+		for _, c := range m.visibleChildren() {
+			got = append(got, c.Name)
+		}
+		return got
+	}
+
+	t.Run("GivenQuery_WhenTyped_ThenListingNarrowedLive", func(t *testing.T) {
+		m := typeKeys(browsingModel(newTree()), "/", "o", "s")
+		if !m.filtering {
+			t.Fatal("/ should open the filter input")
+		}
+		// "os" is a substring of photos and, spread out, of Documents.
+		if want := []string{"Documents", "photos"}; !slices.Equal(names(m), want) {
+			t.Errorf("visible = %v, want %v", names(m), want)
+		}
+		if !strings.Contains(m.View(), "/ os") {
+			t.Error("view should show the query being typed")
+		}
+	})
+
+	t.Run("GivenFilter_WhenEnterPressed_ThenFullListingKeptOnSelection", func(t *testing.T) {
+		m := typeKeys(browsingModel(newTree()), "/", "p", "h", "enter")
+		if m.filtering || len(m.visibleChildren()) != 4 {
+			t.Fatalf("filtering %v with %d visible, want closed showing all", m.filtering, len(m.visibleChildren()))
+		}
+		if sel := m.selected(); sel == nil || sel.Name != "photos" {
+			t.Errorf("selected = %v, want photos", sel)
+		}
+		if !strings.Contains(m.View(), "/ph (n/N)") {
+			t.Error("status bar should keep the query for n/N")
+		}
+	})
+
+	t.Run("GivenClosedFilter_WhenNAndShiftNPressed_ThenMatchesCycled", func(t *testing.T) {
+		m := typeKeys(browsingModel(newTree()), "/", "d", "enter")
+		// Matches of "d": Documents (0), build (1), readme.md (2).
+		for _, step := range []struct {
+			key  string
+			want string
+		}{{"n", "build"}, {"n", "readme.md"}, {"n", "Documents"}, {"N", "readme.md"}} {
+			m = typeKeys(m, step.key)
+			if sel := m.selected(); sel == nil || sel.Name != step.want {
+				t.Fatalf("after %s selected = %v, want %s", step.key, sel, step.want)
+			}
+		}
+	})
+
+	t.Run("GivenFilter_WhenEscPressed_ThenQueryDropped", func(t *testing.T) {
+		m := typeKeys(browsingModel(newTree()), "/", "x", "y", "z")
+		if len(m.visibleChildren()) != 0 {
+			t.Fatalf("visible = %v, want none for xyz", names(m))
+		}
+		m = typeKeys(m, "esc")
+		if m.filtering || m.query() != "" || len(m.visibleChildren()) != 4 {
+			t.Errorf("filtering %v query %q visible %d, want everything back", m.filtering, m.query(), len(m.visibleChildren()))
+		}
+		m = typeKeys(m, "n")
+		if m.cursor != 0 || m.notice != "" {
+			t.Errorf("n without a query should do nothing, cursor %d notice %q", m.cursor, m.notice)
+		}
+	})
+}
+
+func TestMatchName(t *testing.T) {
+	testCases := []struct {
+		name  string
+		query string
+		want  []int
+	}{
+		{"Makefile", "FILE", []int{4, 5, 6, 7}},
+		{"node_modules", "nm", []int{0, 5}},
+		{"Ärger.txt", "ä", []int{0}},
+		{"photos", "xyz", nil},
+		{"photos", "", []int{}},
+	}
+	for _, tc := range testCases {
+		if got := matchName(tc.name, tc.query); !slices.Equal(got, tc.want) || (got == nil) != (tc.want == nil) {
+			t.Errorf("matchName(%q, %q) = %v, want %v", tc.name, tc.query, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package ui
+
+import tea "github.com/charmbracelet/bubbletea"
+
+// keyHelp describes one of the browser's keys.
+type keyHelp struct {
+	key, desc string
+}
+
+// footerKeys are the keys the browser's footer has room for; the rest are
+// listed by the ? help screen.
+var footerKeys = []keyHelp{
+	{"↑↓/jk", "move"},
+	{"→/enter", "open"},
+	{"←/bsp", "back"},
+	{"d", "delete"},
+	{"?", "help"},
+	{"q", "quit"},
+}
+
+// browseKeys are all of the browser's keys, as the help screen lists them.
+var browseKeys = []keyHelp{
+	{"↑↓/jk", "move the cursor"},
+	{"→/enter/l", "enter the directory"},
+	{"←/bsp/h", "go back up"},
+	{"g/G", "jump to the top / bottom"},
+	{"/", "filter the directory by name"},
+	{"n/N", "next / previous match of the filter"},
+	{"f", "find entries anywhere in the tree"},
+	{"s", "cycle sort: size, name, age, count"},
+	{"A", "toggle apparent size / disk usage"},
+	{"m", "show modification ages"},
+	{"O", "show only items not modified lately"},
+	{"c", "show entry counts"},
+	{"p", "show the preview pane"},
+	{"o", "open in the default app"},
+	{"r", "reveal in the file manager"},
+	{"space", "mark / unmark and move down"},
+	{"a", "mark / unmark everything here"},
+	{"esc", "clear the filter, or else the marks"},
+	{"d", "move to Trash (the marked items, if any)"},
+	{"u", "undo the last delete"},
+	{"e", "list what the last delete couldn't trash"},
+	{"R", "rescan the directory"},
+	{"t", "break down by file type"},
+	{"F", "list the largest files"},
+	{"D", "find duplicate files"},
+	{"T", "show as a treemap"},
+	{"?", "show this help"},
+	{"q", "quit"},
+}
+
+// keyWidth is the width of the key column on the help screen.
+const keyWidth = 12
+
+func (m Model) handleKeyHelp(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c", "q":
+		return m, tea.Quit
+	case "up", "k":
+		m.helpCursor = max(m.helpCursor-1, 0)
+	case "down", "j":
+		m.helpCursor = min(m.helpCursor+1, len(browseKeys)-1)
+	case "g", "home":
+		m.helpCursor = 0
+	case "G", "end":
+		m.helpCursor = len(browseKeys) - 1
+	case "esc", "?", "left", "backspace", "h":
+		m.state = StateBrowsing
+	}
+	return m, nil
+}
+
+// viewHelp lists the browser's keys.
+func (m Model) viewHelp() string {
+	return m.viewList(listView{
+		title:    "keys",
+		subtitle: "Everything the browser responds to",
+		total:    len(browseKeys),
+		cursor:   m.helpCursor,
+		row: func(i int) string {
+			k := browseKeys[i]
+			row := " " + styleKey.Render(padRight(k.key, keyWidth)) + styleRow.Render(truncate(k.desc, m.width-keyWidth-2))
+			if i == m.helpCursor {
+				return styleSelected.Width(m.width).Render(row)
+			}
+			return row
+		},
+		status: " " + itoa(len(browseKeys)) + " keys",
+		hints:  keyHint("↑↓/jk", "move") + keyHint("esc/?", "back") + keyHint("q", "quit"),
+	})
+}
@@ -0,0 +1,72 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestFrameFitsTerminal(t *testing.T) {
+	key := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+	newModel := func() Model {
+		root := nodeWithSize("/a/rather/long/path/to/the/volume/being/scanned/for/large/files", true, 1000,
+			nodeWithSize("dir", true, 600, nodeWithSize("inner.bin", false, 600)),
+			nodeWithSize("file.txt", false, 400),
+		)
+		m := browsingModel(root)
+		m.width, m.height = 80, 24
+		m.notice = strings.Repeat("a notice far too long for one line ", 5)
+		return m
+	}
+
+	testCases := []struct {
+		name string
+		keys []string
+	}{
+		{"GivenBrowser_WhenRendered_ThenFitsTerminal", nil},
+		{"GivenPreviewPane_WhenRendered_ThenFitsTerminal", []string{"p"}},
+		{"GivenDeletePrompt_WhenRendered_ThenFitsTerminal", []string{"d"}},
+		{"GivenHelp_WhenRendered_ThenFitsTerminal", []string{"?"}},
+		{"GivenTypes_WhenRendered_ThenFitsTerminal", []string{"t"}},
+		{"GivenTreemap_WhenRendered_ThenFitsTerminal", []string{"T"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := newModel()
+			for _, k := range tc.keys {
+				next, cmd := m.Update(key(k))
+				m = runBatch(next.(Model), cmd)
+			}
+			view := m.View()
+			if h := lipgloss.Height(view); h > m.height {
+				t.Fatalf("view is %d lines, want at most %d:\n%s", h, m.height, view)
+			}
+			for i, l := range strings.Split(view, "\n") {
+				if w := lipgloss.Width(l); w > m.width {
+					t.Errorf("line %d is %d cells wide, want at most %d", i, w, m.width)
+				}
+			}
+		})
+	}
+
+}
+
+func TestHelp(t *testing.T) {
+	key := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+	m := browsingModel(nodeWithSize("root", true, 0))
+	next, _ := m.Update(key("?"))
+	m = next.(Model)
+	if m.state != StateHelp || !strings.Contains(m.View(), "find duplicate files") {
+		t.Fatalf("state %v, want the help screen listing every key", m.state)
+	}
+	next, _ = m.Update(key("G"))
+	if m = next.(Model); m.helpCursor != len(browseKeys)-1 {
+		t.Errorf("helpCursor = %d, want the last key", m.helpCursor)
+	}
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m = next.(Model); m.state != StateBrowsing {
+		t.Errorf("state %v after esc, want browsing", m.state)
+	}
+}
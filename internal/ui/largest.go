@@ -0,0 +1,154 @@
+package ui
+
+import (
+	"cmp"
+	"path/filepath"
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mobanhawi/aster/internal/format"
+)
+
+// largestCount is how many files the largest-files view ranks.
+const largestCount = 200
+
+// largestView is the flat ranking of files shown in StateLargest.
+type largestView struct {
+	scope  *Node // the directory ranked; the root or the browsed directory
+	dir    *Node // the browsed directory when the view was opened
+	files  []*Node
+	cursor int
+}
+
+// startLargest ranks the largest files below the current directory.
+func (m *Model) startLargest() {
+	dir := m.currentDir()
+	if dir == nil {
+		return
+	}
+	m.largest = &largestView{dir: dir}
+	m.rankLargest(dir)
+	m.state = StateLargest
+}
+
+// rankLargest (re)ranks the files below scope in the active size mode.
+func (m *Model) rankLargest(scope *Node) {
+	v := m.largest
+	v.scope = scope
+	v.files = largestFiles(scope, largestCount, m.nodeSize)
+	v.cursor = 0
+}
+
+// largestFiles returns the limit largest files below root by size, largest
+// first, ties broken by path. The ranking is kept sorted while the tree is
+// walked, so only files that make the cut are ever moved.
+func largestFiles(root *Node, limit int, size func(*Node) int64) []*Node {
+	if limit <= 0 {
+		return nil
+	}
+	rank := func(a, b *Node) int {
+		if c := cmp.Compare(size(b), size(a)); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.FullPath(), b.FullPath())
+	}
+	var top []*Node
+	var walk func(n *Node)
+	walk = func(n *Node) {
+		for _, c := range n.Children {
+			switch {
+			case c.IsDir:
+				walk(c)
+			case len(top) < limit || rank(c, top[len(top)-1]) < 0:
+				i, _ := slices.BinarySearchFunc(top, c, rank)
+				top = slices.Insert(top, i, c)
+				top = top[:min(len(top), limit)]
+			}
+		}
+	}
+	walk(root)
+	return top
+}
+
+// jumpToLargest shows the selected file in the browser.
+func (m *Model) jumpToLargest() {
+	v := m.largest
+	if len(v.files) == 0 {
+		return
+	}
+	n := v.files[v.cursor]
+	if !m.jumpTo(n) {
+		m.notice = n.Name + " is no longer in the tree"
+		m.rankLargest(v.scope)
+		return
+	}
+	m.largest = nil
+}
+
+func (m Model) handleKeyLargest(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	v := m.largest
+	switch msg.String() {
+	case "ctrl+c", "q":
+		return m, tea.Quit
+	case "up", "k":
+		v.cursor = max(v.cursor-1, 0)
+	case "down", "j":
+		v.cursor = max(min(v.cursor+1, len(v.files)-1), 0)
+	case "g", "home":
+		v.cursor = 0
+	case "G", "end":
+		v.cursor = max(len(v.files)-1, 0)
+	case "right", "enter", "l":
+		m.jumpToLargest()
+	case "tab":
+		if v.scope == m.root {
+			m.rankLargest(v.dir)
+		} else {
+			m.rankLargest(m.root)
+		}
+	case "esc", "left", "backspace", "h":
+		m.largest = nil
+		m.state = StateBrowsing
+	}
+	return m, nil
+}
+
+// viewLargest renders the ranking, each file with its share of the scope.
+func (m Model) viewLargest() string {
+	v := m.largest
+	root := v.scope.FullPath()
+	total := m.nodeSize(v.scope)
+	var listed int64
+	for _, n := range v.files {
+		listed += m.nodeSize(n)
+	}
+
+	hints := keyHint("↑↓/jk", "move") + keyHint("→/enter", "show in tree")
+	switch {
+	case v.scope != m.root:
+		hints += keyHint("tab", "whole tree")
+	case v.dir != m.root:
+		hints += keyHint("tab", v.dir.Name)
+	}
+	lv := listView{
+		title:    "largest files",
+		subtitle: root,
+		total:    len(v.files),
+		cursor:   v.cursor,
+		row: func(i int) string {
+			n := v.files[i]
+			label := n.FullPath()
+			if rel, err := filepath.Rel(root, label); err == nil {
+				label = rel
+			}
+			sz := m.nodeSize(n)
+			return m.renderTotalRow(label, styleRow, sz, share(sz, total), barStyle(i, len(v.files)), i == v.cursor)
+		},
+		status: " " + itoa(len(v.files)) + " files  " + format.Bytes(listed) + " of " + format.Bytes(total),
+		hints:  hints + keyHint("esc", "back") + keyHint("q", "quit"),
+	}
+	if m.notice != "" {
+		lv.status += "  " + styleNotice.Render(m.notice)
+	}
+	return m.viewList(lv)
+}
@@ -0,0 +1,105 @@
+package ui
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestLargestView(t *testing.T) {
+	key := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+	press := func(m Model, keys ...tea.KeyMsg) Model {
+		for _, k := range keys {
+			next, _ := m.Update(k)
+			m = next.(Model)
+		}
+		return m
+	}
+	newTree := func() *Node {
+		return nodeWithSize("root", true, 1000,
+			nodeWithSize("a", true, 750,
+				nodeWithSize("b", true, 700,
+					nodeWithSize("small.log", false, 100),
+					nodeWithSize("huge.iso", false, 600),
+				),
+				nodeWithSize("mid.zip", false, 50),
+			),
+			nodeWithSize("top.txt", false, 250),
+		)
+	}
+	names := func(nodes []*Node) []string {
+		var out []string
+		for _, n := range nodes {
+			out = append(out, n.Name)
+		}
+		return out
+	}
+
+	t.Run("GivenNestedFiles_WhenFPressed_ThenRankedFlatLargestFirst", func(t *testing.T) {
+		m := press(browsingModel(newTree()), key("F"))
+		if m.state != StateLargest {
+			t.Fatalf("state = %v, want StateLargest", m.state)
+		}
+		if got, want := names(m.largest.files), []string{"huge.iso", "top.txt", "small.log", "mid.zip"}; !slices.Equal(got, want) {
+			t.Errorf("files = %v, want %v", got, want)
+		}
+		if !strings.Contains(m.View(), "a/b/huge.iso") {
+			t.Error("rows should show the path below the scope")
+		}
+	})
+
+	t.Run("GivenEqualSizesPastTheLimit_WhenRanked_ThenEarlierPathsKept", func(t *testing.T) {
+		// z.bin is reached first, but a.bin and b.bin sort ahead of it.
+		root := nodeWithSize("root", true, 300,
+			nodeWithSize("z", true, 100, nodeWithSize("z.bin", false, 100)),
+			nodeWithSize("b.bin", false, 100),
+			nodeWithSize("a.bin", false, 100),
+		)
+		size := func(n *Node) int64 { return n.Size() }
+		if got := names(largestFiles(root, 2, size)); !slices.Equal(got, []string{"a.bin", "b.bin"}) {
+			t.Errorf("files = %v, want [a.bin b.bin]", got)
+		}
+	})
+
+	t.Run("GivenFileSelected_WhenEnterPressed_ThenBrowserShowsItInItsDirectory", func(t *testing.T) {
+		root := newTree()
+		m := press(browsingModel(root), key("F"), key("j"), key("j"), tea.KeyMsg{Type: tea.KeyEnter})
+		if m.state != StateBrowsing || m.largest != nil {
+			t.Fatalf("state = %v, want browsing", m.state)
+		}
+		if got := names(m.stack); !slices.Equal(got, []string{"a", "b"}) {
+			t.Errorf("stack = %v, want [a b]", got)
+		}
+		if sel := m.selected(); sel == nil || sel.Name != "small.log" {
+			t.Errorf("selected %v, want small.log", sel)
+		}
+	})
+
+	t.Run("GivenSubdirectory_WhenTabPressed_ThenScopeTogglesToWholeTree", func(t *testing.T) {
+		m := press(browsingModel(newTree()), key("l"), key("l"), key("F"))
+		if got := names(m.largest.files); !slices.Equal(got, []string{"huge.iso", "small.log"}) {
+			t.Errorf("files = %v, want only b's", got)
+		}
+		m = press(m, tea.KeyMsg{Type: tea.KeyTab})
+		if m.largest.scope != m.root || len(m.largest.files) != 4 {
+			t.Errorf("scope %s with %d files, want the root with 4", m.largest.scope.Name, len(m.largest.files))
+		}
+		m = press(m, tea.KeyMsg{Type: tea.KeyTab}, tea.KeyMsg{Type: tea.KeyEsc})
+		if m.state != StateBrowsing || m.currentDir().Name != "b" {
+			t.Errorf("esc should return to b, state %v", m.state)
+		}
+	})
+
+	t.Run("GivenFileGone_WhenEnterPressed_ThenNoticeAndRanked", func(t *testing.T) {
+		root := newTree()
+		m := press(browsingModel(root), key("F"))
+		b := root.Children[0].Children[0]
+		b.RemoveChild(b.Children[1])
+		m = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+		if m.state != StateLargest || !strings.Contains(m.notice, "huge.iso is no longer in the tree") || len(m.largest.files) != 3 {
+			t.Errorf("state %v, notice %q, %d files", m.state, m.notice, len(m.largest.files))
+		}
+	})
+}
@@ -0,0 +1,171 @@
+package ui
+
+import (
+	"cmp"
+	"path/filepath"
+	"slices"
+	"strconv"
+
+	"github.com/mobanhawi/aster/internal/format"
+)
+
+// toggleMark marks or unmarks the selected row and moves to the next one, so
+// holding space sweeps down a listing.
+func (m *Model) toggleMark() {
+	if m.diff != nil {
+		m.notice = "Marking is not available when comparing scans"
+		return
+	}
+	sel := m.selected()
+	if sel == nil {
+		return
+	}
+	if _, ok := m.marked[sel]; ok {
+		delete(m.marked, sel)
+	} else {
+		m.mark(sel)
+	}
+	if m.cursor < len(m.visibleChildren())-1 {
+		m.cursor++
+	}
+}
+
+// markAllVisible marks every row of the current directory, or unmarks them
+// all when they are already marked.
+func (m *Model) markAllVisible() {
+	if m.diff != nil {
+		m.notice = "Marking is not available when comparing scans"
+		return
+	}
+	children := m.visibleChildren()
+	all := true
+	for _, c := range children {
+		if _, ok := m.marked[c]; !ok {
+			all = false
+			break
+		}
+	}
+	for _, c := range children {
+		if all {
+			delete(m.marked, c)
+		} else {
+			m.mark(c)
+		}
+	}
+}
+
+func (m *Model) mark(n *Node) {
+	if m.marked == nil {
+		m.marked = make(map[*Node]struct{})
+	}
+	m.marked[n] = struct{}{}
+}
+
+// isMarked reports whether n is marked.
+func (m Model) isMarked(n *Node) bool {
+	_, ok := m.marked[n]
+	return ok
+}
+
+// unmarkUnder drops marks on n and anything below it, which no longer belong
+// to the tree once n is deleted.
+func (m *Model) unmarkUnder(n *Node) {
+	for k := range m.marked {
+		for a := k; a != nil; a = a.Parent {
+			if a == n {
+				delete(m.marked, k)
+				break
+			}
+		}
+	}
+}
+
+// dropDetachedMarks forgets marks on nodes that a rescan or a filesystem
+// event removed from the tree.
+func (m *Model) dropDetachedMarks() {
+	for k := range m.marked {
+		if !attached(m.root, k) {
+			delete(m.marked, k)
+		}
+	}
+}
+
+// markedRoots returns the marked nodes that have no marked ancestor, ordered
+// by path. Deleting a marked directory already takes its marked descendants
+// with it, so they must be neither trashed twice nor counted twice.
+func (m Model) markedRoots() []*Node {
+	roots := make([]*Node, 0, len(m.marked))
+	for n := range m.marked {
+		covered := false
+		for a := n.Parent; a != nil; a = a.Parent {
+			if _, ok := m.marked[a]; ok {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			roots = append(roots, n)
+		}
+	}
+	slices.SortFunc(roots, func(a, b *Node) int {
+		return cmp.Compare(a.FullPath(), b.FullPath())
+	})
+	return roots
+}
+
+// markedTotal sums the size of everything marked in the active SizeMode.
+func (m Model) markedTotal() int64 {
+	var total int64
+	for _, n := range m.markedRoots() {
+		total += m.nodeSize(n)
+	}
+	return total
+}
+
+// deleteMarked trashes every marked item as a single undoable action. Items
+// that fail stay marked and are kept for the failures screen.
+func (m *Model) deleteMarked() {
+	var step []deletion
+	var failures []failure
+	var size int64
+	for _, n := range m.markedRoots() {
+		d, err := m.trashNode(n)
+		if err != nil {
+			failures = append(failures, failure{path: n.FullPath(), err: err})
+			continue
+		}
+		step = append(step, d)
+		size += d.size
+	}
+	if len(step) > 0 {
+		m.leaveDeletedDirs()
+	}
+
+	m.notice = "Trashed " + strconv.Itoa(len(step)) + " items (" + format.Bytes(size) + ")"
+	m.notice += m.pushUndo(step)
+	m.reportFailures(failures)
+	m.clampCursor()
+}
+
+// leaveDeletedDirs pops the breadcrumb stack back to the deepest directory
+// still attached to the tree; a marked ancestor of the current directory
+// may just have been trashed.
+func (m *Model) leaveDeletedDirs() {
+	parent := m.root
+	for i, n := range m.stack {
+		if !slices.Contains(parent.Children, n) {
+			m.stack = m.stack[:i]
+			return
+		}
+		parent = n
+	}
+}
+
+// batchPrompt describes the marked items for the confirm overlay.
+func (m Model) batchPrompt() string {
+	roots := m.markedRoots()
+	if len(roots) == 1 {
+		return filepath.Base(roots[0].FullPath()) + " (" + format.Bytes(m.markedTotal()) + ")"
+	}
+	return strconv.Itoa(len(roots)) + " marked items (" + format.Bytes(m.markedTotal()) + ")"
+}
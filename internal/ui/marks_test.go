@@ -0,0 +1,157 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestMarksAndBatchDelete(t *testing.T) {
+	oldTrash, oldRestore := trashItem, restoreItem
+	t.Cleanup(func() { trashItem, restoreItem = oldTrash, oldRestore })
+	restoreItem = func(string, string) error { return nil }
+
+	press := func(m Model, keys ...tea.KeyMsg) Model {
+		for _, k := range keys {
+			next, _ := m.Update(k)
+			m = next.(Model)
+		}
+		return m
+	}
+	r := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+	space := tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")}
+	newTree := func() *Node {
+		return nodeWithSize("root", true, 1000,
+			nodeWithSize("build", true, 600,
+				nodeWithSize("out.bin", false, 400),
+				nodeWithSize("obj", true, 200, nodeWithSize("a.o", false, 200)),
+			),
+			nodeWithSize("cache", true, 300, nodeWithSize("blob", false, 300)),
+			nodeWithSize("keep.txt", false, 100),
+		)
+	}
+
+	t.Run("GivenSpace_WhenPressed_ThenRowMarkedAndCursorAdvances", func(t *testing.T) {
+		m := press(browsingModel(newTree()), space)
+		if len(m.marked) != 1 || m.cursor != 1 || !strings.Contains(m.View(), "marked: 1 (600 B)") {
+			t.Errorf("marked %d, cursor %d", len(m.marked), m.cursor)
+		}
+		m = press(m, r("k"), space)
+		if len(m.marked) != 0 {
+			t.Errorf("second space should unmark, got %d marks", len(m.marked))
+		}
+	})
+
+	t.Run("GivenA_WhenPressedTwice_ThenAllMarkedThenCleared", func(t *testing.T) {
+		m := press(browsingModel(newTree()), r("a"))
+		if len(m.marked) != 3 || m.markedTotal() != 1000 {
+			t.Errorf("marked %d totalling %d, want 3 totalling 1000", len(m.marked), m.markedTotal())
+		}
+		m = press(m, r("a"))
+		if len(m.marked) != 0 {
+			t.Errorf("marked %d, want 0", len(m.marked))
+		}
+		m = press(m, r("a"), tea.KeyMsg{Type: tea.KeyEsc})
+		if len(m.marked) != 0 {
+			t.Errorf("esc left %d marks, want 0", len(m.marked))
+		}
+	})
+
+	t.Run("GivenMarksInSeveralSubtrees_WhenConfirmed_ThenAllTrashedAndAncestorsUpdated", func(t *testing.T) {
+		var trashed []string
+		trashItem = func(path string) (string, error) {
+			trashed = append(trashed, path)
+			return "/trash/" + path, nil
+		}
+		root := newTree()
+		// Mark build/obj inside build, then cache (where the cursor is left
+		// after going back) from the root: marks survive navigation.
+		m := press(browsingModel(root), r("l"), r("j"), space, r("h"), space)
+		if len(m.marked) != 2 || m.markedTotal() != 500 {
+			t.Fatalf("marked %d totalling %d, want 2 totalling 500", len(m.marked), m.markedTotal())
+		}
+		m = press(m, r("d"))
+		if m.state != StateConfirmDelete || !strings.Contains(m.View(), "2 marked items (500 B)") {
+			t.Fatalf("state %v, want batch confirm prompt", m.state)
+		}
+		m = press(m, r("y"))
+
+		if want := []string{"root/build/obj", "root/cache"}; strings.Join(trashed, ",") != strings.Join(want, ",") {
+			t.Errorf("trashed %v, want %v", trashed, want)
+		}
+		if root.Size() != 500 || root.Children[0].Size() != 400 || len(m.marked) != 0 || len(m.undo) != 1 {
+			t.Errorf("root %d, build %d, marks %d, undo %d", root.Size(), root.Children[0].Size(), len(m.marked), len(m.undo))
+		}
+
+		m = press(m, r("u"))
+		if root.Size() != 1000 || root.Children[0].Size() != 600 || !strings.Contains(m.notice, "Restored 2 items") {
+			t.Errorf("after undo: root %d, build %d, notice %q", root.Size(), root.Children[0].Size(), m.notice)
+		}
+	})
+
+	t.Run("GivenMarkedDirAndDescendant_WhenConfirmed_ThenTrashedOnceAndCountedOnce", func(t *testing.T) {
+		var trashed []string
+		trashItem = func(path string) (string, error) {
+			trashed = append(trashed, path)
+			return "/trash/" + path, nil
+		}
+		root := newTree()
+		m := press(browsingModel(root), r("l"), space, r("h"), r("g"), space)
+		if m.markedTotal() != 600 {
+			t.Errorf("markedTotal = %d, want 600 (nested mark not double counted)", m.markedTotal())
+		}
+		m = press(m, r("d"), r("y"))
+		if len(trashed) != 1 || trashed[0] != "root/build" || root.Size() != 400 {
+			t.Errorf("trashed %v, root %d", trashed, root.Size())
+		}
+	})
+
+	t.Run("GivenSomeFailures_WhenConfirmed_ThenReportedAndLeftMarked", func(t *testing.T) {
+		trashItem = func(path string) (string, error) {
+			if strings.HasSuffix(path, "cache") {
+				return "", errors.New("busy")
+			}
+			return "/trash/" + path, nil
+		}
+		root := newTree()
+		m := press(browsingModel(root), r("a"), r("d"), r("y"))
+		if !strings.Contains(m.notice, "Trashed 2 items") || !strings.Contains(m.notice, "1 failed: cache: busy") {
+			t.Errorf("notice = %q", m.notice)
+		}
+		if len(m.marked) != 1 || root.Size() != 300 || len(root.Children) != 1 {
+			t.Errorf("marks %d, root %d, children %d", len(m.marked), root.Size(), len(root.Children))
+		}
+	})
+
+	t.Run("GivenSeveralFailures_WhenConfirmed_ThenFirstShownAndAllListed", func(t *testing.T) {
+		trashItem = func(path string) (string, error) {
+			if strings.HasSuffix(path, "keep.txt") {
+				return "/trash/" + path, nil
+			}
+			return "", errors.New("busy: " + path)
+		}
+		m := press(browsingModel(newTree()), r("a"), r("d"), r("y"))
+		if !strings.Contains(m.notice, "2 failed: build: busy: root/build (e lists all)") || strings.Contains(m.notice, "cache") {
+			t.Errorf("notice = %q, want the count and only the first failure", m.notice)
+		}
+		m = press(m, r("e"))
+		view := m.View()
+		if m.state != StateFailures || !strings.Contains(view, "busy: root/build") || !strings.Contains(view, "busy: root/cache") {
+			t.Fatalf("state %v, want both failures listed:\n%s", m.state, view)
+		}
+		if m = press(m, tea.KeyMsg{Type: tea.KeyEsc}); m.state != StateBrowsing {
+			t.Errorf("state %v, want back to browsing", m.state)
+		}
+	})
+
+	t.Run("GivenCurrentDirMarkedFromAbove_WhenDeletedFromInside_ThenNavigatesUp", func(t *testing.T) {
+		trashItem = func(path string) (string, error) { return "/trash/" + path, nil }
+		root := newTree()
+		m := press(browsingModel(root), space, r("k"), r("l"), r("d"), r("y"))
+		if len(m.stack) != 0 || m.currentDir() != root {
+			t.Errorf("stack depth %d, want back at root", len(m.stack))
+		}
+	})
+}
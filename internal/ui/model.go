@@ -1,13 +1,18 @@
 package ui
 
 import (
+	"cmp"
 	"context"
+	"slices"
 	"strings"
 	"sync/atomic"
+	"time"
 
 	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	humanize "github.com/dustin/go-humanize"
+	"github.com/mobanhawi/aster/internal/diff"
 	"github.com/mobanhawi/aster/internal/scanner"
 )
 
@@ -19,11 +24,26 @@ const (
 	SortBySize SortMode = iota
 	// SortByName sorts items alphabetically.
 	SortByName
+	// SortByAge sorts items least recently modified first.
+	SortByAge
+	// SortByCount sorts items by the number of entries below them, most
+	// first.
+	SortByCount
+)
+
+// SizeMode selects which byte count drives bars, percentages and totals.
+type SizeMode int
+
+const (
+	// SizeApparent uses the logical file length (what ls reports).
+	SizeApparent SizeMode = iota
+	// SizeAllocated uses the blocks allocated on disk (what du reports).
+	SizeAllocated
 )
 
 // sortModeToInt8 converts a SortMode to the int8 stored in Node.SortedMode.
 func sortModeToInt8(m SortMode) int8 {
-	return int8(m) // #nosec G115 -- SortMode values are small iota constants (0, 1)
+	return int8(m) // #nosec G115 -- SortMode values are small iota constants
 }
 
 // scanDoneMsg is sent when scanning completes.
@@ -47,6 +67,20 @@ const (
 	StateConfirmDelete
 	// StateError displays any unrecoverable errors.
 	StateError
+	// StateDuplicates lists groups of identical files.
+	StateDuplicates
+	// StateTypes totals the current directory by file type.
+	StateTypes
+	// StateLargest ranks the largest files of a subtree.
+	StateLargest
+	// StateSearch finds entries anywhere in the tree by name.
+	StateSearch
+	// StateTreemap maps the current directory as nested rectangles.
+	StateTreemap
+	// StateHelp lists the browser's keys.
+	StateHelp
+	// StateFailures lists the items the last batch delete couldn't trash.
+	StateFailures
 )
 
 // Model is the Bubble Tea application model.
@@ -57,15 +91,42 @@ type Model struct {
 	cursor int
 	sort   SortMode
 
+	// sizeMode picks apparent vs allocated bytes for display and size sorting.
+	sizeMode SizeMode
+
+	// showAge adds the age column to every listing.
+	showAge bool
+	// showCount adds the entry count column to every listing.
+	showCount bool
+	// showPreview shows the preview pane beside the list.
+	showPreview bool
+	// preview is what the preview pane shows for the selected entry.
+	preview *previewPane
+	// top is the browser's first row shown while pinned: the mouse pins the
+	// rows so they stay under the pointer, and keys let them follow the
+	// cursor again.
+	top    int
+	pinned bool
+	// lastClick is the last click on a row, for spotting double-clicks.
+	lastClick click
+	// ageFilter hides entries modified within olderThan.
+	ageFilter bool
+	olderThan time.Duration
+
 	// sortGen is incremented each time the sort mode changes so that nodes
 	// detect staleness in O(1) instead of walking the entire tree.
 	sortGen uint64
 
 	// Scan state
 	state    AppState
+	scanOpts scanner.Options
 	rootPath string
 	absRoot  string // resolved once — avoids filepath.Abs on every View()
 	scanErr  error
+	takenAt  time.Time // non-zero when browsing a snapshot
+
+	// diff is non-nil when browsing a comparison of two scans.
+	diff *diff.Result
 
 	// UI dimensions
 	width  int
@@ -73,10 +134,53 @@ type Model struct {
 
 	// Widgets
 	sp spinner.Model
+	// filter holds the name filter typed after /; filtering is set while it
+	// has focus and the listing is narrowed to matches. Once closed, the
+	// query stays for n/N and highlighting.
+	filter    textinput.Model
+	filtering bool
 
 	// Confirm-delete state
 	confirmPath string
 
+	// marked holds rows selected for a batch delete; marks survive navigation.
+	marked map[*Node]struct{}
+	// rescans holds directories with a background rescan in flight.
+	rescans map[*Node]*rescanJob
+	// undo holds delete actions, most recent last, for the u key. A batch
+	// delete is one action.
+	undo [][]deletion
+	// notice describes the last action in the status bar.
+	notice string
+
+	// watchMode keeps the tree current from filesystem events (--watch).
+	watchMode bool
+	// watcher delivers those events once every directory is watched; nil
+	// before then and after falling back to polling.
+	watcher watcher
+	// polling is set when watching failed and the tree is rescanned every
+	// pollInterval instead.
+	polling bool
+	// levelReads holds directories with a one-level re-read in flight.
+	levelReads map[*Node]*levelRead
+
+	// dupes is the duplicate finder shown in StateDuplicates.
+	dupes *dupesView
+	// types is the file type breakdown shown in StateTypes.
+	types *typesView
+	// largest is the largest-files ranking shown in StateLargest.
+	largest *largestView
+	// search is the tree-wide name search shown in StateSearch; it outlives
+	// a jump to one of its results.
+	search *searchView
+	// treemap is the treemap of the current directory shown in StateTreemap.
+	treemap *treemapView
+	// helpCursor is the selected row of the help screen.
+	helpCursor int
+	// failures holds what the last batch delete couldn't trash, shown in
+	// StateFailures; nil when it all went.
+	failures *failuresView
+
 	// Live scan progress (updated from progressCh via atomic).
 	scannedBytes *atomic.Int64 // pointer so Model copies share the counter
 	progressCh   chan int64
@@ -96,30 +200,78 @@ type Model struct {
 	cachedStatusHuman string
 }
 
+// Config holds the start-up settings for a Model.
+type Config struct {
+	// Scan is passed through to scanner.ScanWithOptions.
+	Scan scanner.Options
+
+	// Tree, when set, is browsed directly instead of scanning rootPath.
+	Tree *scanner.Node
+	// TakenAt is when Tree was scanned; shown in the status bar if non-zero.
+	TakenAt time.Time
+
+	// Diff, when set, browses the union tree of a comparison between two
+	// scans, ordered and annotated by change. It takes precedence over Tree.
+	Diff *diff.Result
+
+	// Watch keeps a fresh scan current as files change, by watching every
+	// directory or, where that fails, by rescanning periodically. It has no
+	// effect on a Tree or Diff.
+	Watch bool
+
+	// OlderThan, when positive, starts with only the entries not modified
+	// within it shown. It also sets the age filter's threshold, which is
+	// otherwise 180 days.
+	OlderThan time.Duration
+}
+
 // New constructs a fresh model targeting the given root path.
 func New(rootPath string) Model {
+	return NewWithConfig(rootPath, Config{})
+}
+
+// NewWithConfig constructs a fresh model targeting rootPath, configured by cfg.
+func NewWithConfig(rootPath string, cfg Config) Model {
 	sp := spinner.New()
 	sp.Spinner = spinner.Dot
 	sp.Style = styleScanning
 
 	var scanned atomic.Int64
-	return Model{
+	m := Model{
 		rootPath:     rootPath,
+		scanOpts:     cfg.Scan,
 		absRoot:      rootPath, // refined in startScan after Abs resolves
 		state:        StateScanning,
 		sp:           sp,
+		filter:       newFilterInput(),
 		scannedBytes: &scanned,
 		sortGen:      1, // start at 1 so zero-value nodes are always stale
+		takenAt:      cfg.TakenAt,
+		diff:         cfg.Diff,
+		watchMode:    cfg.Watch && cfg.Tree == nil && cfg.Diff == nil,
+		ageFilter:    cfg.OlderThan > 0,
+		olderThan:    cmp.Or(cfg.OlderThan, defaultOlderThan),
+	}
+	if cfg.Diff != nil {
+		cfg.Tree = cfg.Diff.Root
+	}
+	if cfg.Tree != nil {
+		m.sortChildren(cfg.Tree)
+		m.browse(cfg.Tree)
 	}
+	return m
 }
 
 // Init implements tea.Model.
 func (m Model) Init() tea.Cmd {
+	if m.state != StateScanning {
+		return nil // opened on a pre-built tree; nothing to scan
+	}
 	// Create a buffered progress channel; the scanner will send byte counts.
 	m.progressCh = make(chan int64, 4096)
 	return tea.Batch(
 		m.sp.Tick,
-		startScan(m.rootPath, m.progressCh, m.scannedBytes),
+		startScan(m.rootPath, m.progressCh, m.scannedBytes, m.scanOpts),
 		fetchPurgeable(m.rootPath),
 	)
 }
@@ -146,7 +298,7 @@ func fetchPurgeable(path string) tea.Cmd {
 
 // startScan launches the concurrent scanner in a goroutine that also drains
 // progressCh into scanned (atomic) so the view can display live byte counts.
-func startScan(root string, progressCh chan int64, scanned *atomic.Int64) tea.Cmd {
+func startScan(root string, progressCh chan int64, scanned *atomic.Int64, opts scanner.Options) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
 
@@ -159,7 +311,7 @@ func startScan(root string, progressCh chan int64, scanned *atomic.Int64) tea.Cm
 			}
 		}()
 
-		node, err := scanner.Scan(ctx, root, progressCh)
+		node, err := scanner.ScanWithOptions(ctx, root, progressCh, opts)
 		close(progressCh)
 		<-drainDone // wait for all progress bytes to land
 
@@ -170,7 +322,7 @@ func startScan(root string, progressCh chan int64, scanned *atomic.Int64) tea.Cm
 		// Sort only the root level eagerly; all other dirs sort lazily on
 		// first navigation. This avoids a multi-second O(N log N) pause for
 		// large trees before the UI becomes interactive.
-		sortNode(node, SortBySize)
+		sortNode(node, SortBySize, SizeApparent)
 
 		return scanDoneMsg{root: node}
 	}
@@ -179,15 +331,23 @@ func startScan(root string, progressCh chan int64, scanned *atomic.Int64) tea.Cm
 // sortNode sorts a single node's children (not recursive).
 // The sortGen/SortedMode fields are NOT updated here — the caller (visibleChildren)
 // stamps the generation after sorting to keep the contract simple.
-func sortNode(n *Node, mode SortMode) {
+func sortNode(n *Node, mode SortMode, sizeMode SizeMode) {
 	if n == nil {
 		return
 	}
 	switch mode {
 	case SortBySize:
-		n.SortBySize()
+		if sizeMode == SizeAllocated {
+			n.SortByUsage()
+		} else {
+			n.SortBySize()
+		}
 	case SortByName:
 		n.SortByName()
+	case SortByAge:
+		n.SortByAge()
+	case SortByCount:
+		n.SortByCount()
 	}
 }
 
@@ -209,10 +369,56 @@ func (m *Model) visibleChildren() []*Node {
 	}
 	modeInt := sortModeToInt8(m.sort)
 	if !d.IsSorted(m.sortGen, modeInt) {
-		sortNode(d, m.sort)
+		m.sortChildren(d)
 		d.MarkSorted(m.sortGen, modeInt)
 	}
-	return d.Children
+	return m.filterChildren(d.Children)
+}
+
+// filterChildren applies the active age and name filters to children, a
+// directory's own entries.
+func (m *Model) filterChildren(children []*Node) []*Node {
+	if m.ageFilter {
+		children = m.filterStale(children)
+	}
+	if m.filtering {
+		children = m.filterByName(children)
+	}
+	return children
+}
+
+// activeFilters returns the name query and the age threshold that hide
+// entries, each zero while its filter is off.
+func (m *Model) activeFilters() (query string, olderThan time.Duration) {
+	if m.filtering {
+		query = m.query()
+	}
+	if m.ageFilter {
+		olderThan = m.olderThan
+	}
+	return query, olderThan
+}
+
+// sortChildren sorts d's children for the current mode. In a comparison the
+// size ordering ranks by absolute change instead of by size.
+func (m *Model) sortChildren(d *Node) {
+	if m.diff != nil && m.sort == SortBySize {
+		if m.sizeMode == SizeAllocated {
+			m.diff.SortByUsageDelta(d)
+		} else {
+			m.diff.SortByDelta(d)
+		}
+		return
+	}
+	sortNode(d, m.sort, m.sizeMode)
+}
+
+// nodeSize returns n's size in the active SizeMode.
+func (m *Model) nodeSize(n *Node) int64 {
+	if m.sizeMode == SizeAllocated {
+		return n.Usage()
+	}
+	return n.Size()
 }
 
 // clampCursor ensures the cursor is within bounds.
@@ -239,6 +445,23 @@ func (m *Model) selected() *Node {
 	return children[m.cursor]
 }
 
+// jumpTo browses n's directory with the cursor on n. It reports false, and
+// leaves the view alone, when n is no longer in the tree.
+func (m *Model) jumpTo(n *Node) bool {
+	if n == m.root || !attached(m.root, n) {
+		return false
+	}
+	var stack []*Node
+	for d := n.Parent; d != m.root; d = d.Parent {
+		stack = append(stack, d)
+	}
+	slices.Reverse(stack)
+	m.stack = stack
+	m.state = StateBrowsing
+	m.reselect(n)
+	return true
+}
+
 // divider returns a cached "─" × m.width string, refreshing only when width
 // changes to avoid a strings.Repeat allocation on every frame.
 func (m *Model) divider() string {
@@ -249,28 +472,25 @@ func (m *Model) divider() string {
 	return m.cachedDivider
 }
 
-// keyHints returns the cached footer key-hint string, rebuilding only when
+// keyHints returns the cached footer key-hint line, rebuilding only when
 // the terminal width changes (which is rare).
 func (m *Model) keyHints() string {
 	if m.cachedHintsWidth != m.width {
-		k := func(key, desc string) string {
-			return styleKey.Render(key) + " " + desc + "  "
+		raw := " "
+		for _, k := range footerKeys {
+			raw += keyHint(k.key, k.desc)
 		}
-		raw := " " +
-			k("↑↓/jk", "move") +
-			k("→/enter", "enter") +
-			k("←/bsp", "back") +
-			k("o", "open") +
-			k("r", "reveal") +
-			k("d", "delete") +
-			k("s", "sort") +
-			k("q", "quit")
-		m.cachedHints = styleFooter.Width(m.width).Render(raw)
+		m.cachedHints = m.line(styleFooter, raw)
 		m.cachedHintsWidth = m.width
 	}
 	return m.cachedHints
 }
 
+// keyHint renders one entry of a key-hint footer.
+func keyHint(key, desc string) string {
+	return styleKey.Render(key) + " " + desc + "  "
+}
+
 // humanSize returns a cached humanize.Bytes string for sz, refreshing only
 // when sz changes. This avoids the humanize allocation on every render frame
 // for the status-bar total-size display.
@@ -293,6 +513,21 @@ func (m *Model) humanSize(sz int64) string {
 // discarded after. It is NOT a package-level cache because bar rendering
 // depends on both rank and width, which can change across frames.
 
+// browse switches to StateBrowsing at the top of a freshly built tree whose
+// root level has already been sorted by size.
+func (m *Model) browse(root *Node) {
+	m.root = root
+	m.state = StateBrowsing
+	m.cursor = 0
+	m.stack = nil
+	// Cache the resolved absolute path so breadcrumb() avoids calling
+	// filepath.Abs on every render frame.
+	if root != nil {
+		m.absRoot = root.Name
+		m.markRootSorted()
+	}
+}
+
 // Sorted flag for root after init.
 func (m *Model) markRootSorted() {
 	if m.root != nil {
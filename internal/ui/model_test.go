@@ -1,8 +1,11 @@
 package ui
 
 import (
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
@@ -27,6 +30,26 @@ func browsingModel(root *Node) Model {
 	return m
 }
 
+// runBatch executes cmd and feeds every message except spinner ticks back
+// into the model, following the commands each update returns.
+func runBatch(m Model, cmd tea.Cmd) Model {
+	if cmd == nil {
+		return m
+	}
+	msg := cmd()
+	if batch, ok := msg.(tea.BatchMsg); ok {
+		for _, c := range batch {
+			m = runBatch(m, c)
+		}
+		return m
+	}
+	if _, isTick := msg.(spinner.TickMsg); isTick {
+		return m
+	}
+	next, cmd := m.Update(msg)
+	return runBatch(next.(Model), cmd)
+}
+
 // ── Navigation tests ─────────────────────────────────────────────────────────
 
 func TestNavigation(t *testing.T) {
@@ -126,7 +149,10 @@ func TestSortToggle(t *testing.T) {
 	root := nodeWithSize("root", true, 1600,
 		nodeWithSize("zebra.txt", false, 100),
 		nodeWithSize("apple.txt", false, 500),
+		nodeWithSize("node_modules", true, 50),
 	)
+	root.Children[0].SetNewest(1) // zebra.txt is the older
+	root.Children[2].SetCounts(2000, 100)
 
 	t.Run("GivenSortBySize_WhenSToggled_ThenSwitchesToSortByName", func(t *testing.T) {
 		m := browsingModel(root)
@@ -147,13 +173,54 @@ func TestSortToggle(t *testing.T) {
 		}
 	})
 
-	t.Run("GivenSortByName_WhenSToggled_ThenSwitchesToSortBySize", func(t *testing.T) {
+	t.Run("GivenSortByName_WhenSToggled_ThenSwitchesToSortByAge", func(t *testing.T) {
 		m := browsingModel(root)
 		m.sort = SortByName
 
 		newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
 		got := newModel.(Model)
 
+		if got.sort != SortByAge {
+			t.Errorf("sort = %v, want SortByAge", got.sort)
+		}
+		children := got.visibleChildren()
+		if len(children) > 0 && children[0].Name != "zebra.txt" {
+			t.Errorf("first child = %q, want the older %q", children[0].Name, "zebra.txt")
+		}
+		if !strings.Contains(got.View(), "sort: age") {
+			t.Error("status bar should show the age sort")
+		}
+	})
+
+	t.Run("GivenSortByAge_WhenSToggled_ThenSwitchesToSortByCount", func(t *testing.T) {
+		m := browsingModel(root)
+		m.sort = SortByAge
+
+		newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+		got := newModel.(Model)
+
+		if got.sort != SortByCount {
+			t.Errorf("sort = %v, want SortByCount", got.sort)
+		}
+		children := got.visibleChildren()
+		if len(children) > 0 && children[0].Name != "node_modules" {
+			t.Errorf("first child = %q, want the crowded %q", children[0].Name, "node_modules")
+		}
+		view := got.View()
+		for _, want := range []string{"sort: count", "2100"} {
+			if !strings.Contains(view, want) {
+				t.Errorf("view missing %q", want)
+			}
+		}
+	})
+
+	t.Run("GivenSortByCount_WhenSToggled_ThenSwitchesToSortBySize", func(t *testing.T) {
+		m := browsingModel(root)
+		m.sort = SortByCount
+
+		newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+		got := newModel.(Model)
+
 		if got.sort != SortBySize {
 			t.Errorf("sort = %v, want SortBySize", got.sort)
 		}
@@ -165,6 +232,54 @@ func TestSortToggle(t *testing.T) {
 	})
 }
 
+func TestSizeModeToggle(t *testing.T) {
+	// A fresh tree per subtest: sort stamps on nodes outlive a single Model.
+	newRoot := func() *Node {
+		sparse := nodeWithSize("sparse.img", false, 1000)
+		sparse.SetUsage(10)
+		dense := nodeWithSize("dense.bin", false, 500)
+		dense.SetUsage(500)
+		root := nodeWithSize("root", true, 1500, sparse, dense)
+		root.SetUsage(510)
+		return root
+	}
+
+	t.Run("GivenApparentMode_WhenAToggled_ThenSortsByAllocatedUsage", func(t *testing.T) {
+		root := newRoot()
+		m := browsingModel(root)
+
+		newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("A")})
+		got := newModel.(Model)
+
+		if got.sizeMode != SizeAllocated {
+			t.Fatalf("sizeMode = %v, want SizeAllocated", got.sizeMode)
+		}
+		children := got.visibleChildren()
+		if len(children) > 0 && children[0].Name != "dense.bin" {
+			t.Errorf("first child = %q, want %q", children[0].Name, "dense.bin")
+		}
+		if sz := got.nodeSize(root); sz != 510 {
+			t.Errorf("nodeSize(root) = %d, want 510", sz)
+		}
+	})
+
+	t.Run("GivenAllocatedMode_WhenAToggled_ThenReturnsToApparent", func(t *testing.T) {
+		m := browsingModel(newRoot())
+		m.sizeMode = SizeAllocated
+
+		newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("A")})
+		got := newModel.(Model)
+
+		if got.sizeMode != SizeApparent {
+			t.Errorf("sizeMode = %v, want SizeApparent", got.sizeMode)
+		}
+		children := got.visibleChildren()
+		if len(children) > 0 && children[0].Name != "sparse.img" {
+			t.Errorf("first child = %q, want %q", children[0].Name, "sparse.img")
+		}
+	})
+}
+
 // ── Delete confirm flow tests ─────────────────────────────────────────────────
 
 func TestDeleteConfirmFlow(t *testing.T) {
@@ -398,6 +513,32 @@ func TestScanDoneTransition(t *testing.T) {
 	})
 }
 
+func TestNewWithPreloadedTree(t *testing.T) {
+	t.Run("GivenTree_WhenConstructed_ThenOpensStraightIntoBrowsing", func(t *testing.T) {
+		root := nodeWithSize("/data", true, 600,
+			nodeWithSize("small", false, 100),
+			nodeWithSize("big", false, 500),
+		)
+		takenAt := time.Date(2026, 10, 1, 9, 30, 0, 0, time.UTC)
+
+		m := NewWithConfig(root.Name, Config{Tree: root, TakenAt: takenAt})
+
+		if m.state != StateBrowsing {
+			t.Errorf("state = %v, want StateBrowsing", m.state)
+		}
+		if m.Init() != nil {
+			t.Error("Init() should not start a scan for a preloaded tree")
+		}
+		if sel := m.selected(); sel == nil || sel.Name != "big" {
+			t.Errorf("selected = %v, want largest child first", sel)
+		}
+		m.width, m.height = 120, 40
+		if out := m.View(); !strings.Contains(out, "snapshot: 2026-10-01 09:30") {
+			t.Error("expected status bar to show the snapshot time")
+		}
+	})
+}
+
 // errScanFailed is a test helper error type.
 type errScanFailed string
 
@@ -0,0 +1,145 @@
+package ui
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// doubleClick is the longest gap between two clicks on the same row that
+	// still makes a double-click.
+	doubleClick = 400 * time.Millisecond
+	// wheelStep is how many rows one notch of the wheel scrolls.
+	wheelStep = 3
+	// crumbSep separates the breadcrumb's segments.
+	crumbSep = " › "
+)
+
+// click is a left click on a row of the browser, kept to spot the second
+// click of a double-click.
+type click struct {
+	node *Node
+	at   time.Time
+}
+
+// handleMouse handles the mouse in the browser: a click selects a row and a
+// double-click opens it, the wheel scrolls, and a click on the breadcrumb
+// goes back up to that directory. Other screens ignore the mouse, as does
+// the browser while the / filter is being typed.
+func (m Model) handleMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if m.state != StateBrowsing || m.filtering || msg.Action != tea.MouseActionPress {
+		return m, nil
+	}
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		m.wheel(-wheelStep)
+	case tea.MouseButtonWheelDown:
+		m.wheel(wheelStep)
+	case tea.MouseButtonLeft:
+		crumb, list := m.frameRows()
+		switch {
+		case msg.Y == crumb:
+			m.clickBreadcrumb(msg.X)
+		case msg.Y >= list && msg.Y < list+m.listHeight() && msg.X < m.width-m.previewWidth():
+			return m.clickRow(msg.Y - list)
+		}
+	}
+	return m, nil
+}
+
+// frameRows returns the screen lines of the browser's breadcrumb and first
+// row, measured from the lines viewBrowse renders above the list.
+func (m Model) frameRows() (crumb, list int) {
+	top := m.browseTop()
+	return lipgloss.Height(top[0]), lipgloss.Height(strings.Join(top, "\n"))
+}
+
+// browseWindow returns the browser's visible rows [start, end): where the
+// mouse pinned them, or else, and whenever the cursor has left them, around
+// the cursor.
+func (m *Model) browseWindow(total int) (start, end int) {
+	height := m.listHeight()
+	if m.pinned {
+		start = max(min(m.top, total-height), 0)
+		end = min(start+height, total)
+		if m.cursor >= start && m.cursor < end {
+			return start, end
+		}
+	}
+	return scrollWindow(m.cursor, total, height)
+}
+
+// pin keeps the browser's rows where they are shown now, so they stay under
+// the pointer when the cursor moves.
+func (m *Model) pin() {
+	m.top, _ = m.browseWindow(len(m.visibleChildren()))
+	m.pinned = true
+}
+
+// wheel scrolls the browser by delta rows, taking the cursor along only as
+// far as it must to stay in view.
+func (m *Model) wheel(delta int) {
+	total := len(m.visibleChildren())
+	if total == 0 {
+		return
+	}
+	m.pin()
+	height := m.listHeight()
+	m.top = max(min(m.top+delta, total-height), 0)
+	m.cursor = min(max(m.cursor, m.top), min(m.top+height, total)-1)
+}
+
+// clickRow selects the entry on the given row of the list, or opens it if
+// it was clicked just before.
+func (m Model) clickRow(row int) (tea.Model, tea.Cmd) {
+	children := m.visibleChildren()
+	start, end := m.browseWindow(len(children))
+	i := start + row
+	if i >= end {
+		return m, nil
+	}
+	n := children[i]
+	m.pin()
+	m.cursor = i
+	if last := m.lastClick; last.node == n && now().Sub(last.at) <= doubleClick {
+		m.lastClick = click{}
+		m.pinned = false
+		return m.handleNavRight()
+	}
+	m.lastClick = click{node: n, at: now()}
+	return m, nil
+}
+
+// clickBreadcrumb goes back up to the directory of the breadcrumb segment
+// at column x, selecting the directory it was entered from.
+func (m *Model) clickBreadcrumb(x int) {
+	depth := crumbAt(m.breadcrumbParts(), x-styleBreadcrumb.GetPaddingLeft())
+	if depth < 0 || depth >= len(m.stack) {
+		return
+	}
+	from := m.stack[depth]
+	m.stack = m.stack[:depth]
+	m.pinned = false
+	m.cursor = 0
+	m.reselect(from)
+}
+
+// crumbAt returns the index of the segment of parts, joined by crumbSep, at
+// column x, or -1 for a separator or beyond the last segment.
+func crumbAt(parts []string, x int) int {
+	sepW := lipgloss.Width(crumbSep)
+	for i, p := range parts {
+		w := lipgloss.Width(p)
+		if x < 0 {
+			return -1
+		}
+		if x < w {
+			return i
+		}
+		x -= w + sepW
+	}
+	return -1
+}
@@ -0,0 +1,121 @@
+package ui
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestMouse(t *testing.T) {
+	oldNow := now
+	t.Cleanup(func() { now = oldNow })
+	clock := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	now = func() time.Time { return clock }
+
+	newTree := func() *Node {
+		kids := []*Node{
+			nodeWithSize("a", true, 1000,
+				nodeWithSize("b", true, 900,
+					nodeWithSize("c.txt", false, 900),
+				),
+				nodeWithSize("d.txt", false, 100),
+			),
+		}
+		for i := range 99 {
+			kids = append(kids, nodeWithSize("f"+itoa(100+i), false, int64(500-i)))
+		}
+		return nodeWithSize("/root", true, 60_000, kids...)
+	}
+	mouse := func(m Model, x, y int, button tea.MouseButton) Model {
+		next, _ := m.Update(tea.MouseMsg{X: x, Y: y, Button: button, Action: tea.MouseActionPress})
+		return next.(Model)
+	}
+	selName := func(m Model) string {
+		if sel := m.selected(); sel != nil {
+			return sel.Name
+		}
+		return ""
+	}
+	// Clicks go where the breadcrumb and first row are seen on screen.
+	view := strings.Split(browsingModel(newTree()).View(), "\n")
+	breadcrumbLine := slices.IndexFunc(view, func(l string) bool { return strings.Contains(l, "/root") })
+	listTop := slices.IndexFunc(view, func(l string) bool { return strings.Contains(l, "1.0 kB") })
+	if crumb, list := browsingModel(newTree()).frameRows(); crumb != breadcrumbLine || list != listTop {
+		t.Fatalf("frameRows() = %d, %d; rendered at %d, %d", crumb, list, breadcrumbLine, listTop)
+	}
+
+	t.Run("GivenRow_WhenClicked_ThenSelected", func(t *testing.T) {
+		m := mouse(browsingModel(newTree()), 10, listTop+2, tea.MouseButtonLeft)
+		if m.cursor != 2 || len(m.stack) != 0 {
+			t.Errorf("cursor %d, stack %d deep; want row 2 selected in place", m.cursor, len(m.stack))
+		}
+	})
+
+	t.Run("GivenDirectory_WhenDoubleClicked_ThenEntered", func(t *testing.T) {
+		m := mouse(browsingModel(newTree()), 10, listTop, tea.MouseButtonLeft)
+		clock = clock.Add(200 * time.Millisecond)
+		m = mouse(m, 10, listTop, tea.MouseButtonLeft)
+		if len(m.stack) != 1 || m.currentDir().Name != "a" {
+			t.Errorf("stack %d deep, want a entered", len(m.stack))
+		}
+	})
+
+	t.Run("GivenSlowClicks_WhenClickedTwice_ThenOnlySelected", func(t *testing.T) {
+		m := mouse(browsingModel(newTree()), 10, listTop, tea.MouseButtonLeft)
+		clock = clock.Add(time.Second)
+		if m = mouse(m, 10, listTop, tea.MouseButtonLeft); len(m.stack) != 0 {
+			t.Error("clicks a second apart should not open the directory")
+		}
+	})
+
+	t.Run("GivenLongList_WhenWheeled_ThenRowsScrollAndCursorKeptInView", func(t *testing.T) {
+		m := mouse(browsingModel(newTree()), 10, listTop, tea.MouseButtonWheelDown)
+		if start, _ := m.browseWindow(100); start != wheelStep || m.cursor != wheelStep {
+			t.Fatalf("window starts at %d with cursor %d, want both %d", start, m.cursor, wheelStep)
+		}
+		m = mouse(m, 10, listTop+1, tea.MouseButtonLeft)
+		if selName(m) != "f103" {
+			t.Errorf("clicked %q, want the row shown there, f103", selName(m))
+		}
+		for range 50 {
+			m = mouse(m, 10, listTop, tea.MouseButtonWheelDown)
+		}
+		if start, end := m.browseWindow(100); end != 100 || start != 100-m.listHeight() || m.cursor != start {
+			t.Errorf("window [%d, %d) with cursor %d, want it stopped at the end", start, end, m.cursor)
+		}
+		for range 50 {
+			m = mouse(m, 10, listTop, tea.MouseButtonWheelUp)
+		}
+		if start, _ := m.browseWindow(100); start != 0 || m.cursor != m.listHeight()-1 {
+			t.Errorf("window starts at %d with cursor %d, want back at the top", start, m.cursor)
+		}
+	})
+
+	t.Run("GivenNestedDirectory_WhenBreadcrumbClicked_ThenPoppedToThatLevel", func(t *testing.T) {
+		m := browsingModel(newTree())
+		m.stack = []*Node{m.root.Children[0], m.root.Children[0].Children[0]}
+		// " /root › a › b" after the one-cell padding: a is at 10.
+		if m = mouse(m, 8, breadcrumbLine, tea.MouseButtonLeft); len(m.stack) != 2 {
+			t.Error("a click on a separator should do nothing")
+		}
+		m = mouse(m, 10, breadcrumbLine, tea.MouseButtonLeft)
+		if len(m.stack) != 1 || selName(m) != "b" {
+			t.Errorf("stack %d deep with %q selected, want a with b selected", len(m.stack), selName(m))
+		}
+		m = mouse(m, 3, breadcrumbLine, tea.MouseButtonLeft)
+		if len(m.stack) != 0 || selName(m) != "a" {
+			t.Errorf("stack %d deep with %q selected, want the root with a selected", len(m.stack), selName(m))
+		}
+	})
+
+	t.Run("GivenOtherScreen_WhenClicked_ThenIgnored", func(t *testing.T) {
+		m := browsingModel(newTree())
+		m.state = StateConfirmDelete
+		if m = mouse(m, 10, listTop+2, tea.MouseButtonLeft); m.cursor != 0 {
+			t.Error("clicks should be ignored while a delete is being confirmed")
+		}
+	})
+}
@@ -0,0 +1,218 @@
+package ui
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mobanhawi/aster/internal/format"
+	"github.com/mobanhawi/aster/internal/preview"
+)
+
+const (
+	// previewTop is how many of a directory's largest children the pane
+	// lists, at most.
+	previewTop = 50
+	// previewLines is how many lines of a text file are read for the pane, at
+	// most.
+	previewLines = 100
+	// previewLabelW is the width of the field labels in the pane.
+	previewLabelW = 9
+)
+
+// inspectFile is injected for testing.
+var inspectFile = preview.Inspect
+
+// previewPane is what the preview pane shows for the selected entry. For a
+// directory it is summarised from the tree; a file's details are read from
+// disk by a command, and info stays nil until they arrive.
+type previewPane struct {
+	node *Node
+	// size and items are node's when the pane was loaded; the pane is
+	// reloaded once they change.
+	size  int64
+	items int64
+
+	top []*Node // a directory's largest children, largest first
+
+	info *preview.Info
+	err  error
+}
+
+// previewMsg carries a file's details read for the preview pane.
+type previewMsg struct {
+	node *Node
+	info *preview.Info
+	err  error
+}
+
+// previewWidth is the width of the preview pane, border included: two fifths
+// of the terminal within limits, or 0 when the pane is hidden or the terminal
+// is too narrow to leave room for the list.
+func (m Model) previewWidth() int {
+	if !m.showPreview {
+		return 0
+	}
+	w := min(max(m.width*2/5, 30), 64)
+	if m.width-w < 40 {
+		return 0
+	}
+	return w
+}
+
+// loadPreview brings the pane up to date with the selected entry: a
+// directory is summarised at once, while a file's details are read by the
+// returned command. Nothing is done while the pane is hidden or already
+// current.
+func (m *Model) loadPreview() tea.Cmd {
+	if !m.showPreview || m.state != StateBrowsing {
+		return nil
+	}
+	n := m.selected()
+	if n == nil {
+		m.preview = nil
+		return nil
+	}
+	size, items := m.nodeSize(n), n.Items()
+	if p := m.preview; p != nil && p.node == n && p.size == size && p.items == items {
+		return nil
+	}
+	p := &previewPane{node: n, size: size, items: items}
+	m.preview = p
+	if n.IsDir {
+		p.top = slices.Clone(n.Children)
+		slices.SortStableFunc(p.top, func(a, b *Node) int { return cmp.Compare(m.nodeSize(b), m.nodeSize(a)) })
+		p.top = p.top[:min(len(p.top), previewTop)]
+		return nil
+	}
+	path := n.FullPath()
+	return func() tea.Msg {
+		info, err := inspectFile(path, previewLines)
+		return previewMsg{node: n, info: info, err: err}
+	}
+}
+
+// finishPreview shows a file's details, unless the selection moved on while
+// they were read.
+func (m *Model) finishPreview(msg previewMsg) {
+	if p := m.preview; p != nil && p.node == msg.node {
+		p.info, p.err = msg.info, msg.err
+	}
+}
+
+// viewPreview renders the pane, width cells wide (border included) and
+// height lines tall.
+func (m Model) viewPreview(width, height int) string {
+	p := m.preview
+	if p == nil {
+		return renderPane(nil, width, height)
+	}
+	pl := paneLines{inner: width - stylePane.GetHorizontalFrameSize()}
+	n := p.node
+	title := styleFile
+	if n.IsDir {
+		title = styleDir
+	}
+	pl.add(title.Render(truncate(n.Name, pl.inner)), "")
+	pl.field("size", format.Bytes(n.Size()))
+	pl.field("on disk", format.Bytes(n.Usage()))
+	if n.IsDir {
+		m.previewDir(&pl, p)
+	} else {
+		previewFile(&pl, p)
+	}
+	return renderPane(pl.lines, width, height)
+}
+
+// previewDir adds a directory's counts and largest children.
+func (m Model) previewDir(pl *paneLines, p *previewPane) {
+	n := p.node
+	pl.field("files", itoa(int(n.Files())))
+	pl.field("dirs", itoa(int(n.Dirs())))
+	if errs := n.Unreadable(); errs > 0 {
+		pl.styledField("errors", itoa(int(errs))+" unreadable", styleError)
+	} else {
+		pl.field("errors", "none")
+	}
+	if t := n.Newest(); t != 0 {
+		pl.field("changed", time.Unix(0, t).Format("2006-01-02 15:04"))
+	}
+	if len(p.top) > 0 {
+		pl.add("", stylePaneLabel.Render("largest"))
+	}
+	nameW := pl.inner - styleSize.GetWidth()
+	for _, c := range p.top {
+		style := styleFile
+		if c.IsDir {
+			style = styleDir
+		}
+		pl.add(style.Render(padRight(truncate(c.Name, nameW-1), nameW)) + styleSize.Render(format.Bytes(m.nodeSize(c))))
+	}
+}
+
+// previewFile adds a file's details and, for text, its first lines.
+func previewFile(pl *paneLines, p *previewPane) {
+	n := p.node
+	if n.LinkTarget != "" {
+		pl.field("links to", n.LinkTarget)
+	}
+	if n.ModTime != 0 {
+		pl.field("modified", time.Unix(0, n.ModTime).Format("2006-01-02 15:04"))
+	}
+	switch {
+	case p.err != nil:
+		pl.add("", styleError.Render(truncate(p.err.Error(), pl.inner)))
+		return
+	case p.info == nil:
+		pl.add("", stylePaneLabel.Render("reading…"))
+		return
+	}
+	info := p.info
+	pl.field("mode", info.Mode.String())
+	if info.Owner != "" {
+		pl.field("owner", info.Owner)
+	}
+	if info.Type != "" {
+		pl.field("type", info.Type)
+	}
+	if len(info.Lines) > 0 {
+		pl.add("")
+	}
+	for _, l := range info.Lines {
+		pl.add(stylePaneLabel.Render(truncate(l, pl.inner)))
+	}
+}
+
+// paneLines collects the pane's lines, each fitting inner cells.
+type paneLines struct {
+	inner int
+	lines []string
+}
+
+func (pl *paneLines) add(lines ...string) {
+	pl.lines = append(pl.lines, lines...)
+}
+
+// field adds a labelled value, truncated to fit.
+func (pl *paneLines) field(label, value string) {
+	pl.add(stylePaneLabel.Render(padRight(label, previewLabelW)) + truncate(value, pl.inner-previewLabelW))
+}
+
+// styledField adds a labelled value in style, truncated to fit.
+func (pl *paneLines) styledField(label, value string, style lipgloss.Style) {
+	pl.add(stylePaneLabel.Render(padRight(label, previewLabelW)) + style.Render(truncate(value, pl.inner-previewLabelW)))
+}
+
+// renderPane frames the pane's lines, cut to height.
+func renderPane(lines []string, width, height int) string {
+	lines = lines[:min(len(lines), height)]
+	return stylePane.Width(width - stylePane.GetHorizontalBorderSize()).Height(height).Render(strings.Join(lines, "\n"))
+}
+
+// padRight pads s with spaces to width runes.
+func padRight(s string, width int) string {
+	return s + strings.Repeat(" ", max(width-len([]rune(s)), 0))
+}
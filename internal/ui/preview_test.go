@@ -0,0 +1,100 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mobanhawi/aster/internal/preview"
+)
+
+func TestPreviewPane(t *testing.T) {
+	oldInspect := inspectFile
+	t.Cleanup(func() { inspectFile = oldInspect })
+	inspectFile = func(path string, _ int) (*preview.Info, error) {
+		if strings.HasSuffix(path, "gone.txt") {
+			return nil, errors.New("no such file or directory")
+		}
+		return &preview.Info{Mode: 0o640, Owner: "alice", Type: "text/plain; charset=utf-8", Lines: []string{"first line", "second line"}}, nil
+	}
+	key := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+	// press feeds keys, running the commands they return.
+	press := func(m Model, keys ...string) Model {
+		for _, k := range keys {
+			next, cmd := m.Update(key(k))
+			m = runBatch(next.(Model), cmd)
+		}
+		return m
+	}
+	newTree := func() *Node {
+		locked := nodeWithSize("locked", true, 0)
+		locked.Err = errors.New("permission denied")
+		src := nodeWithSize("src", true, 600,
+			nodeWithSize("main.go", false, 400),
+			nodeWithSize("util.go", false, 200),
+			locked,
+		)
+		src.SetCounts(2, 1)
+		src.SetErrors(1)
+		return nodeWithSize("root", true, 1000, src,
+			nodeWithSize("notes.txt", false, 300),
+			nodeWithSize("gone.txt", false, 100),
+		)
+	}
+
+	t.Run("GivenDirectory_WhenPPressed_ThenCountsErrorsAndLargestShown", func(t *testing.T) {
+		m := press(browsingModel(newTree()), "p")
+		view := m.View()
+		for _, want := range []string{"files    2", "dirs     1", "1 unreadable", "largest", "main.go"} {
+			if !strings.Contains(view, want) {
+				t.Errorf("view missing %q", want)
+			}
+		}
+	})
+
+	t.Run("GivenFile_WhenSelected_ThenDetailsReadAndShown", func(t *testing.T) {
+		m := press(browsingModel(newTree()), "p")
+		next, cmd := m.Update(key("j"))
+		m = next.(Model)
+		if !strings.Contains(m.View(), "reading…") {
+			t.Error("pane should say the file is being read")
+		}
+		m = runBatch(m, cmd)
+		view := m.View()
+		for _, want := range []string{"notes.txt", "-rw-r-----", "alice", "text/plain", "first line", "second line"} {
+			if !strings.Contains(view, want) {
+				t.Errorf("view missing %q", want)
+			}
+		}
+	})
+
+	t.Run("GivenUnreadableFile_WhenSelected_ThenErrorShown", func(t *testing.T) {
+		m := press(browsingModel(newTree()), "p", "j", "j")
+		if !strings.Contains(m.View(), "no such file or directory") {
+			t.Error("pane should show why the file could not be read")
+		}
+	})
+
+	t.Run("GivenSelectionMoved_WhenOldDetailsArrive_ThenIgnored", func(t *testing.T) {
+		m := press(browsingModel(newTree()), "p")
+		next, cmd := m.Update(key("j"))
+		m = press(next.(Model), "k")
+		m = runBatch(m, cmd)
+		if p := m.preview; p == nil || p.node.Name != "src" || p.info != nil {
+			t.Errorf("pane = %+v, want src's summary", p)
+		}
+	})
+
+	t.Run("GivenPaneShown_WhenPPressedOrTerminalNarrow_ThenHidden", func(t *testing.T) {
+		m := press(browsingModel(newTree()), "p")
+		m.width = 60
+		if strings.Contains(m.View(), "unreadable") {
+			t.Error("pane should be hidden when the list would be too narrow")
+		}
+		m.width = 120
+		if m = press(m, "p"); strings.Contains(m.View(), "unreadable") {
+			t.Error("p should hide the pane")
+		}
+	})
+}
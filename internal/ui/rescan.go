@@ -0,0 +1,181 @@
+package ui
+
+import (
+	"context"
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mobanhawi/aster/internal/scanner"
+)
+
+// rescanJob is a background rescan in flight.
+type rescanJob struct {
+	cancel context.CancelFunc
+	// quiet rescans were triggered by watch mode rather than by the user and
+	// don't report in the status bar.
+	quiet bool
+	// pending are directories below the rescan that changed while it ran.
+	// It may already have read them, so they are re-read once it lands.
+	pending []*Node
+}
+
+// rescanDoneMsg carries the result of a background rescan of node.
+type rescanDoneMsg struct {
+	node  *Node
+	job   *rescanJob
+	fresh *Node
+	err   error
+}
+
+// rescanNode is injected for testing.
+var rescanNode = scanner.Rescan
+
+// startRescan rescans the selected directory, or the current one when the
+// selection is a file, in the background. Browsing continues meanwhile; the
+// affected row shows a spinner until rescanDoneMsg swaps the result in.
+func (m *Model) startRescan() tea.Cmd {
+	if m.diff != nil {
+		m.notice = "Rescan is not available when comparing scans"
+		return nil
+	}
+	target := m.currentDir()
+	if sel := m.selected(); sel != nil && sel.IsDir {
+		target = sel
+	}
+	if target == nil {
+		return nil
+	}
+	if target.IsMount {
+		m.notice = target.Name + " is on another filesystem"
+		return nil
+	}
+	if m.rescanCovering(target) != nil {
+		return nil
+	}
+	m.notice = "Rescanning " + target.Name + "…"
+	return m.rescan(target, false)
+}
+
+// rescan starts a background rescan of target unless target or one of its
+// ancestors already has one in flight. Rescans and level reads below target
+// are cancelled, since this one covers them, so at most one walk of any
+// directory is ever in flight.
+func (m *Model) rescan(target *Node, quiet bool) tea.Cmd {
+	if m.rescanCovering(target) != nil {
+		return nil
+	}
+	for n, job := range m.rescans {
+		if within(target, n) {
+			job.cancel()
+			delete(m.rescans, n)
+			quiet = quiet && job.quiet
+		}
+	}
+	for n, read := range m.levelReads {
+		if within(target, n) {
+			read.cancel()
+			delete(m.levelReads, n)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	job := &rescanJob{cancel: cancel, quiet: quiet}
+	if m.rescans == nil {
+		m.rescans = make(map[*Node]*rescanJob)
+	}
+	m.rescans[target] = job
+
+	// The walk works from target's location, taken here, on the UI
+	// goroutine, because the tree must not be read concurrently with updates.
+	loc, opts := scanner.Locate(target), m.scanOpts
+	return tea.Batch(m.sp.Tick, func() tea.Msg {
+		fresh, err := rescanNode(ctx, loc, opts)
+		return rescanDoneMsg{node: target, job: job, fresh: fresh, err: err}
+	})
+}
+
+// finishRescan merges a completed rescan into the tree and adjusts every
+// ancestor by the size difference. In watch mode it returns a command that
+// watches any directories the rescan found.
+func (m *Model) finishRescan(msg rescanDoneMsg) tea.Cmd {
+	job := msg.job
+	job.cancel()
+	if m.rescans[msg.node] != job {
+		return nil // superseded by a rescan further up
+	}
+	delete(m.rescans, msg.node)
+	if msg.err != nil {
+		if !job.quiet {
+			m.notice = "Rescan of " + msg.node.Name + " failed: " + msg.err.Error()
+		}
+		return nil
+	}
+	if !attached(m.root, msg.node) {
+		return nil // deleted while the scan ran
+	}
+
+	before := msg.node.Size()
+	sel := m.selected()
+	msg.node.ReplaceWith(msg.fresh)
+	m.dropDetachedMarks()
+	m.leaveDeletedDirs()
+	m.reselect(sel)
+	if !job.quiet {
+		m.notice = "Rescanned " + msg.node.Name + " (" + formatDelta(msg.node.Size()-before) + ")"
+	}
+	cmds := []tea.Cmd{m.watchSubtree(msg.node)}
+	for _, n := range job.pending {
+		if attached(m.root, n) {
+			cmds = append(cmds, m.refreshDir(n))
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// reselect puts the cursor back on sel after the listing was re-sorted, or
+// just clamps it when sel is gone.
+func (m *Model) reselect(sel *Node) {
+	if i := slices.Index(m.visibleChildren(), sel); i >= 0 {
+		m.cursor = i
+	}
+	m.clampCursor()
+}
+
+// isRescanning reports whether n has a rescan in flight.
+func (m Model) isRescanning(n *Node) bool {
+	_, ok := m.rescans[n]
+	return ok
+}
+
+// rescanCovering returns the rescan in flight of n or of one of its
+// ancestors, if any.
+func (m Model) rescanCovering(n *Node) *rescanJob {
+	for a := n; a != nil; a = a.Parent {
+		if job, ok := m.rescans[a]; ok {
+			return job
+		}
+	}
+	return nil
+}
+
+// within reports whether n is dir or lies below it.
+func within(dir, n *Node) bool {
+	for a := n; a != nil; a = a.Parent {
+		if a == dir {
+			return true
+		}
+	}
+	return false
+}
+
+// attached reports whether n is still reachable from root by following
+// Children links; detached subtrees keep their Parent pointers.
+func attached(root, n *Node) bool {
+	for n != nil && n != root {
+		if n.Parent == nil || !slices.Contains(n.Parent.Children, n) {
+			return false
+		}
+		n = n.Parent
+	}
+	return n == root
+}
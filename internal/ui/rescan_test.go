@@ -0,0 +1,82 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mobanhawi/aster/internal/scanner"
+)
+
+func TestRescanKey(t *testing.T) {
+	oldRescan := rescanNode
+	t.Cleanup(func() { rescanNode = oldRescan })
+
+	newTree := func() *Node {
+		return nodeWithSize("root", true, 700,
+			nodeWithSize("sub", true, 600, nodeWithSize("old.bin", false, 600)),
+			nodeWithSize("file.txt", false, 100),
+		)
+	}
+
+	t.Run("GivenSelectedDir_WhenRPressed_ThenSpinnerThenChildrenReplaced", func(t *testing.T) {
+		var rescanned string
+		rescanNode = func(_ context.Context, loc scanner.Location, _ scanner.Options) (*Node, error) {
+			rescanned = loc.Path
+			return nodeWithSize(loc.Name, true, 250, nodeWithSize("new.bin", false, 250)), nil
+		}
+		root := newTree()
+		m := browsingModel(root)
+
+		next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("R")})
+		m = next.(Model)
+		if m.state != StateBrowsing || !m.isRescanning(root.Children[0]) {
+			t.Fatalf("state %v, rescanning %v; want browsing with sub in flight", m.state, m.isRescanning(root.Children[0]))
+		}
+		if !strings.Contains(m.View(), "rescanning 1") {
+			t.Error("status bar should show the rescan in progress")
+		}
+
+		m = runBatch(m, cmd)
+		sub := root.Children[0]
+		if rescanned != "root/sub" || m.isRescanning(sub) {
+			t.Errorf("rescanned %q, still in flight %v", rescanned, m.isRescanning(sub))
+		}
+		if root.Size() != 350 || len(sub.Children) != 1 || sub.Children[0].Name != "new.bin" || sub.Children[0].Parent != sub {
+			t.Errorf("root %d, sub children %v", root.Size(), sub.Children)
+		}
+		if !strings.Contains(m.notice, "Rescanned sub (-350 B)") {
+			t.Errorf("notice = %q", m.notice)
+		}
+	})
+
+	t.Run("GivenFileSelected_WhenRPressed_ThenCurrentDirRescanned", func(t *testing.T) {
+		var rescanned string
+		rescanNode = func(_ context.Context, loc scanner.Location, _ scanner.Options) (*Node, error) {
+			rescanned = loc.Path
+			return nodeWithSize(loc.Name, true, 0), nil
+		}
+		root := newTree()
+		m := browsingModel(root)
+		m.cursor = 1 // file.txt
+		next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("R")})
+		m = runBatch(next.(Model), cmd)
+		if rescanned != "root" || root.Size() != 0 {
+			t.Errorf("rescanned %q, root size %d; want root rescanned to empty", rescanned, root.Size())
+		}
+	})
+
+	t.Run("GivenRescanFails_WhenDone_ThenTreeUntouchedAndNotice", func(t *testing.T) {
+		rescanNode = func(context.Context, scanner.Location, scanner.Options) (*Node, error) {
+			return nil, errors.New("gone")
+		}
+		root := newTree()
+		next, cmd := browsingModel(root).Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("R")})
+		m := runBatch(next.(Model), cmd)
+		if root.Size() != 700 || !strings.Contains(m.notice, "failed: gone") || len(m.rescans) != 0 {
+			t.Errorf("root %d, notice %q, in flight %d", root.Size(), m.notice, len(m.rescans))
+		}
+	})
+}
@@ -0,0 +1,245 @@
+package ui
+
+import (
+	"slices"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mobanhawi/aster/internal/search"
+)
+
+// searchBudget is how many entries one search step visits: enough to get
+// through millions in a few seconds, few enough that keys and redraws are
+// never held up noticeably.
+const searchBudget = 50_000
+
+// searchView is the tree-wide name search shown in StateSearch.
+type searchView struct {
+	input   textinput.Model
+	syntax  search.Syntax
+	editing bool // the pattern input has focus
+	err     string
+
+	walker   *search.Walker // nil before the first search
+	stepping bool           // a searchStepMsg is on its way
+	done     bool
+	results  []*Node
+	cursor   int
+}
+
+// searchStepMsg asks for the next slice of walker's search.
+type searchStepMsg struct {
+	walker *search.Walker
+}
+
+// startSearch opens the search screen: the results of the last search, if
+// it was left by jumping to one, else an empty pattern.
+func (m *Model) startSearch() tea.Cmd {
+	m.state = StateSearch
+	v := m.search
+	if v == nil || v.walker == nil {
+		ti := textinput.New()
+		ti.Prompt = " find: "
+		ti.Placeholder = "glob, e.g. *.mov"
+		ti.PromptStyle = styleMatch
+		v = &searchView{input: ti}
+		m.search = v
+		return m.editSearch()
+	}
+	return m.resumeSearch()
+}
+
+// editSearch gives the pattern input focus.
+func (m *Model) editSearch() tea.Cmd {
+	v := m.search
+	v.editing = true
+	v.input.CursorEnd()
+	return v.input.Focus()
+}
+
+// runSearch starts walking the whole tree for the pattern typed. The walk
+// runs in steps on the UI goroutine, between other messages, since the tree
+// may change under it (deletes, rescans, --watch) and must not be read
+// concurrently.
+func (m *Model) runSearch() tea.Cmd {
+	v := m.search
+	matcher, err := search.Compile(v.input.Value(), v.syntax)
+	if err != nil {
+		v.err = err.Error()
+		return nil
+	}
+	v.err = ""
+	v.editing = false
+	v.input.Blur()
+	v.walker = matcher.Walk(m.root)
+	v.stepping = false // a step still on its way is for the old walker
+	v.done = false
+	v.results = nil
+	v.cursor = 0
+	return m.resumeSearch()
+}
+
+// resumeSearch schedules the next search step unless the walk is finished or
+// a step is already scheduled.
+func (m *Model) resumeSearch() tea.Cmd {
+	v := m.search
+	if v.done || v.stepping || v.walker == nil {
+		return nil
+	}
+	v.stepping = true
+	w := v.walker
+	return tea.Batch(m.sp.Tick, func() tea.Msg { return searchStepMsg{walker: w} })
+}
+
+// searchStep advances the walk and streams its matches into the results.
+// The walk pauses while the search screen is not shown and is dropped when
+// a new search replaces it or the screen is left.
+func (m *Model) searchStep(msg searchStepMsg) tea.Cmd {
+	v := m.search
+	if v == nil || msg.walker != v.walker {
+		return nil
+	}
+	v.stepping = false
+	if m.state != StateSearch {
+		return nil
+	}
+	found, done := v.walker.Next(searchBudget)
+	v.results = append(v.results, found...)
+	v.done = done
+	return m.resumeSearch()
+}
+
+// leaveSearch returns to the browser, abandoning the search.
+func (m *Model) leaveSearch() {
+	m.search = nil
+	m.state = StateBrowsing
+}
+
+// jumpToResult shows the selected match in the browser. The search is kept,
+// paused, for f to come back to.
+func (m *Model) jumpToResult() {
+	v := m.search
+	if len(v.results) == 0 {
+		return
+	}
+	n := v.results[v.cursor]
+	if !m.jumpTo(n) {
+		m.notice = n.Name + " is no longer in the tree"
+		v.results = slices.Delete(v.results, v.cursor, v.cursor+1)
+		v.cursor = max(min(v.cursor, len(v.results)-1), 0)
+	}
+}
+
+func (m Model) handleKeySearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	v := m.search
+	if v.editing {
+		return m.handleKeySearchInput(msg)
+	}
+	switch msg.String() {
+	case "ctrl+c", "q":
+		return m, tea.Quit
+	case "up", "k":
+		v.cursor = max(v.cursor-1, 0)
+	case "down", "j":
+		v.cursor = max(min(v.cursor+1, len(v.results)-1), 0)
+	case "g", "home":
+		v.cursor = 0
+	case "G", "end":
+		v.cursor = max(len(v.results)-1, 0)
+	case "right", "enter", "l":
+		m.jumpToResult()
+	case "f", "/":
+		return m, m.editSearch()
+	case "esc", "left", "backspace", "h":
+		m.leaveSearch()
+	}
+	return m, nil
+}
+
+// handleKeySearchInput edits the pattern. Tab switches between glob and
+// regex syntax; esc goes back to the results, or leaves if there are none.
+func (m Model) handleKeySearchInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	v := m.search
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "enter":
+		return m, m.runSearch()
+	case "tab":
+		if v.syntax == search.Glob {
+			v.syntax = search.Regexp
+			v.input.Placeholder = "regex, e.g. (?i)^readme"
+		} else {
+			v.syntax = search.Glob
+			v.input.Placeholder = "glob, e.g. *.mov"
+		}
+		return m, nil
+	case "esc":
+		if v.walker == nil {
+			m.leaveSearch()
+			return m, nil
+		}
+		v.editing = false
+		v.input.Blur()
+		return m, m.resumeSearch()
+	}
+	var cmd tea.Cmd
+	v.input, cmd = v.input.Update(msg)
+	return m, cmd
+}
+
+// viewSearch renders the matches found so far with their full paths and
+// their share of the whole tree.
+func (m Model) viewSearch() string {
+	v := m.search
+	total := m.nodeSize(m.root)
+	lv := listView{
+		title:    "find",
+		subtitle: m.root.FullPath(),
+		total:    len(v.results),
+		cursor:   v.cursor,
+		row: func(i int) string {
+			n := v.results[i]
+			style := styleRow
+			if n.IsDir {
+				style = styleDir
+			}
+			sz := m.nodeSize(n)
+			return m.renderTotalRow(n.FullPath(), style, sz, share(sz, total), barStyle(i, len(v.results)), i == v.cursor)
+		},
+	}
+
+	matches := itoa(len(v.results)) + " matches"
+	if len(v.results) == 1 {
+		matches = "1 match"
+	}
+	switch {
+	case v.walker == nil:
+		lv.status = " Find files and directories anywhere in the tree by name"
+	case !v.done:
+		lv.status = " " + m.sp.View() + " Searching… " + matches + " in " + itoa(v.walker.Visited()) + " entries"
+	default:
+		lv.status = " " + matches + " in " + itoa(v.walker.Visited()) + " entries"
+	}
+	if v.walker != nil {
+		lv.subtitle += "  " + v.syntax.String() + ": " + v.input.Value()
+	}
+	if v.err != "" {
+		lv.status += "  " + styleError.Render(v.err)
+	}
+	if m.notice != "" {
+		lv.status += "  " + styleNotice.Render(m.notice)
+	}
+
+	if v.editing {
+		other := search.Glob
+		if v.syntax == search.Glob {
+			other = search.Regexp
+		}
+		lv.hints = v.input.View() + "  " + keyHint("enter", "search") + keyHint("tab", other.String()) + keyHint("esc", "back")
+	} else {
+		lv.hints = keyHint("↑↓/jk", "move") + keyHint("→/enter", "show in tree") + keyHint("f", "edit") +
+			keyHint("esc", "back") + keyHint("q", "quit")
+	}
+	return m.viewList(lv)
+}
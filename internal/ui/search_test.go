@@ -0,0 +1,106 @@
+package ui
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSearchView(t *testing.T) {
+	key := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+	enter := tea.KeyMsg{Type: tea.KeyEnter}
+	// press feeds keys without running the commands they return, which
+	// include the input's cursor blink timer.
+	press := func(m Model, keys ...tea.KeyMsg) Model {
+		for _, k := range keys {
+			next, _ := m.Update(k)
+			m = next.(Model)
+		}
+		return m
+	}
+	// search types pattern and runs the search to completion.
+	search := func(m Model, pattern string) Model {
+		m = press(m, key(pattern))
+		next, cmd := m.Update(enter)
+		return runBatch(next.(Model), cmd)
+	}
+	newTree := func() *Node {
+		return nodeWithSize("root", true, 1000,
+			nodeWithSize("a", true, 750,
+				nodeWithSize("b", true, 700,
+					nodeWithSize("small.log", false, 100),
+					nodeWithSize("huge.iso", false, 600),
+				),
+				nodeWithSize("mid.zip", false, 50),
+			),
+			nodeWithSize("top.txt", false, 250),
+		)
+	}
+	names := func(nodes []*Node) []string {
+		var out []string
+		for _, n := range nodes {
+			out = append(out, n.Name)
+		}
+		return out
+	}
+
+	t.Run("GivenGlob_WhenSearched_ThenMatchesListedWithFullPaths", func(t *testing.T) {
+		m := search(press(browsingModel(newTree()), key("f")), "*.iso")
+		if m.state != StateSearch || !m.search.done {
+			t.Fatalf("state = %v, done %v, want a finished search", m.state, m.search.done)
+		}
+		if got := names(m.search.results); !slices.Equal(got, []string{"huge.iso"}) {
+			t.Errorf("results = %v, want [huge.iso]", got)
+		}
+		view := m.View()
+		for _, want := range []string{"root/a/b/huge.iso", "1 match in 6 entries", "glob: *.iso"} {
+			if !strings.Contains(view, want) {
+				t.Errorf("view missing %q", want)
+			}
+		}
+	})
+
+	t.Run("GivenResult_WhenEnterPressed_ThenBrowserJumpsAndFReturns", func(t *testing.T) {
+		m := press(search(press(browsingModel(newTree()), key("f")), "*.*"), key("j"), enter)
+		if m.state != StateBrowsing || !slices.Equal(names(m.stack), []string{"a", "b"}) {
+			t.Fatalf("state %v, stack %v, want browsing a/b", m.state, names(m.stack))
+		}
+		if sel := m.selected(); sel == nil || sel.Name != "huge.iso" {
+			t.Errorf("selected %v, want huge.iso", sel)
+		}
+		m = press(m, key("f"))
+		if m.state != StateSearch || len(m.search.results) != 4 || m.search.cursor != 1 {
+			t.Errorf("f should return to the results, state %v with %d results", m.state, len(m.search.results))
+		}
+	})
+
+	t.Run("GivenRegexSyntax_WhenSearched_ThenDirectoriesMatchToo", func(t *testing.T) {
+		m := press(browsingModel(newTree()), key("f"), tea.KeyMsg{Type: tea.KeyTab})
+		m = search(m, "^(a|b)$")
+		if got := names(m.search.results); !slices.Equal(got, []string{"a", "b"}) {
+			t.Errorf("results = %v, want [a b]", got)
+		}
+	})
+
+	t.Run("GivenBadRegex_WhenEntered_ThenErrorShownAndInputKept", func(t *testing.T) {
+		m := press(browsingModel(newTree()), key("f"), tea.KeyMsg{Type: tea.KeyTab})
+		m = search(m, "(")
+		if !m.search.editing || m.search.walker != nil || !strings.Contains(m.View(), "missing closing )") {
+			t.Errorf("editing %v, walker %v, want the error shown while editing", m.search.editing, m.search.walker)
+		}
+	})
+
+	t.Run("GivenSearchRunning_WhenEscPressed_ThenWalkAbandoned", func(t *testing.T) {
+		m := press(browsingModel(newTree()), key("f"), key("*"))
+		next, cmd := m.Update(enter)
+		m = press(next.(Model), tea.KeyMsg{Type: tea.KeyEsc})
+		if m.state != StateBrowsing || m.search != nil {
+			t.Fatalf("state %v, want browsing with the search dropped", m.state)
+		}
+		if m = runBatch(m, cmd); m.search != nil || m.state != StateBrowsing {
+			t.Error("a step arriving after esc should be ignored")
+		}
+	})
+}
@@ -14,6 +14,7 @@ var (
 	colorYellow = lipgloss.Color("#f1c40f")
 	colorGreen  = lipgloss.Color("#2ecc71")
 	colorPink   = lipgloss.Color("#ff79c6")
+	colorInk    = lipgloss.Color("#111122") // text on colored backgrounds
 
 	// Bar colors by size percentile (index 0 = largest).
 	barColors = []lipgloss.Color{
@@ -35,6 +36,21 @@ var (
 		return styles
 	}()
 
+	// tileLabelStyles label treemap tiles: dark text on each bar color.
+	tileLabelStyles = func() []lipgloss.Style {
+		styles := make([]lipgloss.Style, len(barColors))
+		for i, c := range barColors {
+			styles[i] = lipgloss.NewStyle().Foreground(colorInk).Background(c)
+		}
+		return styles
+	}()
+
+	// Style: label of the selected treemap tile.
+	styleTileSelected = lipgloss.NewStyle().
+				Foreground(colorInk).
+				Background(colorWhite).
+				Bold(true)
+
 	// Style: header bar.
 	styleHeader = lipgloss.NewStyle().
 			Bold(true).
@@ -72,6 +88,18 @@ var (
 			Width(9).
 			Align(lipgloss.Right)
 
+	// Style: age since last modification (right-aligned).
+	styleAge = lipgloss.NewStyle().
+			Foreground(colorGray).
+			Width(6).
+			Align(lipgloss.Right)
+
+	// Style: number of entries below a directory (right-aligned).
+	styleCount = lipgloss.NewStyle().
+			Foreground(colorGray).
+			Width(7).
+			Align(lipgloss.Right)
+
 	// Style: size percentage.
 	stylePct = lipgloss.NewStyle().
 			Foreground(colorGray).
@@ -115,6 +143,57 @@ var (
 			Foreground(colorPink).
 			Bold(true)
 
+	// Style: rows marked for a batch delete.
+	styleMarked = lipgloss.NewStyle().
+			Foreground(colorPink).
+			Bold(true)
+
+	// Style: name characters matching the / filter.
+	styleMatch = lipgloss.NewStyle().
+			Foreground(colorYellow).
+			Bold(true).
+			Underline(true)
+
+	// Style: last-action notice in the status bar.
+	styleNotice = lipgloss.NewStyle().
+			Foreground(colorYellow)
+
+	// Style: hardlinked duplicates whose bytes are counted elsewhere.
+	styleShared = lipgloss.NewStyle().
+			Foreground(colorDim).
+			Italic(true)
+
+	// Style: mount points skipped by a one-filesystem scan.
+	styleMount = lipgloss.NewStyle().
+			Foreground(colorGray).
+			Bold(true)
+
+	// Style: diff bars and deltas for paths that grew or were added.
+	styleGrow = lipgloss.NewStyle().Foreground(colorRed)
+
+	// Style: diff bars and deltas for paths that shrank or were removed.
+	styleShrink = lipgloss.NewStyle().Foreground(colorGreen)
+
+	// Style: diff delta column (right-aligned).
+	styleDelta = lipgloss.NewStyle().
+			Width(10).
+			Align(lipgloss.Right)
+
+	// Style: names of paths that no longer exist in a comparison.
+	styleRemoved = lipgloss.NewStyle().
+			Foreground(colorGray).
+			Strikethrough(true)
+
+	// Style: the preview pane, ruled off from the list on its left.
+	stylePane = lipgloss.NewStyle().
+			Border(lipgloss.NormalBorder(), false, false, false, true).
+			BorderForeground(colorDim).
+			PaddingLeft(1)
+
+	// Style: field labels and the first lines of files in the preview pane.
+	stylePaneLabel = lipgloss.NewStyle().
+			Foreground(colorGray)
+
 	// Style: dim portion of the usage bar (cached to avoid per-frame allocs).
 	styleBarDim = lipgloss.NewStyle().Foreground(colorDim)
 )
@@ -124,12 +203,14 @@ var (
 // every row render, which would otherwise happen up to listHeight times per
 // frame.
 func barStyle(rank, total int) lipgloss.Style {
+	return barStyles[barIndex(rank, total)]
+}
+
+// barIndex maps an item's rank in a list of total items onto barColors,
+// from the first color for the largest to the last for the smallest.
+func barIndex(rank, total int) int {
 	if total <= 1 {
-		return barStyles[0]
-	}
-	idx := (rank * (len(barStyles) - 1)) / (total - 1)
-	if idx >= len(barStyles) {
-		idx = len(barStyles) - 1
+		return 0
 	}
-	return barStyles[idx]
+	return min((rank*(len(barColors)-1))/(total-1), len(barColors)-1)
 }
@@ -0,0 +1,390 @@
+package ui
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mobanhawi/aster/internal/format"
+	"github.com/mobanhawi/aster/internal/treemap"
+)
+
+// treemapMax caps the tiles laid out per directory; the smaller children
+// share one last tile, so directories of any size lay out instantly.
+const treemapMax = 200
+
+// treemapView is the squarified treemap shown in StateTreemap. It maps the
+// browser's current directory, so drilling in and out moves the browser too.
+type treemapView struct {
+	nested bool  // draw each directory's own children inside its tile
+	sel    *Node // selected child of the directory shown
+
+	// tiles is the layout for key, reused until key changes.
+	tiles []tile
+	key   treemapKey
+}
+
+// treemapKey is everything a layout depends on.
+type treemapKey struct {
+	dir           *Node
+	size          int64
+	children      int
+	width, height int
+	nested        bool
+	sizeMode      SizeMode
+	query         string        // the name filter, at every level
+	olderThan     time.Duration // the age filter's threshold, at every level
+}
+
+// tile is one rectangle of the map, covering the cells [x0, x1) × [y0, y1).
+type tile struct {
+	node           *Node // nil for the tile holding the remaining children
+	rest           int   // how many children the remainder tile holds
+	size           int64
+	x0, y0, x1, y1 int
+	kids           []tile // the node's own children, when nested
+}
+
+// startTreemap maps the current directory with the cursor's entry selected.
+func (m *Model) startTreemap() {
+	if m.currentDir() == nil {
+		return
+	}
+	m.treemap = &treemapView{sel: m.selected()}
+	m.state = StateTreemap
+}
+
+// treemapTiles returns the layout of the current directory for the current
+// terminal size, recomputing it only when something it depends on changed.
+// The selection is moved to the largest tile if it is no longer mapped.
+func (m *Model) treemapTiles() []tile {
+	v := m.treemap
+	dir := m.currentDir()
+	key := treemapKey{
+		dir: dir, size: m.nodeSize(dir), children: len(dir.Children),
+		width: m.width, height: m.listHeight(), nested: v.nested, sizeMode: m.sizeMode,
+	}
+	key.query, key.olderThan = m.activeFilters()
+	if key != v.key || v.tiles == nil {
+		v.key = key
+		v.tiles = m.layoutTiles(m.visibleChildren(), 0, 0, key.width, key.height, v.nested)
+	}
+	if v.tileOf(v.sel) < 0 {
+		v.sel = nil
+		if len(v.tiles) > 0 {
+			v.sel = v.tiles[0].node
+		}
+	}
+	return v.tiles
+}
+
+// layoutTiles lays out nodes, largest first, over the cells [x0, x1) ×
+// [y0, y1). Entries too small to get a cell are left out. With nested set,
+// directories are subdivided once more below their label row, showing only
+// the children the browser's filters let through, as at the top level.
+func (m *Model) layoutTiles(nodes []*Node, x0, y0, x1, y1 int, nested bool) []tile {
+	nodes = slices.DeleteFunc(slices.Clone(nodes), func(n *Node) bool { return m.nodeSize(n) <= 0 })
+	slices.SortStableFunc(nodes, func(a, b *Node) int { return cmp.Compare(m.nodeSize(b), m.nodeSize(a)) })
+
+	tiles := make([]tile, 0, min(len(nodes), treemapMax)+1)
+	for _, n := range nodes[:min(len(nodes), treemapMax)] {
+		tiles = append(tiles, tile{node: n, size: m.nodeSize(n)})
+	}
+	if len(nodes) > treemapMax {
+		rest := tile{rest: len(nodes) - treemapMax}
+		for _, n := range nodes[treemapMax:] {
+			rest.size += m.nodeSize(n)
+		}
+		tiles = append(tiles, rest)
+	}
+
+	// Terminal cells are about twice as tall as wide, so rows count double
+	// for the squares to look square.
+	weights := make([]float64, len(tiles))
+	for i, t := range tiles {
+		weights[i] = float64(t.size)
+	}
+	rects := treemap.Squarify(weights, treemap.Rect{
+		X: float64(x0), Y: float64(2 * y0), W: float64(x1 - x0), H: float64(2 * (y1 - y0)),
+	})
+	kept := tiles[:0]
+	for i, t := range tiles {
+		r := rects[i]
+		t.x0, t.x1 = round(r.X), round(r.X+r.W)
+		t.y0, t.y1 = round(r.Y/2), round((r.Y+r.H)/2)
+		if t.x0 == t.x1 || t.y0 == t.y1 {
+			continue
+		}
+		if nested && t.node != nil && t.node.IsDir {
+			bx1, by1 := t.body()
+			if bx1-t.x0 >= 2 && by1-(t.y0+1) >= 1 {
+				t.kids = m.layoutTiles(m.filterChildren(t.node.Children), t.x0, t.y0+1, bx1, by1, false)
+			}
+		}
+		kept = append(kept, t)
+	}
+	return kept
+}
+
+// round rounds a layout coordinate to a cell boundary. Neighbouring tiles
+// share their boundary coordinates, so they never overlap or leave gaps.
+func round(f float64) int {
+	return int(math.Round(f))
+}
+
+// body returns the end of t's cells without the one-cell gutter on its right
+// and bottom that separates it from its neighbours, when it is big enough to
+// spare one.
+func (t tile) body() (x1, y1 int) {
+	x1, y1 = t.x1, t.y1
+	if t.x1-t.x0 > 1 {
+		x1--
+	}
+	if t.y1-t.y0 > 1 {
+		y1--
+	}
+	return x1, y1
+}
+
+// tileOf returns the index of n's tile, or -1.
+func (v *treemapView) tileOf(n *Node) int {
+	if n == nil {
+		return -1
+	}
+	return slices.IndexFunc(v.tiles, func(t tile) bool { return t.node == n })
+}
+
+// move selects the nearest tile in the direction (dx, dy): preferably one
+// lying alongside the selected tile, then the closest, then the best
+// centred.
+func (v *treemapView) move(dx, dy int) {
+	cur := v.tileOf(v.sel)
+	if cur < 0 {
+		return
+	}
+	c := v.tiles[cur]
+	best, bestScore := -1, 0
+	for i, t := range v.tiles {
+		if t.node == nil || i == cur {
+			continue
+		}
+		// gap runs along the direction of travel; [lo, hi) and [clo, chi)
+		// are the two tiles' extents across it.
+		var gap, lo, hi, clo, chi int
+		switch {
+		case dx > 0:
+			gap, lo, hi, clo, chi = t.x0-c.x1, t.y0, t.y1, c.y0, c.y1
+		case dx < 0:
+			gap, lo, hi, clo, chi = c.x0-t.x1, t.y0, t.y1, c.y0, c.y1
+		case dy > 0:
+			gap, lo, hi, clo, chi = t.y0-c.y1, t.x0, t.x1, c.x0, c.x1
+		default:
+			gap, lo, hi, clo, chi = c.y0-t.y1, t.x0, t.x1, c.x0, c.x1
+		}
+		if gap < 0 {
+			continue
+		}
+		apart := max(lo-chi, clo-hi, 0)
+		offCentre := (lo + hi) - (clo + chi)
+		score := apart*1_000_000 + gap*1_000 + max(offCentre, -offCentre)
+		if best < 0 || score < bestScore {
+			best, bestScore = i, score
+		}
+	}
+	if best >= 0 {
+		v.sel = v.tiles[best].node
+	}
+}
+
+func (m Model) handleKeyTreemap(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	v := m.treemap
+	m.treemapTiles()
+	switch msg.String() {
+	case "ctrl+c", "q":
+		return m, tea.Quit
+	case "left", "h":
+		v.move(-1, 0)
+	case "right", "l":
+		v.move(1, 0)
+	case "up", "k":
+		v.move(0, -1)
+	case "down", "j":
+		v.move(0, 1)
+	case "enter":
+		if v.sel != nil && v.sel.IsDir && len(v.sel.Children) > 0 {
+			m.stack = append(m.stack, v.sel)
+			v.sel = nil
+		}
+	case "backspace":
+		if len(m.stack) > 0 {
+			v.sel = m.currentDir()
+			m.stack = m.stack[:len(m.stack)-1]
+		}
+	case "tab":
+		v.nested = !v.nested
+	case "esc":
+		m.treemap = nil
+		m.state = StateBrowsing
+		m.cursor = 0
+		m.reselect(v.sel)
+	}
+	return m, nil
+}
+
+// viewTreemap renders the current directory as a treemap filling the list
+// area, each child a block of the bar colour for its rank.
+func (m Model) viewTreemap() string {
+	v := m.treemap
+	tiles := m.treemapTiles()
+	dir := m.currentDir()
+
+	c := newCanvas(m.width, m.listHeight())
+	for i, t := range tiles {
+		m.drawTile(c, t, barIndex(i, len(tiles)), t.node != nil && t.node == v.sel)
+	}
+	lines := c.lines()
+
+	status := " " + itoa(len(dir.Children)) + " items  total: " + format.Bytes(m.nodeSize(dir))
+	if n := v.sel; n != nil {
+		sz := m.nodeSize(n)
+		status += "  ▶ " + n.Name + "  " + format.Bytes(sz) + " (" + itoa(int(share(sz, m.nodeSize(dir))*100)) + "%)"
+	}
+	layout, other := "flat", "nested"
+	if v.nested {
+		layout, other = other, layout
+	}
+	return m.viewList(listView{
+		title:    "treemap",
+		subtitle: dir.FullPath(),
+		total:    len(lines),
+		row:      func(i int) string { return lines[i] },
+		status:   status,
+		right:    "layout: " + layout,
+		hints: keyHint("←↑↓→/hjkl", "move") + keyHint("enter", "open") + keyHint("bsp", "up") +
+			keyHint("tab", other) + keyHint("esc", "list") + keyHint("q", "quit"),
+	})
+}
+
+// drawTile draws t in the bar colour at index color: a label row, then a
+// solid block, or the tiles of its children when nested. The selected tile
+// is labelled in reverse and shaded lighter.
+func (m Model) drawTile(c *canvas, t tile, color int, selected bool) {
+	fill := barStyles[color]
+	label := tileLabelStyles[color]
+	if t.node == nil {
+		fill = styleBarDim
+		label = lipgloss.NewStyle().Foreground(colorWhite).Background(colorDim)
+	}
+	if selected {
+		label = styleTileSelected
+	}
+	block := '█'
+	if selected {
+		block = '▓'
+	}
+
+	x1, y1 := t.body()
+	fillID := c.style(fill)
+	if t.kids != nil {
+		c.fill(t.x0, t.y0, x1, y1, '░', fillID)
+		for _, k := range t.kids {
+			kx1, ky1 := k.body()
+			c.fill(k.x0, k.y0, kx1, ky1, block, fillID)
+		}
+	} else {
+		c.fill(t.x0, t.y0, x1, y1, block, fillID)
+	}
+
+	text := "+" + itoa(t.rest) + " more"
+	if t.node != nil {
+		text = t.node.Name + " " + format.Bytes(t.size)
+	}
+	w := x1 - t.x0
+	if w >= 3 {
+		c.text(t.x0, t.y0, w, " "+truncate(text, w-1), c.style(label))
+	}
+}
+
+// canvas is a grid of styled cells, rendered line by line with one lipgloss
+// call per run of equally styled cells.
+type canvas struct {
+	cells  [][]canvasCell
+	styles []lipgloss.Style // styles[0] is unstyled
+}
+
+// canvasCell is one character cell and the index of its style.
+type canvasCell struct {
+	ch    rune
+	style int
+}
+
+func newCanvas(width, height int) *canvas {
+	c := &canvas{cells: make([][]canvasCell, height), styles: []lipgloss.Style{{}}}
+	for y := range c.cells {
+		c.cells[y] = slices.Repeat([]canvasCell{{ch: ' '}}, width)
+	}
+	return c
+}
+
+// style registers s and returns its index.
+func (c *canvas) style(s lipgloss.Style) int {
+	c.styles = append(c.styles, s)
+	return len(c.styles) - 1
+}
+
+// fill sets the cells [x0, x1) × [y0, y1) to ch in the given style.
+func (c *canvas) fill(x0, y0, x1, y1 int, ch rune, style int) {
+	for y := max(y0, 0); y < min(y1, len(c.cells)); y++ {
+		row := c.cells[y]
+		for x := max(x0, 0); x < min(x1, len(row)); x++ {
+			row[x] = canvasCell{ch: ch, style: style}
+		}
+	}
+}
+
+// text writes s from (x, y) padded with spaces to width cells, in the given
+// style. Each rune takes one cell.
+func (c *canvas) text(x, y, width int, s string, style int) {
+	if y < 0 || y >= len(c.cells) {
+		return
+	}
+	runes := []rune(s)
+	row := c.cells[y]
+	for i := range width {
+		if x+i < 0 || x+i >= len(row) {
+			continue
+		}
+		ch := ' '
+		if i < len(runes) {
+			ch = runes[i]
+		}
+		row[x+i] = canvasCell{ch: ch, style: style}
+	}
+}
+
+// lines renders the canvas.
+func (c *canvas) lines() []string {
+	out := make([]string, len(c.cells))
+	var sb, run strings.Builder
+	for y, row := range c.cells {
+		sb.Reset()
+		for x := 0; x < len(row); {
+			style := row[x].style
+			run.Reset()
+			for ; x < len(row) && row[x].style == style; x++ {
+				run.WriteRune(row[x].ch)
+			}
+			if style == 0 {
+				sb.WriteString(run.String())
+			} else {
+				sb.WriteString(c.styles[style].Render(run.String()))
+			}
+		}
+		out[y] = sb.String()
+	}
+	return out
+}
@@ -0,0 +1,155 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestTreemapView(t *testing.T) {
+	key := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+	press := func(m Model, keys ...tea.KeyMsg) Model {
+		for _, k := range keys {
+			next, _ := m.Update(k)
+			m = next.(Model)
+		}
+		return m
+	}
+	newTree := func() *Node {
+		return nodeWithSize("root", true, 1000,
+			nodeWithSize("a", true, 600,
+				nodeWithSize("b", false, 400),
+				nodeWithSize("c", false, 200),
+			),
+			nodeWithSize("x", false, 300),
+			nodeWithSize("y", false, 100),
+			nodeWithSize("empty", false, 0),
+		)
+	}
+	selName := func(m Model) string {
+		if m.treemap.sel == nil {
+			return ""
+		}
+		return m.treemap.sel.Name
+	}
+
+	t.Run("GivenDirectory_WhenTPressed_ThenTilesCoverTheListArea", func(t *testing.T) {
+		m := press(browsingModel(newTree()), key("T"))
+		if m.state != StateTreemap || selName(m) != "a" {
+			t.Fatalf("state %v, selected %q, want the treemap with a selected", m.state, selName(m))
+		}
+		tiles := m.treemapTiles()
+		if len(tiles) != 3 {
+			t.Fatalf("%d tiles, want 3 (the empty file gets none)", len(tiles))
+		}
+		area := 0
+		for i, a := range tiles {
+			area += (a.x1 - a.x0) * (a.y1 - a.y0)
+			for _, b := range tiles[:i] {
+				if a.x0 < b.x1 && b.x0 < a.x1 && a.y0 < b.y1 && b.y0 < a.y1 {
+					t.Errorf("tiles %s and %s overlap", a.node.Name, b.node.Name)
+				}
+			}
+		}
+		if want := m.width * m.listHeight(); area != want {
+			t.Errorf("tiles cover %d cells, want %d", area, want)
+		}
+		if view := m.View(); !strings.Contains(view, "a 600 B") || !strings.Contains(view, "x 300 B") {
+			t.Error("view should label the tiles with name and size")
+		}
+	})
+
+	t.Run("GivenSelection_WhenArrowsPressed_ThenNeighbourSelected", func(t *testing.T) {
+		m := press(browsingModel(newTree()), key("T"), key("l"))
+		if selName(m) != "x" {
+			t.Errorf("right of a selected %q, want x", selName(m))
+		}
+		m = press(m, key("j"))
+		if selName(m) != "y" {
+			t.Errorf("below x selected %q, want y", selName(m))
+		}
+		m = press(m, key("h"))
+		if selName(m) != "a" {
+			t.Errorf("left of y selected %q, want a", selName(m))
+		}
+		if m = press(m, key("h")); selName(m) != "a" {
+			t.Errorf("nothing left of a, but selected %q", selName(m))
+		}
+	})
+
+	t.Run("GivenDirectory_WhenEnterThenBackspace_ThenDrilledInAndOut", func(t *testing.T) {
+		m := press(browsingModel(newTree()), key("T"), tea.KeyMsg{Type: tea.KeyEnter})
+		if len(m.stack) != 1 || m.currentDir().Name != "a" || selName(m) != "" {
+			t.Fatalf("stack %d deep at %s, want a with nothing selected yet", len(m.stack), m.currentDir().Name)
+		}
+		m.View()
+		if selName(m) != "b" {
+			t.Errorf("selected %q, want the largest tile b", selName(m))
+		}
+		m = press(m, tea.KeyMsg{Type: tea.KeyBackspace})
+		if len(m.stack) != 0 || selName(m) != "a" {
+			t.Errorf("stack %d deep, selected %q, want root with a selected", len(m.stack), selName(m))
+		}
+	})
+
+	t.Run("GivenSelection_WhenEscPressed_ThenBrowserSelectsIt", func(t *testing.T) {
+		m := press(browsingModel(newTree()), key("T"), key("l"), tea.KeyMsg{Type: tea.KeyEsc})
+		if m.state != StateBrowsing || m.treemap != nil {
+			t.Fatalf("state %v, want browsing", m.state)
+		}
+		if sel := m.selected(); sel == nil || sel.Name != "x" {
+			t.Errorf("selected %v, want x", sel)
+		}
+	})
+
+	t.Run("GivenNestedLayout_WhenTabPressed_ThenDirectoriesHoldTheirChildren", func(t *testing.T) {
+		m := press(browsingModel(newTree()), key("T"), tea.KeyMsg{Type: tea.KeyTab})
+		tiles := m.treemapTiles()
+		if len(tiles[0].kids) != 2 || tiles[1].kids != nil {
+			t.Errorf("a has %d kids, x has %d; want 2 and none", len(tiles[0].kids), len(tiles[1].kids))
+		}
+		for _, k := range tiles[0].kids {
+			if k.x0 < tiles[0].x0 || k.x1 > tiles[0].x1 || k.y0 <= tiles[0].y0 || k.y1 > tiles[0].y1 {
+				t.Errorf("kid %s lies outside a, below its label", k.node.Name)
+			}
+		}
+	})
+
+	t.Run("GivenNameFilter_WhenNested_ThenEveryLevelFiltered", func(t *testing.T) {
+		tree := nodeWithSize("root", true, 1000,
+			nodeWithSize("docs", true, 600,
+				nodeWithSize("doc.txt", false, 400),
+				nodeWithSize("img.png", false, 200),
+			),
+			nodeWithSize("x", false, 400),
+		)
+		m := press(browsingModel(tree), key("T"), tea.KeyMsg{Type: tea.KeyTab})
+		if tiles := m.treemapTiles(); len(tiles) != 2 || len(tiles[0].kids) != 2 {
+			t.Fatalf("%d tiles, %d kids in docs; want 2 and 2 unfiltered", len(tiles), len(tiles[0].kids))
+		}
+		m.filtering = true
+		m.filter.SetValue("o")
+		tiles := m.treemapTiles()
+		if len(tiles) != 1 || tiles[0].node.Name != "docs" {
+			t.Fatalf("%d tiles, want only docs to match", len(tiles))
+		}
+		if kids := tiles[0].kids; len(kids) != 1 || kids[0].node.Name != "doc.txt" {
+			t.Errorf("docs holds %d kids, want only doc.txt to match", len(kids))
+		}
+	})
+
+	t.Run("GivenManyChildren_WhenMapped_ThenSmallestShareOneTile", func(t *testing.T) {
+		var kids []*Node
+		for i := range treemapMax + 5 {
+			kids = append(kids, nodeWithSize("f"+itoa(i), false, 1))
+		}
+		m := press(browsingModel(nodeWithSize("root", true, int64(len(kids)), kids...)), key("T"))
+		m.width, m.height = 400, 200
+		tiles := m.treemapTiles()
+		last := tiles[len(tiles)-1]
+		if len(tiles) != treemapMax+1 || last.node != nil || last.rest != 5 || last.size != 5 {
+			t.Errorf("%d tiles, last %+v; want %d with the last holding 5", len(tiles), last, treemapMax+1)
+		}
+	})
+}
@@ -0,0 +1,173 @@
+package ui
+
+import (
+	"cmp"
+	"path/filepath"
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mobanhawi/aster/internal/filetype"
+	"github.com/mobanhawi/aster/internal/format"
+)
+
+// typesView is the file type breakdown shown in StateTypes.
+type typesView struct {
+	scope    *Node // the directory totalled
+	grouping filetype.Grouping
+	stats    []filetype.Stat
+	cursor   int
+
+	// files lists the files of stats[cursor] once drilled into; nil before.
+	files      []*Node
+	fileCursor int
+}
+
+// startTypes totals the current directory by file extension.
+func (m *Model) startTypes() {
+	if m.diff != nil {
+		m.notice = "The type breakdown is not available when comparing scans"
+		return
+	}
+	scope := m.currentDir()
+	if scope == nil {
+		return
+	}
+	m.types = &typesView{scope: scope}
+	m.aggregateTypes(filetype.ByExtension)
+	m.state = StateTypes
+}
+
+// aggregateTypes (re)totals the scope by g, ordered by the size in use.
+func (m *Model) aggregateTypes(g filetype.Grouping) {
+	v := m.types
+	v.grouping = g
+	v.stats = filetype.Aggregate(v.scope, g)
+	slices.SortStableFunc(v.stats, func(a, b filetype.Stat) int {
+		return cmp.Compare(m.statSize(b), m.statSize(a))
+	})
+	v.cursor = 0
+}
+
+// statSize is the apparent or allocated total of s, per m.sizeMode.
+func (m *Model) statSize(s filetype.Stat) int64 {
+	if m.sizeMode == SizeAllocated {
+		return s.Usage
+	}
+	return s.Size
+}
+
+// drillIntoType lists the files of the selected type, largest first.
+func (m *Model) drillIntoType() {
+	v := m.types
+	if len(v.stats) == 0 {
+		return
+	}
+	v.files = filetype.Files(v.scope, v.grouping, v.stats[v.cursor].Key)
+	slices.SortStableFunc(v.files, func(a, b *Node) int {
+		return cmp.Compare(m.nodeSize(b), m.nodeSize(a))
+	})
+	v.fileCursor = 0
+}
+
+func (m Model) handleKeyTypes(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	v := m.types
+	cursor, total := &v.cursor, len(v.stats)
+	if v.files != nil {
+		cursor, total = &v.fileCursor, len(v.files)
+	}
+
+	switch msg.String() {
+	case "ctrl+c", "q":
+		return m, tea.Quit
+	case "up", "k":
+		*cursor = max(*cursor-1, 0)
+	case "down", "j":
+		*cursor = max(min(*cursor+1, total-1), 0)
+	case "g", "home":
+		*cursor = 0
+	case "G", "end":
+		*cursor = max(total-1, 0)
+	case "right", "enter", "l":
+		if v.files == nil {
+			m.drillIntoType()
+		}
+	case "tab":
+		if v.files == nil {
+			if v.grouping == filetype.ByExtension {
+				m.aggregateTypes(filetype.ByCategory)
+			} else {
+				m.aggregateTypes(filetype.ByExtension)
+			}
+		}
+	case "esc", "left", "backspace", "h":
+		if v.files != nil {
+			v.files = nil
+		} else {
+			m.types = nil
+			m.state = StateBrowsing
+		}
+	}
+	return m, nil
+}
+
+// viewTypes renders the breakdown, or the files of one type once drilled
+// into.
+func (m Model) viewTypes() string {
+	v := m.types
+	if v.files != nil {
+		return m.viewTypeFiles()
+	}
+
+	var total int64
+	for _, s := range v.stats {
+		total += m.statSize(s)
+	}
+	kind, other := "extensions", "categories"
+	if v.grouping == filetype.ByCategory {
+		kind, other = other, kind
+	}
+	return m.viewList(listView{
+		title:    "types",
+		subtitle: v.scope.FullPath(),
+		total:    len(v.stats),
+		cursor:   v.cursor,
+		row: func(i int) string {
+			s := v.stats[i]
+			label := s.Key + "  (" + itoa(s.Files) + " files)"
+			if s.Files == 1 {
+				label = s.Key + "  (1 file)"
+			}
+			sz := m.statSize(s)
+			return m.renderTotalRow(label, styleDir, sz, share(sz, total), barStyle(i, len(v.stats)), i == v.cursor)
+		},
+		status: " " + itoa(len(v.stats)) + " " + kind + "  total: " + format.Bytes(total),
+		hints: keyHint("↑↓/jk", "move") + keyHint("→/enter", "files") + keyHint("tab", other) +
+			keyHint("esc", "back") + keyHint("q", "quit"),
+	})
+}
+
+// viewTypeFiles renders the files of the selected type, each with its share
+// of the type's total.
+func (m Model) viewTypeFiles() string {
+	v := m.types
+	stat := v.stats[v.cursor]
+	total := m.statSize(stat)
+	root := v.scope.FullPath()
+	return m.viewList(listView{
+		title:    "types",
+		subtitle: root + " › " + stat.Key,
+		total:    len(v.files),
+		cursor:   v.fileCursor,
+		row: func(i int) string {
+			n := v.files[i]
+			label := n.FullPath()
+			if rel, err := filepath.Rel(root, label); err == nil {
+				label = rel
+			}
+			sz := m.nodeSize(n)
+			return m.renderTotalRow(label, styleRow, sz, share(sz, total), barStyle(i, len(v.files)), i == v.fileCursor)
+		},
+		status: " " + itoa(len(v.files)) + " files  total: " + format.Bytes(total),
+		hints:  keyHint("↑↓/jk", "move") + keyHint("←/esc", "back") + keyHint("q", "quit"),
+	})
+}
@@ -0,0 +1,85 @@
+package ui
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestTypesView(t *testing.T) {
+	key := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+	press := func(m Model, keys ...tea.KeyMsg) Model {
+		for _, k := range keys {
+			next, _ := m.Update(k)
+			m = next.(Model)
+		}
+		return m
+	}
+	newTree := func() *Node {
+		return nodeWithSize("root", true, 1000,
+			nodeWithSize("media", true, 700,
+				nodeWithSize("a.mp4", false, 400),
+				nodeWithSize("b.mkv", false, 200),
+				nodeWithSize("c.mp4", false, 100),
+			),
+			nodeWithSize("main.o", false, 300),
+		)
+	}
+
+	t.Run("GivenTree_WhenTPressed_ThenExtensionsListedLargestFirst", func(t *testing.T) {
+		m := press(browsingModel(newTree()), key("t"))
+		if m.state != StateTypes {
+			t.Fatalf("state = %v, want StateTypes", m.state)
+		}
+		var keys []string
+		for _, s := range m.types.stats {
+			keys = append(keys, s.Key)
+		}
+		if want := []string{".mp4", ".o", ".mkv"}; !slices.Equal(keys, want) {
+			t.Errorf("keys = %v, want %v", keys, want)
+		}
+		view := m.View()
+		for _, want := range []string{".mp4  (2 files)", "50%", "3 extensions  total: 1.0 kB"} {
+			if !strings.Contains(view, want) {
+				t.Errorf("view missing %q", want)
+			}
+		}
+	})
+
+	t.Run("GivenExtensions_WhenTabPressed_ThenCategoriesCombineThem", func(t *testing.T) {
+		m := press(browsingModel(newTree()), key("t"), tea.KeyMsg{Type: tea.KeyTab})
+		if s := m.types.stats[0]; s.Key != "video" || s.Size != 700 || s.Files != 3 {
+			t.Errorf("top category = %+v, want video with 700 B in 3 files", s)
+		}
+		if !strings.Contains(m.View(), "build artefacts") {
+			t.Error("view should list build artefacts")
+		}
+	})
+
+	t.Run("GivenType_WhenEntered_ThenMatchingFilesListedAndEscReturns", func(t *testing.T) {
+		m := press(browsingModel(newTree()), key("t"), key("l"))
+		if len(m.types.files) != 2 || m.types.files[0].Name != "a.mp4" {
+			t.Fatalf("files = %v, want a.mp4 then c.mp4", m.types.files)
+		}
+		if view := m.View(); !strings.Contains(view, "media/a.mp4") || !strings.Contains(view, "80%") {
+			t.Error("file rows should show the path below the scope and the share of the type")
+		}
+		m = press(m, tea.KeyMsg{Type: tea.KeyEsc})
+		if m.state != StateTypes || m.types.files != nil {
+			t.Errorf("esc should return to the breakdown, state %v", m.state)
+		}
+		m = press(m, tea.KeyMsg{Type: tea.KeyEsc})
+		if m.state != StateBrowsing || m.types != nil {
+			t.Errorf("second esc should return to the browser, state %v", m.state)
+		}
+	})
+
+	t.Run("GivenSubdirectory_WhenTPressed_ThenOnlyItIsTotalled", func(t *testing.T) {
+		m := press(browsingModel(newTree()), key("l"), key("t"))
+		if len(m.types.stats) != 2 || m.types.scope.Name != "media" {
+			t.Errorf("stats = %+v for %s, want two extensions in media", m.types.stats, m.types.scope.Name)
+		}
+	})
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mobanhawi/aster/internal/scanner"
 )
 
 func TestModelNewInit(t *testing.T) {
@@ -25,7 +26,7 @@ func TestModelNewInit(t *testing.T) {
 	// Test startScan cmd manually
 	var counter atomic.Int64
 	pCh := make(chan int64, 16)
-	sCmd := startScan("/invalid/path/that/does/not/exist/1234", pCh, &counter)
+	sCmd := startScan("/invalid/path/that/does/not/exist/1234", pCh, &counter, scanner.Options{})
 	msg := sCmd()
 	if _, ok := msg.(scanDoneMsg); !ok {
 		t.Errorf("expected scanDoneMsg, got %T", msg)
@@ -50,7 +51,7 @@ func TestSortNode(t *testing.T) {
 	const modeSize = int8(0) // SortBySize = 0
 	const modeName = int8(1) // SortByName = 1
 
-	sortNode(root, SortBySize)
+	sortNode(root, SortBySize, SizeApparent)
 	root.MarkSorted(gen0, modeSize)
 	if root.Children[0].Name != "a" {
 		t.Errorf("expected 'a' to be sorted first by size")
@@ -61,7 +62,7 @@ func TestSortNode(t *testing.T) {
 	if root.IsSorted(gen1, modeName) {
 		t.Errorf("expected node to be stale after generation advance")
 	}
-	sortNode(root, SortByName)
+	sortNode(root, SortByName, SizeApparent)
 	root.MarkSorted(gen1, modeName)
 	if root.Children[0].Name != "a" {
 		t.Errorf("expected 'a' to be sorted first by name")
@@ -184,6 +185,14 @@ func TestModelUpdateKeys(t *testing.T) {
 		t.Errorf("expected sort toggle to SortByName")
 	}
 	m2, _ = m2.(Model).Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	if m2.(Model).sort != SortByAge {
+		t.Errorf("expected sort toggle to SortByAge")
+	}
+	m2, _ = m2.(Model).Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	if m2.(Model).sort != SortByCount {
+		t.Errorf("expected sort toggle to SortByCount")
+	}
+	m2, _ = m2.(Model).Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
 	if m2.(Model).sort != SortBySize {
 		t.Errorf("expected sort toggle to SortBySize")
 	}
@@ -303,6 +312,32 @@ func TestModelView(t *testing.T) {
 		t.Errorf("expected View() output for small window")
 	}
 
+	// Hardlinked duplicates render a "shared" marker instead of a size
+	root.Children[1].IsHardlink = true
+	out = m.View()
+	if !strings.Contains(out, "shared") {
+		t.Errorf("expected View() output to mark hardlinked duplicates as shared")
+	}
+	root.Children[1].IsHardlink = false
+
+	// Symlinks show where they point
+	root.Children[1].LinkTarget = "/elsewhere/file1"
+	out = m.View()
+	if !strings.Contains(out, "→ /elsewhere/file1") {
+		t.Errorf("expected View() output to show the symlink target")
+	}
+	root.Children[1].LinkTarget = ""
+
+	// Mount points skipped by --one-file-system get their own marker
+	root.Children[0].IsMount = true
+	m.cursor = 0
+	out = m.View()
+	if !strings.Contains(out, "mount") {
+		t.Errorf("expected View() output to mark mount points")
+	}
+	root.Children[0].IsMount = false
+	m.cursor = 1
+
 	// View with confirm delete
 	m.state = StateConfirmDelete
 	m.confirmPath = "sys/file1"
@@ -335,9 +370,9 @@ func TestModelActions(t *testing.T) {
 	}()
 
 	trashed := ""
-	trashItem = func(path string) error {
+	trashItem = func(path string) (string, error) {
 		trashed = path
-		return nil
+		return "/trash/" + path, nil
 	}
 
 	opened := ""
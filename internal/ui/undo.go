@@ -0,0 +1,140 @@
+package ui
+
+import (
+	"path/filepath"
+	"slices"
+	"strconv"
+
+	"github.com/mobanhawi/aster/internal/format"
+)
+
+// deletion records a trashed item so it can be put back.
+type deletion struct {
+	path    string // where the item lived
+	trashed string // where the trash put it
+	node    *Node  // the detached subtree, sizes intact
+	parent  *Node  // the directory it was removed from
+	size    int64  // apparent size at deletion time, for the notice
+}
+
+// trashNode moves node to the trash and detaches it from the tree, returning
+// the record needed to undo it.
+func (m *Model) trashNode(node *Node) (deletion, error) {
+	path := node.FullPath()
+	trashed, err := trashItem(path)
+	if err != nil {
+		return deletion{}, err
+	}
+	parent := node.Parent
+	parent.RemoveChild(node)
+	m.unmarkUnder(node)
+	return deletion{path: path, trashed: trashed, node: node, parent: parent, size: node.Size()}, nil
+}
+
+// startDelete asks to confirm trashing the marked items or, with none
+// marked, the selected one. A comparison shows no live tree to delete from.
+func (m *Model) startDelete() {
+	if m.diff != nil {
+		m.notice = "Deleting is not available when comparing scans"
+		return
+	}
+	if len(m.marked) > 0 {
+		m.state = StateConfirmDelete // batch: confirmPath stays empty
+	} else if sel := m.selected(); sel != nil {
+		m.state = StateConfirmDelete
+		m.confirmPath = sel.FullPath()
+	}
+}
+
+// deleteConfirmed trashes the item at m.confirmPath and pushes it onto the
+// undo stack.
+func (m *Model) deleteConfirmed() {
+	parent := m.currentDir()
+	if parent == nil {
+		return
+	}
+	var target *Node
+	for _, c := range parent.Children {
+		if c.FullPath() == m.confirmPath {
+			target = c
+			break
+		}
+	}
+	if target == nil {
+		return
+	}
+
+	d, err := m.trashNode(target)
+	if err != nil {
+		m.notice = "Delete failed: " + err.Error()
+		return
+	}
+	m.notice = "Trashed " + target.Name + " (" + format.Bytes(d.size) + ")" + m.pushUndo([]deletion{d})
+	m.clampCursor()
+}
+
+// pushUndo records step as one undoable action and returns the hint for the
+// notice. Items the trash couldn't locate after moving them are left out, as
+// they can't be restored; nothing is pushed when that leaves none.
+func (m *Model) pushUndo(step []deletion) string {
+	step = slices.DeleteFunc(step, func(d deletion) bool { return d.trashed == "" })
+	if len(step) == 0 {
+		return ""
+	}
+	m.undo = append(m.undo, step)
+	return " — u to undo"
+}
+
+// undoDelete restores the most recent delete action from the trash and
+// reattaches each subtree, re-adding its size to every ancestor. Items that
+// fail to restore stay on the stack so the undo can be retried.
+func (m *Model) undoDelete() {
+	if m.diff != nil {
+		m.notice = "Undo is not available when comparing scans"
+		return
+	}
+	if len(m.undo) == 0 {
+		m.notice = "Nothing to undo"
+		return
+	}
+	step := m.undo[len(m.undo)-1]
+	var failed []deletion
+	var restored int
+	var restoredSize int64
+	var firstErr error
+	for _, d := range step {
+		if err := restoreItem(d.trashed, d.path); err != nil {
+			failed = append(failed, d)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		// A rescan may have replaced the parent's subtree since; the item is
+		// back on disk either way and the next rescan will list it.
+		if attached(m.root, d.parent) {
+			d.parent.AddChild(d.node)
+		}
+		restored++
+		restoredSize += d.size
+	}
+
+	if len(failed) > 0 {
+		m.undo[len(m.undo)-1] = failed
+	} else {
+		m.undo = m.undo[:len(m.undo)-1]
+	}
+
+	switch {
+	case restored == 0:
+		m.notice = "Undo failed: " + firstErr.Error()
+	case len(step) == 1:
+		m.notice = "Restored " + filepath.Base(step[0].path) + " (" + format.Bytes(restoredSize) + ")"
+	default:
+		m.notice = "Restored " + strconv.Itoa(restored) + " items (" + format.Bytes(restoredSize) + ")"
+		if firstErr != nil {
+			m.notice += "; " + strconv.Itoa(len(failed)) + " failed: " + firstErr.Error()
+		}
+	}
+	m.clampCursor()
+}
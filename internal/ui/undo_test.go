@@ -0,0 +1,92 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestUndoDelete(t *testing.T) {
+	oldTrash, oldRestore := trashItem, restoreItem
+	t.Cleanup(func() { trashItem, restoreItem = oldTrash, oldRestore })
+	trashItem = func(path string) (string, error) { return "/trash/" + path, nil }
+
+	press := func(m Model, keys ...string) Model {
+		for _, k := range keys {
+			next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)})
+			m = next.(Model)
+		}
+		return m
+	}
+	newTree := func() *Node {
+		return nodeWithSize("root", true, 600,
+			nodeWithSize("sub", true, 600,
+				nodeWithSize("fileA.txt", false, 500),
+				nodeWithSize("fileB.txt", false, 100),
+			),
+		)
+	}
+
+	t.Run("GivenDeletedItem_WhenUPressed_ThenRestoredAndSizesReadded", func(t *testing.T) {
+		var restored [2]string
+		restoreItem = func(trashed, original string) error {
+			restored = [2]string{trashed, original}
+			return nil
+		}
+		root := newTree()
+		m := press(browsingModel(root), "l", "d", "y")
+		if root.Size() != 100 || len(m.undo) != 1 || !strings.Contains(m.notice, "fileA.txt") {
+			t.Fatalf("after delete: root %d, undo %d, notice %q", root.Size(), len(m.undo), m.notice)
+		}
+
+		m = press(m, "u")
+		if restored != [2]string{"/trash/root/sub/fileA.txt", "root/sub/fileA.txt"} {
+			t.Errorf("restoreItem called with %v", restored)
+		}
+		if root.Size() != 600 || root.Children[0].Size() != 600 || len(m.undo) != 0 {
+			t.Errorf("after undo: root %d, sub %d, undo %d", root.Size(), root.Children[0].Size(), len(m.undo))
+		}
+		if names := m.visibleChildren(); len(names) != 2 || names[0].Name != "fileA.txt" {
+			t.Errorf("restored item not listed first by size: %v", names)
+		}
+		if !strings.Contains(m.notice, "Restored fileA.txt") || !strings.Contains(m.View(), "Restored fileA.txt") {
+			t.Errorf("notice = %q, want a restore notice in the status bar", m.notice)
+		}
+	})
+
+	t.Run("GivenRestoreFails_WhenUPressed_ThenEntryKeptForRetry", func(t *testing.T) {
+		restoreItem = func(string, string) error { return errors.New("occupied") }
+		root := newTree()
+		m := press(browsingModel(root), "l", "d", "y", "u")
+		if len(m.undo) != 1 || root.Size() != 100 || !strings.Contains(m.notice, "Undo failed: occupied") {
+			t.Errorf("undo %d, root %d, notice %q", len(m.undo), root.Size(), m.notice)
+		}
+	})
+
+	t.Run("GivenNothingDeleted_WhenUPressed_ThenNotice", func(t *testing.T) {
+		m := press(browsingModel(newTree()), "u")
+		if m.notice != "Nothing to undo" {
+			t.Errorf("notice = %q, want Nothing to undo", m.notice)
+		}
+	})
+
+	t.Run("GivenTrashFails_WhenConfirmed_ThenTreeUntouched", func(t *testing.T) {
+		trashItem = func(string) (string, error) { return "", errors.New("denied") }
+		root := newTree()
+		m := press(browsingModel(root), "d", "y")
+		if root.Size() != 600 || len(m.undo) != 0 || !strings.Contains(m.notice, "Delete failed: denied") {
+			t.Errorf("root %d, undo %d, notice %q", root.Size(), len(m.undo), m.notice)
+		}
+	})
+
+	t.Run("GivenTrashCannotLocateItem_WhenConfirmed_ThenTrashedWithoutUndo", func(t *testing.T) {
+		trashItem = func(string) (string, error) { return "", nil }
+		root := newTree()
+		m := press(browsingModel(root), "d", "y")
+		if root.Size() != 0 || len(m.undo) != 0 || m.notice != "Trashed sub (600 B)" {
+			t.Errorf("root %d, undo %d, notice %q", root.Size(), len(m.undo), m.notice)
+		}
+	})
+}
@@ -2,17 +2,28 @@ package ui
 
 import (
 	"context"
-	"fmt"
 	"os/exec"
 	"path/filepath"
 	"time"
 
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mobanhawi/aster/internal/trash"
 )
 
-// Update implements tea.Model.
+// Update implements tea.Model. Whatever msg does, the preview pane then
+// follows the selection.
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.update(msg)
+	nm, ok := next.(Model)
+	if !ok || !nm.showPreview {
+		return next, cmd
+	}
+	load := nm.loadPreview()
+	return nm, tea.Batch(cmd, load)
+}
+
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
@@ -30,19 +41,42 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.scanErr = msg.err
 			return m, nil
 		}
-		m.root = msg.root
-		m.state = StateBrowsing
-		m.cursor = 0
-		m.stack = nil
-		// Cache the resolved absolute path so breadcrumb() avoids calling
-		// filepath.Abs on every render frame.
-		if msg.root != nil {
-			m.absRoot = msg.root.Name
-			// Mark the root as already sorted (startScan sorted it eagerly).
-			m.markRootSorted()
+		// startScan sorted the root eagerly, so browse can mark it sorted.
+		m.browse(msg.root)
+		if m.watchMode {
+			return m, m.startWatch()
 		}
 		return m, nil
 
+	case rescanDoneMsg:
+		return m, m.finishRescan(msg)
+
+	case watchStartedMsg:
+		return m, m.watchStarted(msg)
+
+	case watchFailedMsg:
+		return m, m.watchFailed(msg)
+
+	case watchBatchMsg:
+		return m, m.applyWatchBatch(msg.batch)
+
+	case levelReadMsg:
+		return m, m.finishLevelRead(msg)
+
+	case previewMsg:
+		m.finishPreview(msg)
+		return m, nil
+
+	case searchStepMsg:
+		return m, m.searchStep(msg)
+
+	case dupesDoneMsg:
+		m.finishDupes(msg)
+		return m, nil
+
+	case pollTickMsg:
+		return m, tea.Batch(m.rescan(m.root, true), pollTick())
+
 	case purgeableSpaceMsg:
 		m.purgeableSpace = msg.space
 		m.purgeableString = msg.str
@@ -51,9 +85,20 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case tea.KeyMsg:
 		return m.handleKey(msg)
+
+	case tea.MouseMsg:
+		return m.handleMouse(msg)
 	}
 
-	return m, nil
+	// Anything else is for the text input with focus, if any: cursor blinks.
+	var cmd tea.Cmd
+	switch {
+	case m.filtering:
+		m.filter, cmd = m.filter.Update(msg)
+	case m.state == StateSearch && m.search.editing:
+		m.search.input, cmd = m.search.input.Update(msg)
+	}
+	return m, cmd
 }
 
 func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
@@ -64,6 +109,20 @@ func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m.handleKeyConfirmDelete(msg)
 	case StateBrowsing:
 		return m.handleKeyBrowsing(msg)
+	case StateDuplicates:
+		return m.handleKeyDupes(msg)
+	case StateTypes:
+		return m.handleKeyTypes(msg)
+	case StateLargest:
+		return m.handleKeyLargest(msg)
+	case StateSearch:
+		return m.handleKeySearch(msg)
+	case StateTreemap:
+		return m.handleKeyTreemap(msg)
+	case StateHelp:
+		return m.handleKeyHelp(msg)
+	case StateFailures:
+		return m.handleKeyFailures(msg)
 	}
 	return m, nil
 }
@@ -78,26 +137,10 @@ func (m Model) handleKeyScanning(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 func (m Model) handleKeyConfirmDelete(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "d", "y", "enter":
-		err := trashItem(m.confirmPath)
-		if err == nil {
-			// Remove from parent's children list
-			parent := m.currentDir()
-			removedSize := int64(0)
-			for i, c := range parent.Children {
-				if c.FullPath() == m.confirmPath {
-					removedSize = c.Size()
-					parent.Children = append(parent.Children[:i], parent.Children[i+1:]...)
-					break
-				}
-			}
-			// Deduct size up the stack
-			for _, anc := range m.stack {
-				anc.AddSize(-removedSize)
-			}
-			if m.root != nil {
-				m.root.AddSize(-removedSize)
-			}
-			m.clampCursor()
+		if m.confirmPath == "" {
+			m.deleteMarked()
+		} else {
+			m.deleteConfirmed()
 		}
 		m.state = StateBrowsing
 		m.confirmPath = ""
@@ -109,6 +152,10 @@ func (m Model) handleKeyConfirmDelete(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 }
 
 func (m Model) handleKeyBrowsing(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.pinned = false
+	if m.filtering {
+		return m.handleKeyFilter(msg)
+	}
 	key := msg.String()
 
 	// Intercept and handle basic navigation
@@ -141,8 +188,40 @@ func (m Model) handleKeyBrowsing(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 func (m Model) handleKeyBrowsingActions(key string) (tea.Model, tea.Cmd) {
 	switch key {
+	case "R":
+		return m, m.startRescan()
+	case "D":
+		return m, m.startDupes()
+	case "t":
+		m.startTypes()
+	case "F":
+		m.startLargest()
+	case "/":
+		return m, m.startFilter()
+	case "f":
+		return m, m.startSearch()
+	case "T":
+		m.startTreemap()
+	case "?":
+		m.helpCursor = 0
+		m.state = StateHelp
+	case "n":
+		m.jumpToMatch(1)
+	case "N":
+		m.jumpToMatch(-1)
 	case "s":
 		m.handleSortToggle()
+	case "A":
+		m.handleSizeModeToggle()
+	case "m":
+		m.showAge = !m.showAge
+	case "c":
+		m.showCount = !m.showCount
+	case "p":
+		m.showPreview = !m.showPreview
+		m.preview = nil
+	case "O":
+		m.handleAgeFilterToggle()
 	case "o":
 		if err := m.handleOpen(); err != nil {
 			m.scanErr = err
@@ -154,10 +233,20 @@ func (m Model) handleKeyBrowsingActions(key string) (tea.Model, tea.Cmd) {
 			m.state = StateError
 		}
 	case "d":
-		sel := m.selected()
-		if sel != nil {
-			m.state = StateConfirmDelete
-			m.confirmPath = sel.FullPath()
+		m.startDelete()
+	case "u":
+		m.undoDelete()
+	case "e":
+		m.showFailures()
+	case " ":
+		m.toggleMark()
+	case "a":
+		m.markAllVisible()
+	case "esc":
+		if m.query() != "" {
+			m.filter.Reset()
+		} else {
+			clear(m.marked)
 		}
 	case "g", "home":
 		m.cursor = 0
@@ -179,9 +268,14 @@ func (m *Model) handleNavRight() (tea.Model, tea.Cmd) {
 }
 
 func (m *Model) handleSortToggle() {
-	if m.sort == SortBySize {
+	switch m.sort {
+	case SortBySize:
 		m.sort = SortByName
-	} else {
+	case SortByName:
+		m.sort = SortByAge
+	case SortByAge:
+		m.sort = SortByCount
+	default:
 		m.sort = SortBySize
 	}
 	// Advance the sort generation: each Node caches the generation at which it
@@ -191,6 +285,20 @@ func (m *Model) handleSortToggle() {
 	m.cursor = 0
 }
 
+// handleSizeModeToggle flips between apparent and allocated sizes. Only a
+// size-ordered listing depends on the metric, so only then is a re-sort needed.
+func (m *Model) handleSizeModeToggle() {
+	if m.sizeMode == SizeApparent {
+		m.sizeMode = SizeAllocated
+	} else {
+		m.sizeMode = SizeApparent
+	}
+	if m.sort == SortBySize {
+		m.sortGen++
+		m.cursor = 0
+	}
+}
+
 func (m *Model) handleOpen() error {
 	sel := m.selected()
 	if sel != nil {
@@ -211,23 +319,14 @@ func (m *Model) handleReveal() error {
 	return nil
 }
 
-const (
-	cmdOsascript = "osascript"
-	cmdOpen      = "open"
-)
-
-// trashItem moves a file/dir to the macOS Trash via osascript (safe delete).
-var trashItem = func(path string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-	defer cancel()
+const cmdOpen = "open"
 
-	cleanedPath := filepath.Clean(path)
-	script := fmt.Sprintf(`tell application "Finder" to delete POSIX file %q`, cleanedPath)
+// trashItem moves a file/dir to the platform trash (safe delete) and returns
+// where it went.
+var trashItem = trash.Move
 
-	// #nosec G204 -- The application intentionally constructs commands based on user input, and we've verified sanitization
-	cmd := exec.CommandContext(ctx, cmdOsascript, "-e", script)
-	return cmd.Run()
-}
+// restoreItem moves a trashed item back to its original path.
+var restoreItem = trash.Restore
 
 // openPath opens a file or directory with the default macOS app.
 var openPath = func(ctx context.Context, path string) error {
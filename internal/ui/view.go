@@ -3,10 +3,14 @@ package ui
 import (
 	"fmt"
 	"path/filepath"
+	"slices"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 	humanize "github.com/dustin/go-humanize"
+	"github.com/mobanhawi/aster/internal/format"
 )
 
 // barFill and barDim are pre-built strings of the maximum bar width — we slice
@@ -29,6 +33,20 @@ func (m Model) View() string {
 		return m.viewError()
 	case StateBrowsing, StateConfirmDelete:
 		return m.viewBrowse()
+	case StateDuplicates:
+		return m.viewDupes()
+	case StateTypes:
+		return m.viewTypes()
+	case StateLargest:
+		return m.viewLargest()
+	case StateSearch:
+		return m.viewSearch()
+	case StateTreemap:
+		return m.viewTreemap()
+	case StateHelp:
+		return m.viewHelp()
+	case StateFailures:
+		return m.viewFailures()
 	}
 	return ""
 }
@@ -64,83 +82,120 @@ func (m Model) viewError() string {
 func (m Model) viewBrowse() string {
 	lines := make([]string, 0, m.height)
 
-	// ── Header ──────────────────────────────────────────────────────────────
-	lines = append(lines, styleHeader.Width(m.width).Render("  aster"))
-
-	// ── Breadcrumb ───────────────────────────────────────────────────────────
-	lines = append(lines, styleBreadcrumb.Width(m.width).Render(m.breadcrumb()))
-
-	// ── Divider (cached by width) ─────────────────────────────────────────────
-	lines = append(lines, m.divider())
+	// ── Header, breadcrumb and divider ───────────────────────────────────────
+	lines = append(lines, m.browseTop()...)
 
 	// ── File list ────────────────────────────────────────────────────────────
 	children := m.visibleChildren()
 	current := m.currentDir()
 	totalSize := int64(0)
 	if current != nil {
-		totalSize = current.Size()
+		totalSize = m.nodeSize(current)
 	}
-
-	// Bar max width — capped globally, clamped for narrow terminals.
-	barMaxW := m.width / 4
-	if barMaxW > maxBarW {
-		barMaxW = maxBarW
-	}
-	if barMaxW < 4 {
-		barMaxW = 4
+	barTotal := totalSize
+	if m.diff != nil {
+		barTotal = m.diffBarTotal(children)
 	}
 
-	// How many rows we can show (reserve header+breadcrumb+divider+footer+status = 6 rows)
-	listHeight := m.height - 7
-	if listHeight < 1 {
-		listHeight = 1
-	}
+	// With the preview pane shown, rows are laid out as if the terminal
+	// ended where the pane begins.
+	paneW := m.previewWidth()
+	list := m
+	list.width -= paneW
+	barMaxW := list.barWidth()
+
+	listHeight := m.listHeight()
 
 	// Viewport window: keep cursor visible
-	start, end := scrollWindow(m.cursor, len(children), listHeight)
+	start, end := m.browseWindow(len(children))
 
+	rows := make([]string, 0, listHeight)
 	for i := start; i < end; i++ {
 		child := children[i]
-		row := m.renderRow(child, i, len(children), totalSize, barMaxW, i == m.cursor)
-		lines = append(lines, row)
+		rows = append(rows, list.renderRow(child, i, len(children), barTotal, barMaxW, i == m.cursor))
 	}
 
 	// Pad remaining rows
 	for i := end - start; i < listHeight; i++ {
-		lines = append(lines, "")
+		rows = append(rows, "")
+	}
+
+	if paneW > 0 {
+		pane := strings.Split(m.viewPreview(paneW, listHeight), "\n")
+		for i, row := range rows {
+			rows[i] = row + strings.Repeat(" ", max(list.width-lipgloss.Width(row), 0)) + pane[i]
+		}
 	}
+	lines = append(lines, rows...)
 
 	// ── Divider (cached) ──────────────────────────────────────────────────────
 	lines = append(lines, m.divider())
 
 	// ── Status bar ───────────────────────────────────────────────────────────
 	sortLabel := "size"
-	if m.sort == SortByName {
+	switch m.sort {
+	case SortByName:
 		sortLabel = "name"
+	case SortByAge:
+		sortLabel = "age"
+	case SortByCount:
+		sortLabel = "count"
 	}
 	n := len(children)
 	// Use caches: humanSize avoids re-running humanize on every frame;
 	// itoa avoids fmt.Sprintf for item count.
-	statusLeft := " " + itoa(n) + " items  total: " + m.humanSize(totalSize) + "  sort: " + sortLabel
+	sizeLabel := "apparent"
+	if m.sizeMode == SizeAllocated {
+		sizeLabel = "disk"
+	}
+	statusLeft := " " + itoa(n) + " items  total: " + m.humanSize(totalSize) + "  sort: " + sortLabel + "  size: " + sizeLabel
+	if m.diff != nil && current != nil {
+		statusLeft += "  change: " + formatDelta(m.entryDelta(current))
+	}
+	if m.ageFilter {
+		statusLeft += "  " + styleNotice.Render("older than "+formatDays(m.olderThan))
+	}
+	if !m.takenAt.IsZero() {
+		statusLeft += "  snapshot: " + m.takenAt.Format("2006-01-02 15:04")
+	}
 	if len(m.stack) == 0 && m.purgeableReady && m.purgeableSpace > 0 {
 		statusLeft += "  purgeable: " + stylePurgeable.Render(m.purgeableString)
 	}
-	statusRight := "scroll: " + scrollIndicator(m.cursor, n) + " "
-	gap := m.width - lipgloss.Width(statusLeft) - lipgloss.Width(statusRight)
-	if gap < 0 {
-		gap = 0
+	switch {
+	case m.watcher != nil:
+		statusLeft += "  " + styleNotice.Render("watching")
+	case m.polling:
+		statusLeft += "  " + styleNotice.Render("watching (every "+pollInterval.String()+")")
+	}
+	if len(m.rescans) > 0 {
+		statusLeft += "  " + m.sp.View() + " rescanning " + itoa(len(m.rescans))
+	}
+	if len(m.marked) > 0 {
+		statusLeft += "  " + styleMarked.Render("marked: "+itoa(len(m.marked))+" ("+format.Bytes(m.markedTotal())+")")
+	}
+	if q := m.query(); q != "" && !m.filtering {
+		statusLeft += "  " + styleMatch.Render("/"+q) + " (n/N)"
 	}
-	statusLine := styleFooter.Render(statusLeft + strings.Repeat(" ", gap) + statusRight)
-	lines = append(lines, statusLine)
+	if m.notice != "" {
+		statusLeft += "  " + styleNotice.Render(m.notice)
+	}
+	lines = append(lines, m.statusLine(statusLeft, "scroll: "+scrollIndicator(m.cursor, n)+" "))
 
-	// ── Key hints (cached by width) ───────────────────────────────────────────
-	lines = append(lines, m.keyHints())
+	// ── Key hints (cached by width), or the filter input ──────────────────────
+	if m.filtering {
+		lines = append(lines, m.line(lipgloss.NewStyle(), m.filter.View()))
+	} else {
+		lines = append(lines, m.keyHints())
+	}
 
 	// ── Confirm-delete overlay ────────────────────────────────────────────────
 	if m.state == StateConfirmDelete {
 		name := filepath.Base(m.confirmPath)
-		prompt := styleConfirm.Width(m.width).Render(
-			"  ⚠  Move to Trash: " + truncate(name, m.width-50) + " ? [d/y/enter = yes  esc/n = no]",
+		if m.confirmPath == "" {
+			name = m.batchPrompt()
+		}
+		prompt := m.line(styleConfirm,
+			"  ⚠  Move to Trash: "+truncate(name, m.width-50)+" ? [d/y/enter = yes  esc/n = no]",
 		)
 		lines = append(lines, prompt)
 	}
@@ -148,16 +203,165 @@ func (m Model) viewBrowse() string {
 	return strings.Join(lines, "\n")
 }
 
+// barWidth is the width of the usage bar column: a quarter of the terminal,
+// capped globally and clamped for narrow terminals.
+func (m Model) barWidth() int {
+	return min(max(m.width/4, 4), maxBarW)
+}
+
+// footerLines is how many lines the frame takes below the list: a divider,
+// the status line, the key hints and room for a confirmation prompt.
+const footerLines = 4
+
+// browseTop renders the browser's lines above the list: the header, the
+// breadcrumb and a divider.
+func (m Model) browseTop() []string {
+	return []string{
+		m.line(styleHeader, "  aster"),
+		m.line(styleBreadcrumb, m.breadcrumb()),
+		m.divider(),
+	}
+}
+
+// listHeight is how many list rows fit between the lines above the list, as
+// rendered, and those below it. Every line of the frame is cut to one line
+// rather than wrapped, so the view never outgrows the terminal.
+func (m Model) listHeight() int {
+	return max(m.height-lipgloss.Height(strings.Join(m.browseTop(), "\n"))-footerLines, 1)
+}
+
+// line renders s in style as exactly one line across the terminal: text
+// that doesn't fit is cut, as wrapping would push the view past the screen.
+func (m Model) line(style lipgloss.Style, s string) string {
+	s = ansi.Truncate(s, max(m.width-style.GetHorizontalFrameSize(), 0), "…")
+	return style.Width(m.width).Render(s)
+}
+
+// statusLine renders the status bar with left and right aligned to the
+// edges, cutting left short when both don't fit.
+func (m Model) statusLine(left, right string) string {
+	inner := m.width - styleFooter.GetHorizontalFrameSize()
+	left = ansi.Truncate(left, max(inner-lipgloss.Width(right)-1, 0), "…")
+	gap := max(inner-lipgloss.Width(left)-lipgloss.Width(right), 0)
+	return styleFooter.Render(left + strings.Repeat(" ", gap) + right)
+}
+
+// listView describes a secondary list screen drawn in the browser's frame.
+type listView struct {
+	title    string // appended to the header
+	subtitle string // shown in place of the breadcrumb
+	total    int
+	cursor   int
+	row      func(i int) string // renders row i; only visible rows are rendered
+	status   string
+	right    string // replaces the scroll position in the status line, if set
+	hints    string
+	prompt   string // confirmation shown below the hints, if any
+}
+
+// viewList renders v with the same header, dividers, scrolling and footer
+// as the browser.
+func (m Model) viewList(v listView) string {
+	lines := make([]string, 0, m.height)
+	lines = append(lines,
+		m.line(styleHeader, "  aster — "+v.title),
+		m.line(styleBreadcrumb, " "+v.subtitle),
+		m.divider(),
+	)
+	listHeight := m.listHeight()
+	start, end := scrollWindow(v.cursor, v.total, listHeight)
+	for i := start; i < end; i++ {
+		lines = append(lines, v.row(i))
+	}
+	for i := end - start; i < listHeight; i++ {
+		lines = append(lines, "")
+	}
+	right := v.right
+	if right == "" {
+		right = "scroll: " + scrollIndicator(v.cursor, v.total)
+	}
+	lines = append(lines,
+		m.divider(),
+		m.statusLine(v.status, right+" "),
+		m.line(styleFooter, " "+v.hints),
+	)
+	if v.prompt != "" {
+		lines = append(lines, m.line(styleConfirm, v.prompt))
+	}
+	return strings.Join(lines, "\n")
+}
+
 // renderRow renders a single file/dir row.
 // barMaxW is pre-computed by the caller to avoid repeating the clamping math.
 func (m Model) renderRow(node *Node, rank, total int, parentSize int64, barMaxW int, selected bool) string {
+	if m.diff != nil {
+		return m.renderDiffRow(node, parentSize, barMaxW, selected)
+	}
+
+	sz := m.nodeSize(node)
+
 	// Proportion of parent
 	pct := 0.0
 	if parentSize > 0 {
-		pct = float64(node.Size()) / float64(parentSize)
+		pct = float64(sz) / float64(parentSize)
 	}
+	bar := renderBar(pct, sz > 0, barMaxW, barStyle(rank, total))
+
+	nameW := m.width - barMaxW - 18 // 18 = size(9) + pct(5) + gaps
+	age := ""
+	if m.showAgeColumn() {
+		age = ageCell(node)
+		nameW -= lipgloss.Width(age)
+	}
+	count := ""
+	if m.showCountColumn() {
+		count = countCell(node)
+		nameW -= lipgloss.Width(count)
+	}
+	name := m.nameCell(node, nameW, selected)
+	sizeStr := sizeCell(node, sz)
+	pctStr := stylePct.Render(fmt.Sprintf("%4.0f%%", pct*100))
+
+	row := bar + " " + name + count + age + sizeStr + pctStr
+
+	if selected {
+		return styleSelected.Width(m.width).Render(row)
+	}
+	return row
+}
+
+// renderTotalRow renders a row outside the tree, such as a file type's total,
+// in renderRow's columns: a bar showing pct, a label and a size.
+func (m Model) renderTotalRow(label string, labelStyle lipgloss.Style, sz int64, pct float64, bar lipgloss.Style, selected bool) string {
+	barMaxW := m.barWidth()
+	nameW := max(m.width-barMaxW-18, 10) // as in renderRow
+	icon := "  "
+	if selected {
+		icon = "▶ "
+	}
+	row := renderBar(pct, sz > 0, barMaxW, bar) + " " +
+		labelStyle.Width(nameW).Render(icon+truncate(label, nameW-3)) +
+		styleSize.Render(format.Bytes(sz)) +
+		stylePct.Render(fmt.Sprintf("%4.0f%%", pct*100))
+	if selected {
+		return styleSelected.Width(m.width).Render(row)
+	}
+	return row
+}
+
+// share returns part as a fraction of whole, or 0 when whole is empty.
+func share(part, whole int64) float64 {
+	if whole <= 0 {
+		return 0
+	}
+	return float64(part) / float64(whole)
+}
+
+// renderBar renders a usage bar barMaxW cells wide with pct of it filled.
+// nonEmpty guarantees at least one filled cell so tiny items stay visible.
+func renderBar(pct float64, nonEmpty bool, barMaxW int, style lipgloss.Style) string {
 	barLen := int(pct * float64(barMaxW))
-	if barLen == 0 && node.Size() > 0 {
+	if barLen == 0 && nonEmpty {
 		barLen = 1
 	}
 	if barLen > barMaxW {
@@ -168,13 +372,26 @@ func (m Model) renderRow(node *Node, rank, total int, parentSize int64, barMaxW
 	// Note: "█" and "░" are 3 bytes each in UTF-8.
 	filledPart := barFill[:barLen*3]
 	dimPart := barDim[:(barMaxW-barLen)*3]
-	bar := barStyle(rank, total).Render(filledPart) + styleBarDim.Render(dimPart)
+	return style.Render(filledPart) + styleBarDim.Render(dimPart)
+}
 
-	// Icon + name
+// nameCell renders the icon and name of node padded to nameW columns.
+func (m Model) nameCell(node *Node, nameW int, selected bool) string {
 	iconStr := "  "
+	if node.IsMount {
+		iconStr = "⏏ "
+	}
+	marked := m.isMarked(node)
+	if marked {
+		iconStr = "✓ "
+	}
 	if selected {
 		iconStr = "▶ "
 	}
+	rescanning := m.isRescanning(node)
+	if rescanning {
+		iconStr = m.sp.View() + " "
+	}
 	icon := styleFile.Render(iconStr)
 	nameStyle := styleRow
 	if node.IsDir {
@@ -184,32 +401,70 @@ func (m Model) renderRow(node *Node, rank, total int, parentSize int64, barMaxW
 	if node.Err != nil {
 		icon = styleError.Render(iconStr)
 	}
+	if node.IsHardlink {
+		nameStyle = styleShared
+	}
+	if node.IsMount {
+		icon = styleMount.Render(iconStr)
+		nameStyle = styleMount
+	}
+	if m.isRemoved(node) {
+		nameStyle = styleRemoved
+	}
+	if marked {
+		icon = styleMarked.Render(iconStr)
+		nameStyle = styleMarked
+	}
+	if rescanning {
+		icon = iconStr // the spinner is already styled
+	}
 
-	nameW := m.width - barMaxW - 18 // 18 = size(9) + pct(5) + gaps
 	if nameW < 10 {
 		nameW = 10
 	}
-	name := nameStyle.Width(nameW).Render(icon + truncate(node.Name, nameW-3))
+	label := node.Name
+	if node.LinkTarget != "" {
+		label += " → " + node.LinkTarget
+	}
+	shown := truncate(label, nameW-3)
+	if pos := matchName(node.Name, m.query()); len(pos) > 0 {
+		// Matches cut off by truncation, and the ellipsis, stay plain.
+		visible := utf8.RuneCountInString(shown)
+		if shown != label {
+			visible--
+		}
+		n, _ := slices.BinarySearch(pos, visible)
+		return nameStyle.Width(nameW).Render(icon + highlight(shown, pos[:n], nameStyle))
+	}
+	return nameStyle.Width(nameW).Render(icon + shown)
+}
 
-	sz := node.Size()
+// sizeCell renders the right-aligned size column for node.
+func sizeCell(node *Node, sz int64) string {
+	switch {
+	case node.IsHardlink:
+		// Bytes are attributed to another link to the same inode.
+		return styleSize.Inherit(styleShared).Render("shared")
+	case node.IsMount:
+		// Not descended into: the size is unknown rather than zero.
+		return styleSize.Inherit(styleMount).Render("mount")
+	}
 	if sz < 0 {
 		sz = 0
 	}
-	sizeStr := styleSize.Render(humanize.Bytes(uint64(sz)))
-	pctStr := stylePct.Render(fmt.Sprintf("%4.0f%%", pct*100))
-
-	row := bar + " " + name + sizeStr + pctStr
-
-	if selected {
-		return styleSelected.Width(m.width).Render(row)
-	}
-	return row
+	return styleSize.Render(humanize.Bytes(uint64(sz)))
 }
 
 // breadcrumb returns a readable "~ › dir › subdir" path.
 // Uses m.absRoot which was resolved once at scan time instead of calling
 // filepath.Abs on every render frame.
 func (m Model) breadcrumb() string {
+	return strings.Join(m.breadcrumbParts(), crumbSep)
+}
+
+// breadcrumbParts returns the breadcrumb's segments: the root's absolute
+// path, then the name of each directory entered.
+func (m Model) breadcrumbParts() []string {
 	home := m.absRoot
 	if home == "" {
 		var err error
@@ -223,7 +478,7 @@ func (m Model) breadcrumb() string {
 	for _, n := range m.stack {
 		parts = append(parts, n.Name)
 	}
-	return strings.Join(parts, " › ")
+	return parts
 }
 
 // scrollWindow returns [start, end) to keep cursor visible in listHeight rows.
@@ -0,0 +1,284 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mobanhawi/aster/internal/scanner"
+	"github.com/mobanhawi/aster/internal/watch"
+)
+
+// pollInterval is how often the whole tree is rescanned in watch mode when
+// the filesystem itself can't be watched.
+const pollInterval = 10 * time.Second
+
+// watcher is the part of watch.Watcher the model uses.
+type watcher interface {
+	Add(dir string) error
+	Events() <-chan watch.Batch
+	Close() error
+}
+
+// newWatcher and readLevel are injected for testing.
+var (
+	newWatcher = func() (watcher, error) {
+		w, err := watch.New()
+		if err != nil {
+			return nil, err
+		}
+		return w, nil
+	}
+	readLevel = scanner.ReadLevel
+)
+
+// watchStartedMsg reports that every scanned directory is being watched.
+type watchStartedMsg struct{ w watcher }
+
+// watchFailedMsg reports that watches could not be placed, e.g. because
+// the inotify limit is exhausted.
+type watchFailedMsg struct{ err error }
+
+// watchBatchMsg carries a batch of filesystem changes.
+type watchBatchMsg struct{ batch watch.Batch }
+
+// levelRead is a one-level re-read in flight.
+type levelRead struct {
+	cancel context.CancelFunc
+	// again is set when another change arrived meanwhile, so the read must
+	// be repeated.
+	again bool
+}
+
+// levelReadMsg carries a fresh one-level listing of node.
+type levelReadMsg struct {
+	node  *Node
+	read  *levelRead
+	fresh *Node
+	err   error
+}
+
+// pollTickMsg asks for the periodic rescan that stands in for watching.
+type pollTickMsg struct{}
+
+// startWatch watches every directory of the freshly scanned tree in the
+// background. The paths are collected here, on the UI goroutine, because
+// the tree must not be read concurrently with updates.
+func (m *Model) startWatch() tea.Cmd {
+	dirs := watchableDirs(m.root)
+	return func() tea.Msg {
+		w, err := newWatcher()
+		if err != nil {
+			return watchFailedMsg{err: err}
+		}
+		if err := addWatches(w, dirs); err != nil {
+			return watchFailedMsg{err: errors.Join(err, w.Close())}
+		}
+		return watchStartedMsg{w: w}
+	}
+}
+
+// watchSubtree watches the directories under n, which a rescan may have
+// just added to the tree. It returns nil outside watch mode.
+func (m *Model) watchSubtree(n *Node) tea.Cmd {
+	if m.watcher == nil {
+		return nil
+	}
+	w, dirs := m.watcher, watchableDirs(n)
+	return func() tea.Msg {
+		if err := addWatches(w, dirs); err != nil {
+			return watchFailedMsg{err: err}
+		}
+		return nil
+	}
+}
+
+// addWatches adds dirs to w. Directories that vanished or can't be read are
+// skipped; only running out of watches is an error.
+func addWatches(w watcher, dirs []string) error {
+	for _, d := range dirs {
+		if err := w.Add(d); errors.Is(err, watch.ErrLimit) {
+			return err
+		}
+	}
+	return nil
+}
+
+// watchableDirs returns the paths of n and every directory below it, except
+// other filesystems, which the scan did not enter.
+func watchableDirs(n *Node) []string {
+	var dirs []string
+	var walk func(n *Node, path string)
+	walk = func(n *Node, path string) {
+		if !n.IsDir || n.IsMount {
+			return
+		}
+		dirs = append(dirs, path)
+		for _, c := range n.Children {
+			walk(c, filepath.Join(path, c.Name))
+		}
+	}
+	walk(n, n.FullPath())
+	return dirs
+}
+
+// waitForWatch delivers the next batch from w. It returns nil once w is
+// closed, ending the chain.
+func waitForWatch(w watcher) tea.Cmd {
+	return func() tea.Msg {
+		b, ok := <-w.Events()
+		if !ok {
+			return nil
+		}
+		return watchBatchMsg{batch: b}
+	}
+}
+
+// pollTick schedules the next periodic rescan.
+func pollTick() tea.Cmd {
+	return tea.Tick(pollInterval, func(time.Time) tea.Msg { return pollTickMsg{} })
+}
+
+// watchStarted begins consuming events from a watcher.
+func (m *Model) watchStarted(msg watchStartedMsg) tea.Cmd {
+	m.watcher = msg.w
+	return waitForWatch(msg.w)
+}
+
+// watchFailed gives up on watching and falls back to rescanning the whole
+// tree every pollInterval. Sizes still update; they just lag behind.
+func (m *Model) watchFailed(msg watchFailedMsg) tea.Cmd {
+	if m.polling {
+		return nil
+	}
+	err := msg.err
+	if m.watcher != nil {
+		err = errors.Join(err, m.watcher.Close())
+		m.watcher = nil
+	}
+	m.polling = true
+	m.notice = "Can't watch for changes (" + err.Error() + "); rescanning every " + pollInterval.String()
+	return pollTick()
+}
+
+// applyWatchBatch refreshes every changed directory still in the tree. Each
+// is re-read one level deep; when the kernel dropped events the whole tree
+// is rescanned instead.
+func (m *Model) applyWatchBatch(b watch.Batch) tea.Cmd {
+	if m.watcher == nil {
+		return nil // fell back to polling since this batch was sent
+	}
+	cmds := []tea.Cmd{waitForWatch(m.watcher)}
+	if b.Overflow {
+		return tea.Batch(append(cmds, m.rescan(m.root, true))...)
+	}
+	for _, dir := range b.Dirs {
+		if n := m.nodeAt(dir); n != nil {
+			cmds = append(cmds, m.refreshDir(n))
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// refreshDir re-reads n's listing in the background. Directories collapsed
+// by --max-depth hold no children, so they are rescanned in full instead.
+// A refresh requested while one of n, or a rescan covering it, is in flight
+// is repeated when that lands.
+func (m *Model) refreshDir(n *Node) tea.Cmd {
+	if job := m.rescanCovering(n); job != nil {
+		if !slices.Contains(job.pending, n) {
+			job.pending = append(job.pending, n)
+		}
+		return nil
+	}
+	if m.collapsed(n) {
+		return m.rescan(n, true)
+	}
+	if read, busy := m.levelReads[n]; busy {
+		read.again = true
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	read := &levelRead{cancel: cancel}
+	if m.levelReads == nil {
+		m.levelReads = make(map[*Node]*levelRead)
+	}
+	m.levelReads[n] = read
+
+	// Located here for the same reason as in rescan.
+	loc, opts := scanner.Locate(n), m.scanOpts
+	return func() tea.Msg {
+		fresh, err := readLevel(ctx, loc, opts)
+		return levelReadMsg{node: n, read: read, fresh: fresh, err: err}
+	}
+}
+
+// finishLevelRead applies a listing to the tree and rescans any directories
+// that appeared in it.
+func (m *Model) finishLevelRead(msg levelReadMsg) tea.Cmd {
+	msg.read.cancel()
+	if m.levelReads[msg.node] != msg.read {
+		return nil // superseded by a rescan
+	}
+	again := msg.read.again
+	delete(m.levelReads, msg.node)
+	if msg.err != nil || !attached(m.root, msg.node) {
+		return nil // gone; its parent's event removes it from the tree
+	}
+
+	sel := m.selected()
+	var cmds []tea.Cmd
+	for _, d := range msg.node.Sync(msg.fresh) {
+		cmds = append(cmds, m.rescan(d, true))
+	}
+	m.dropDetachedMarks()
+	m.leaveDeletedDirs()
+	m.reselect(sel)
+	if again {
+		cmds = append(cmds, m.refreshDir(msg.node))
+	}
+	return tea.Batch(cmds...)
+}
+
+// nodeAt finds the directory at path, or nil when it isn't in the tree.
+func (m *Model) nodeAt(path string) *Node {
+	rel, err := filepath.Rel(m.root.Name, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil
+	}
+	n := m.root
+	if rel == "." {
+		return n
+	}
+	for _, name := range strings.Split(rel, string(filepath.Separator)) {
+		var next *Node
+		for _, c := range n.Children {
+			if c.Name == name && c.IsDir {
+				next = c
+				break
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		n = next
+	}
+	return n
+}
+
+// collapsed reports whether --max-depth folded n's contents into its size.
+func (m *Model) collapsed(n *Node) bool {
+	if m.scanOpts.MaxDepth == 0 {
+		return false
+	}
+	depth := 0
+	for a := n; a.Parent != nil; a = a.Parent {
+		depth++
+	}
+	return depth >= m.scanOpts.MaxDepth
+}
@@ -0,0 +1,196 @@
+package ui
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mobanhawi/aster/internal/scanner"
+	"github.com/mobanhawi/aster/internal/watch"
+)
+
+// fakeWatcher records watched paths; its Events channel is closed so that
+// waiting on it ends immediately.
+type fakeWatcher struct {
+	added  []string
+	limit  int // Add fails with watch.ErrLimit once this many are added
+	closed bool
+}
+
+func (f *fakeWatcher) Add(dir string) error {
+	if f.limit > 0 && len(f.added) >= f.limit {
+		return watch.ErrLimit
+	}
+	f.added = append(f.added, dir)
+	return nil
+}
+
+func (f *fakeWatcher) Events() <-chan watch.Batch {
+	ch := make(chan watch.Batch)
+	close(ch)
+	return ch
+}
+
+func (f *fakeWatcher) Close() error {
+	f.closed = true
+	return nil
+}
+
+func TestWatchMode(t *testing.T) {
+	oldWatcher, oldRead, oldRescan := newWatcher, readLevel, rescanNode
+	t.Cleanup(func() { newWatcher, readLevel, rescanNode = oldWatcher, oldRead, oldRescan })
+
+	newTree := func() *Node {
+		mnt := nodeWithSize("mnt", true, 0)
+		mnt.IsMount = true
+		return nodeWithSize("/r", true, 700,
+			nodeWithSize("logs", true, 600,
+				nodeWithSize("a.log", false, 100),
+				nodeWithSize("old.log", false, 500),
+			),
+			nodeWithSize("file.txt", false, 100),
+			mnt,
+		)
+	}
+	// started scans nothing and returns a model watching root through fake.
+	started := func(t *testing.T, root *Node, fake *fakeWatcher) Model {
+		t.Helper()
+		newWatcher = func() (watcher, error) { return fake, nil }
+		m := NewWithConfig(root.Name, Config{Watch: true})
+		m.width, m.height = 120, 40
+		next, cmd := m.Update(scanDoneMsg{root: root})
+		return runBatch(next.(Model), cmd)
+	}
+
+	t.Run("GivenWatchMode_WhenScanDone_ThenEveryDirWatchedExceptMounts", func(t *testing.T) {
+		fake := &fakeWatcher{}
+		m := started(t, newTree(), fake)
+		if want := []string{"/r", "/r/logs"}; !slices.Equal(fake.added, want) {
+			t.Errorf("watched %v, want %v", fake.added, want)
+		}
+		if m.watcher == nil || !strings.Contains(m.View(), "watching") {
+			t.Error("model should be watching and say so")
+		}
+	})
+
+	t.Run("GivenChangedDir_WhenBatchArrives_ThenLevelSyncedAndNewDirScannedAndWatched", func(t *testing.T) {
+		readLevel = func(_ context.Context, loc scanner.Location, _ scanner.Options) (*Node, error) {
+			return nodeWithSize(loc.Name, true, 0,
+				nodeWithSize("a.log", false, 10),
+				nodeWithSize("new", true, 0),
+			), nil
+		}
+		rescanNode = func(_ context.Context, loc scanner.Location, _ scanner.Options) (*Node, error) {
+			return nodeWithSize(loc.Name, true, 50, nodeWithSize("x", false, 50)), nil
+		}
+		fake := &fakeWatcher{}
+		root := newTree()
+		logs := root.Children[0]
+		m := started(t, root, fake)
+		m.cursor = 1 // file.txt, which moves to the top once logs shrinks
+		m.mark(logs.Children[1])
+
+		next, cmd := m.Update(watchBatchMsg{batch: watch.Batch{Dirs: []string{"/r/logs", "/r/gone"}}})
+		m = runBatch(next.(Model), cmd)
+
+		if root.Size() != 160 || logs.Size() != 60 || len(logs.Children) != 2 {
+			t.Errorf("root %d, logs %d with %d children; want 160, 60, 2", root.Size(), logs.Size(), len(logs.Children))
+		}
+		if len(m.marked) != 0 {
+			t.Error("the mark on the deleted old.log should be dropped")
+		}
+		if sel := m.selected(); sel == nil || sel.Name != "file.txt" || m.cursor != 0 {
+			t.Errorf("selected %v, want the cursor to follow file.txt", sel)
+		}
+		if !slices.Contains(fake.added, "/r/logs/new") || len(m.levelReads) != 0 || len(m.rescans) != 0 {
+			t.Errorf("watched %v, reads %d, rescans %d", fake.added, len(m.levelReads), len(m.rescans))
+		}
+	})
+
+	t.Run("GivenOverflow_WhenBatchArrives_ThenWholeTreeRescanned", func(t *testing.T) {
+		var rescanned string
+		rescanNode = func(_ context.Context, loc scanner.Location, _ scanner.Options) (*Node, error) {
+			rescanned = loc.Path
+			return nodeWithSize(loc.Name, true, 0), nil
+		}
+		root := newTree()
+		m := started(t, root, &fakeWatcher{})
+		next, cmd := m.Update(watchBatchMsg{batch: watch.Batch{Overflow: true}})
+		runBatch(next.(Model), cmd)
+		if rescanned != "/r" || root.Size() != 0 {
+			t.Errorf("rescanned %q, root size %d; want the root rescanned", rescanned, root.Size())
+		}
+	})
+
+	t.Run("GivenWalksBelow_WhenRootRescanned_ThenTheyAreCancelledAndChangesReadAfter", func(t *testing.T) {
+		var calls []string
+		var readCtx context.Context
+		readLevel = func(ctx context.Context, loc scanner.Location, _ scanner.Options) (*Node, error) {
+			calls = append(calls, "read "+loc.Path)
+			readCtx = ctx
+			return nodeWithSize(loc.Name, true, 0, nodeWithSize("a.log", false, 10)), nil
+		}
+		rescanNode = func(_ context.Context, loc scanner.Location, _ scanner.Options) (*Node, error) {
+			calls = append(calls, "rescan "+loc.Path)
+			return nodeWithSize(loc.Name, true, 300, nodeWithSize("logs", true, 300, nodeWithSize("a.log", false, 300))), nil
+		}
+		root := newTree()
+		logs := root.Children[0]
+		m := started(t, root, &fakeWatcher{})
+		read := m.refreshDir(logs)
+		rescan := m.rescan(root, true)
+		if len(m.levelReads) != 0 || len(m.rescans) != 1 {
+			t.Fatalf("reads %d, rescans %d; want only the root's rescan in flight", len(m.levelReads), len(m.rescans))
+		}
+
+		next, _ := m.Update(read())
+		m = next.(Model)
+		if readCtx.Err() == nil || root.Size() != 700 {
+			t.Errorf("read cancelled %v, root %d; want the superseded read cancelled and ignored", readCtx.Err() != nil, root.Size())
+		}
+		if m.refreshDir(logs) != nil {
+			t.Error("a change below the rescan should wait for it to land")
+		}
+
+		calls = nil
+		m = runBatch(m, rescan)
+		if want := []string{"rescan /r", "read /r/logs"}; !slices.Equal(calls, want) {
+			t.Errorf("calls = %v, want %v", calls, want)
+		}
+		if root.Size() != 10 || logs.Size() != 10 || len(m.rescans) != 0 || len(m.levelReads) != 0 {
+			t.Errorf("root %d, logs %d; want both 10 with nothing in flight", root.Size(), logs.Size())
+		}
+	})
+
+	t.Run("GivenWatchLimit_WhenStarting_ThenFallsBackToPolling", func(t *testing.T) {
+		fake := &fakeWatcher{limit: 1}
+		newWatcher = func() (watcher, error) { return fake, nil }
+		m := NewWithConfig("/r", Config{Watch: true})
+		next, cmd := m.Update(scanDoneMsg{root: newTree()})
+		next, cmd = next.(Model).Update(cmd())
+		m = next.(Model)
+		if !fake.closed || m.watcher != nil || !m.polling || cmd == nil {
+			t.Fatalf("closed %v, watcher %v, polling %v; want closed and polling", fake.closed, m.watcher, m.polling)
+		}
+		if !strings.Contains(m.notice, "rescanning every 10s") {
+			t.Errorf("notice = %q", m.notice)
+		}
+
+		next, _ = m.Update(pollTickMsg{})
+		if m = next.(Model); !m.isRescanning(m.root) {
+			t.Error("a poll tick should rescan the root")
+		}
+	})
+
+	t.Run("GivenNoWatchFlag_WhenScanDone_ThenNothingWatched", func(t *testing.T) {
+		newWatcher = func() (watcher, error) {
+			t.Error("watcher started without --watch")
+			return nil, watch.ErrUnsupported
+		}
+		_, cmd := New("/r").Update(scanDoneMsg{root: newTree()})
+		if cmd != nil {
+			t.Error("no command expected after a plain scan")
+		}
+	})
+}
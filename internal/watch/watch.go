@@ -0,0 +1,74 @@
+// Package watch reports which directories of a scanned tree have changed on
+// disk, so the tree can be refreshed one directory level at a time instead of
+// being rescanned from the top.
+package watch
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrUnsupported is returned by New on platforms without a watch backend.
+	ErrUnsupported = errors.New("filesystem watching is not supported on this platform")
+	// ErrLimit is returned when the kernel refuses more watches, e.g. once
+	// fs.inotify.max_user_watches is exhausted.
+	ErrLimit = errors.New("filesystem watch limit reached")
+)
+
+// Latency is how long events are collected before a Batch is delivered. A
+// file being appended to produces a stream of events; batching turns them
+// into one refresh of its directory.
+const Latency = 250 * time.Millisecond
+
+// Batch is a set of changes collected over Latency.
+type Batch struct {
+	// Dirs lists the watched directories whose direct entries were created,
+	// deleted, renamed or modified, each once, in the order first seen.
+	Dirs []string
+	// Overflow reports that the kernel dropped events, so any directory may
+	// be stale.
+	Overflow bool
+}
+
+// change is a single event as passed from the reader to the batcher.
+type change struct {
+	dir      string
+	overflow bool
+}
+
+// batch collects changes from in and delivers them on out as one Batch per
+// Latency window. It keeps collecting while the receiver is busy, so a slow
+// consumer sees fewer, larger batches rather than blocking the reader. out is
+// closed once in is closed.
+func batch(in <-chan change, out chan<- Batch) {
+	defer close(out)
+	var (
+		pending Batch
+		seen    = make(map[string]bool)
+		timer   <-chan time.Time
+		ready   chan<- Batch // nil until the window has elapsed
+	)
+	for {
+		select {
+		case c, ok := <-in:
+			if !ok {
+				return
+			}
+			if c.overflow {
+				pending.Overflow = true
+			} else if !seen[c.dir] {
+				seen[c.dir] = true
+				pending.Dirs = append(pending.Dirs, c.dir)
+			}
+			if timer == nil && ready == nil {
+				timer = time.After(Latency)
+			}
+		case <-timer:
+			timer, ready = nil, out
+		case ready <- pending:
+			pending, ready = Batch{}, nil
+			clear(seen)
+		}
+	}
+}
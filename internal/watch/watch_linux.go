@@ -0,0 +1,134 @@
+package watch
+
+import (
+	"encoding/binary"
+	"errors"
+	"io/fs"
+	"os"
+	"sync"
+	"syscall"
+)
+
+// dirMask selects the events that change a directory's listing or the size
+// of something in it.
+const dirMask = syscall.IN_CREATE | syscall.IN_DELETE | syscall.IN_MODIFY |
+	syscall.IN_CLOSE_WRITE | syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO |
+	syscall.IN_ONLYDIR
+
+// Watcher watches directories with inotify. Each directory is watched on its
+// own; inotify does not recurse, so callers Add every directory they care
+// about, including ones that appear later.
+type Watcher struct {
+	fd   int
+	file *os.File // wraps fd so Close wakes the blocked reader
+
+	mu    sync.Mutex
+	paths map[int32]string
+	wds   map[string]int32
+
+	events    chan Batch
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// New starts an inotify instance with no watches.
+func New() (*Watcher, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if errors.Is(err, syscall.EMFILE) {
+		return nil, ErrLimit // fs.inotify.max_user_instances
+	}
+	if err != nil {
+		return nil, os.NewSyscallError("inotify_init1", err)
+	}
+	w := &Watcher{
+		fd:     fd,
+		file:   os.NewFile(uintptr(fd), "inotify"), // #nosec G115 -- fd is a fresh non-negative descriptor
+		paths:  make(map[int32]string),
+		wds:    make(map[string]int32),
+		events: make(chan Batch),
+	}
+	changes := make(chan change, 256)
+	go w.read(changes)
+	go batch(changes, w.events)
+	return w, nil
+}
+
+// Add starts watching dir. Adding a directory twice is harmless; adding one
+// that was renamed since its last Add moves the watch to the new path.
+func (w *Watcher) Add(dir string) error {
+	wd, err := syscall.InotifyAddWatch(w.fd, dir, dirMask)
+	if errors.Is(err, syscall.ENOSPC) {
+		return ErrLimit // fs.inotify.max_user_watches
+	}
+	if err != nil {
+		return &fs.PathError{Op: "inotify_add_watch", Path: dir, Err: err}
+	}
+	id := int32(wd) // #nosec G115 -- watch descriptors are small positive ints
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if old, ok := w.paths[id]; ok {
+		delete(w.wds, old)
+	}
+	w.paths[id] = dir
+	w.wds[dir] = id
+	return nil
+}
+
+// Events delivers batches of changes. It is closed after Close.
+func (w *Watcher) Events() <-chan Batch {
+	return w.events
+}
+
+// Close removes every watch and stops delivering events.
+func (w *Watcher) Close() error {
+	w.closeOnce.Do(func() { w.closeErr = w.file.Close() })
+	return w.closeErr
+}
+
+// read decodes raw inotify events until the file is closed.
+func (w *Watcher) read(out chan<- change) {
+	defer close(out)
+	buf := make([]byte, 64<<10)
+	for {
+		n, err := w.file.Read(buf)
+		if err != nil {
+			return
+		}
+		w.decode(buf[:n], out)
+	}
+}
+
+// decode turns a buffer of inotify_event records into changes.
+func (w *Watcher) decode(buf []byte, out chan<- change) {
+	for len(buf) >= syscall.SizeofInotifyEvent {
+		wd := int32(binary.NativeEndian.Uint32(buf[0:])) // #nosec G115 -- reinterpreting the kernel's s32
+		mask := binary.NativeEndian.Uint32(buf[4:])
+		// The entry name that follows is not needed: the whole directory
+		// level is re-read.
+		end := syscall.SizeofInotifyEvent + int(binary.NativeEndian.Uint32(buf[12:]))
+		if end > len(buf) {
+			return
+		}
+		buf = buf[end:]
+
+		if mask&syscall.IN_Q_OVERFLOW != 0 {
+			out <- change{overflow: true}
+			continue
+		}
+		w.mu.Lock()
+		dir, ok := w.paths[wd]
+		if mask&syscall.IN_IGNORED != 0 && ok {
+			// The directory was deleted or unmounted; its parent reports
+			// the removal itself.
+			delete(w.paths, wd)
+			if w.wds[dir] == wd {
+				delete(w.wds, dir)
+			}
+			ok = false
+		}
+		w.mu.Unlock()
+		if ok {
+			out <- change{dir: dir}
+		}
+	}
+}
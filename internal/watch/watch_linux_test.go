@@ -0,0 +1,125 @@
+package watch_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/mobanhawi/aster/internal/watch"
+)
+
+// ── Helpers ──────────────────────────────────────────────────────────────────
+
+// newWatcher starts a Watcher on dirs and closes it when the test ends.
+func newWatcher(t *testing.T, dirs ...string) *watch.Watcher {
+	t.Helper()
+	w, err := watch.New()
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := w.Close(); err != nil {
+			t.Errorf("Close: %v", err)
+		}
+	})
+	for _, d := range dirs {
+		if err := w.Add(d); err != nil {
+			t.Fatalf("Add(%s): %v", d, err)
+		}
+	}
+	return w
+}
+
+// next waits for the next batch.
+func next(t *testing.T, w *watch.Watcher) watch.Batch {
+	t.Helper()
+	select {
+	case b, ok := <-w.Events():
+		if !ok {
+			t.Fatal("Events closed")
+		}
+		return b
+	case <-time.After(5 * time.Second):
+		t.Fatal("no batch within 5s")
+		return watch.Batch{}
+	}
+}
+
+// ── Tests ────────────────────────────────────────────────────────────────────
+
+func TestWatcher(t *testing.T) {
+	t.Run("GivenWatchedDirs_WhenEntriesChange_ThenEachDirReportedOnce", func(t *testing.T) {
+		root := t.TempDir()
+		sub := filepath.Join(root, "sub")
+		if err := os.Mkdir(sub, 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		w := newWatcher(t, root, sub)
+
+		for i := range 3 {
+			if err := os.WriteFile(filepath.Join(sub, "log"), make([]byte, i+1), 0o644); err != nil {
+				t.Fatalf("write: %v", err)
+			}
+		}
+		if err := os.Mkdir(filepath.Join(root, "new"), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+
+		var dirs []string
+		for len(dirs) < 2 {
+			for _, d := range next(t, w).Dirs {
+				if slices.Contains(dirs, d) {
+					t.Errorf("%s reported twice", d)
+				}
+				dirs = append(dirs, d)
+			}
+		}
+		slices.Sort(dirs)
+		if want := []string{root, sub}; !slices.Equal(dirs, want) {
+			t.Errorf("dirs = %v, want %v", dirs, want)
+		}
+	})
+
+	t.Run("GivenDeletedWatchedDir_WhenRemoved_ThenOnlyParentReported", func(t *testing.T) {
+		root := t.TempDir()
+		sub := filepath.Join(root, "sub")
+		if err := os.Mkdir(sub, 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		w := newWatcher(t, root, sub)
+
+		if err := os.Remove(sub); err != nil {
+			t.Fatalf("remove: %v", err)
+		}
+		if b := next(t, w); !slices.Equal(b.Dirs, []string{root}) || b.Overflow {
+			t.Errorf("batch = %+v, want just %s", b, root)
+		}
+	})
+
+	t.Run("GivenMissingDir_WhenAdded_ThenError", func(t *testing.T) {
+		w := newWatcher(t)
+		if err := w.Add(filepath.Join(t.TempDir(), "missing")); err == nil {
+			t.Error("Add of a missing dir should fail")
+		}
+	})
+
+	t.Run("GivenWatcher_WhenClosed_ThenEventsClosed", func(t *testing.T) {
+		w, err := watch.New()
+		if err != nil {
+			t.Fatalf("New: %v", err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("Close: %v", err)
+		}
+		select {
+		case _, ok := <-w.Events():
+			if ok {
+				t.Error("unexpected batch after Close")
+			}
+		case <-time.After(5 * time.Second):
+			t.Error("Events not closed after Close")
+		}
+	})
+}
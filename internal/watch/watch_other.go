@@ -0,0 +1,26 @@
+//go:build !linux
+
+package watch
+
+// Watcher is unavailable on this platform; New always fails.
+type Watcher struct{}
+
+// New returns ErrUnsupported.
+func New() (*Watcher, error) {
+	return nil, ErrUnsupported
+}
+
+// Add returns ErrUnsupported.
+func (w *Watcher) Add(string) error {
+	return ErrUnsupported
+}
+
+// Events returns a nil channel, which never delivers.
+func (w *Watcher) Events() <-chan Batch {
+	return nil
+}
+
+// Close does nothing.
+func (w *Watcher) Close() error {
+	return nil
+}
@@ -1,11 +1,17 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mobanhawi/aster/internal/scanner"
 	"github.com/mobanhawi/aster/internal/ui"
 )
 
@@ -17,35 +23,209 @@ var runProgram = func(p *tea.Program) (tea.Model, error) { // injected for testi
 
 var osExit = os.Exit
 
+const usage = `usage: aster [flags] <path>
+       aster --save snap.aster <path>
+       aster --load snap.aster
+       aster --refresh snap.aster
+       aster --diff old.aster <new.aster|path>
+       aster --export-ncdu out.json <path>
+       aster --import-ncdu dump.json
+       aster report [flags] <path>      (see aster report -h)
+
+flags:
+      --save FILE         scan <path>, write a snapshot to FILE and exit
+      --load FILE         browse a snapshot instead of scanning
+      --refresh FILE      update snapshot FILE, re-reading only changed directories
+      --diff FILE         compare snapshot FILE against a newer snapshot or path
+      --export-ncdu FILE  scan <path>, write an ncdu JSON dump to FILE (- for stdout)
+      --import-ncdu FILE  browse an ncdu JSON dump (- for stdin)
+      --watch             keep the tree current as files change (inotify on Linux)
+      --older-than DAYS   only show items not modified in DAYS days (toggle with O)
+  -e, --exclude PATTERN   skip entries matching PATTERN (repeatable)
+  -d, --max-depth N       collapse directories deeper than N levels
+      --skip-hidden       skip dotfiles and dot-directories
+      --skip-symlinks     leave symlinks out of the tree
+  -L, --follow-symlinks   count symlink targets and descend into linked dirs
+  -x, --one-file-system   don't descend into other mounted filesystems
+  -v, --version           print version and exit
+  -h, --help              show this help
+`
+
 func main() {
 	osExit(run(os.Args))
 }
 
+// cliOptions holds everything parsed from the command line.
+type cliOptions struct {
+	showVersion bool
+	savePath    string
+	loadPath    string
+	refreshPath string
+	diffPath    string
+	exportNcdu  string
+	importNcdu  string
+	watch       bool
+	olderThan   int // days
+	scan        scanner.Options
+}
+
+// view returns the browser settings common to every way of opening the TUI.
+func (o cliOptions) view() ui.Config {
+	return ui.Config{OlderThan: time.Duration(o.olderThan) * 24 * time.Hour}
+}
+
+// parseFlags parses args (without the program name). Every flag has a short
+// and a long spelling bound to the same variable.
+func parseFlags(args []string) (cliOptions, []string, error) {
+	var opts cliOptions
+	fs := flag.NewFlagSet("aster", flag.ContinueOnError)
+	fs.SetOutput(io.Discard) // usage and errors are printed by run
+	fs.BoolVar(&opts.showVersion, "v", false, "")
+	fs.BoolVar(&opts.showVersion, "version", false, "")
+	fs.StringVar(&opts.savePath, "save", "", "")
+	fs.StringVar(&opts.loadPath, "load", "", "")
+	fs.StringVar(&opts.refreshPath, "refresh", "", "")
+	fs.StringVar(&opts.diffPath, "diff", "", "")
+	fs.StringVar(&opts.exportNcdu, "export-ncdu", "", "")
+	fs.StringVar(&opts.importNcdu, "import-ncdu", "", "")
+	fs.BoolVar(&opts.watch, "watch", false, "")
+	fs.IntVar(&opts.olderThan, "older-than", 0, "")
+	addScanFlags(fs, &opts.scan)
+	if err := fs.Parse(args); err != nil {
+		return opts, nil, err
+	}
+	if err := checkScanFlags(opts.scan); err != nil {
+		return opts, nil, err
+	}
+	if opts.olderThan < 0 {
+		return opts, nil, fmt.Errorf("invalid --older-than %d: must be >= 0", opts.olderThan)
+	}
+	if opts.watch && (opts.savePath != "" || opts.loadPath != "" || opts.refreshPath != "" ||
+		opts.diffPath != "" || opts.exportNcdu != "" || opts.importNcdu != "") {
+		return opts, nil, errors.New("--watch only applies when browsing a fresh scan of <path>")
+	}
+	return opts, fs.Args(), nil
+}
+
+// addScanFlags registers the scanner options shared by every command that
+// scans a path.
+func addScanFlags(fs *flag.FlagSet, scan *scanner.Options) {
+	exclude := (*stringList)(&scan.Exclude)
+	fs.Var(exclude, "e", "")
+	fs.Var(exclude, "exclude", "")
+	fs.IntVar(&scan.MaxDepth, "d", 0, "")
+	fs.IntVar(&scan.MaxDepth, "max-depth", 0, "")
+	fs.BoolVar(&scan.SkipHidden, "skip-hidden", false, "")
+	fs.BoolVar(&scan.SkipSymlinks, "skip-symlinks", false, "")
+	fs.BoolVar(&scan.FollowSymlinks, "L", false, "")
+	fs.BoolVar(&scan.FollowSymlinks, "follow-symlinks", false, "")
+	fs.BoolVar(&scan.OneFileSystem, "x", false, "")
+	fs.BoolVar(&scan.OneFileSystem, "one-file-system", false, "")
+}
+
+// checkScanFlags rejects values the flag package accepts but the scanner
+// can't use.
+func checkScanFlags(scan scanner.Options) error {
+	if scan.MaxDepth < 0 {
+		return fmt.Errorf("invalid --max-depth %d: must be >= 0", scan.MaxDepth)
+	}
+	return nil
+}
+
+// stringList is a flag.Value collecting every occurrence of a repeated flag.
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
 func run(args []string) int {
-	if len(args) >= 2 && (args[1] == "-v" || args[1] == "--version") {
-		fmt.Printf("aster version %s\n", version)
+	if len(args) > 1 && args[1] == "report" {
+		return runReport(args[2:])
+	}
+
+	opts, rest, err := parseFlags(args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Print(usage)
 		return 0
 	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		fmt.Fprint(os.Stderr, usage)
+		return 1
+	}
 
-	if len(args) >= 2 && (args[1] == "-h" || args[1] == "--help") {
-		fmt.Println("usage: aster <path>")
-		fmt.Println("       aster ~/Downloads")
+	if opts.showVersion {
+		fmt.Printf("aster version %s\n", version)
 		return 0
 	}
 
-	if len(args) < 2 {
-		fmt.Fprintln(os.Stderr, "usage: aster <path>")
-		fmt.Fprintln(os.Stderr, "       aster ~/Downloads")
+	if opts.loadPath != "" {
+		if len(rest) > 0 {
+			fmt.Fprintln(os.Stderr, "error: --load takes no <path>; the snapshot records its own root")
+			return 1
+		}
+		return loadSnapshot(opts.loadPath, opts.view())
+	}
+
+	if opts.refreshPath != "" {
+		if len(rest) > 0 {
+			fmt.Fprintln(os.Stderr, "error: --refresh takes no <path>; the snapshot records its own root")
+			return 1
+		}
+		return refreshSnapshot(opts.refreshPath)
+	}
+
+	if opts.importNcdu != "" {
+		if len(rest) > 0 {
+			fmt.Fprintln(os.Stderr, "error: --import-ncdu takes no <path>; the dump records its own root")
+			return 1
+		}
+		return importNcdu(opts.importNcdu, opts.view())
+	}
+
+	if opts.diffPath != "" {
+		if len(rest) != 1 {
+			fmt.Fprintln(os.Stderr, "error: --diff needs exactly one newer snapshot or path to compare against")
+			return 1
+		}
+		return compareSnapshots(opts.diffPath, rest[0], opts.scan, opts.view())
+	}
+
+	if len(rest) < 1 {
+		fmt.Fprint(os.Stderr, usage)
+		return 1
+	}
+
+	absRoot, err := resolveRoot(rest[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		return 1
 	}
 
-	root := args[1]
+	if opts.savePath != "" {
+		return saveSnapshot(opts.savePath, absRoot, opts.scan)
+	}
 
+	if opts.exportNcdu != "" {
+		return exportNcdu(opts.exportNcdu, absRoot, opts.scan)
+	}
+
+	view := opts.view()
+	view.Scan, view.Watch = opts.scan, opts.watch
+	return runUI(ui.NewWithConfig(absRoot, view))
+}
+
+// resolveRoot turns a user-supplied path into an absolute one and verifies
+// that it exists.
+func resolveRoot(root string) (string, error) {
 	// Resolve to absolute path
 	absRoot, err := filepath.Abs(root)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "error resolving path: %v\n", err)
-		return 1
+		return "", fmt.Errorf("resolving path: %w", err)
 	}
 
 	// Verify the path exists and is bounded securely
@@ -56,18 +236,19 @@ func run(args []string) int {
 
 	cleanRootAbs, err := filepath.Abs(cleanRoot)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "error computing valid path: %v\n", err)
-		return 1
+		return "", fmt.Errorf("computing valid path: %w", err)
 	}
 
 	// #nosec G703 -- This is a CLI. Exploring untrusted paths directly from input is intended.
 	if _, err := os.Stat(cleanRootAbs); err != nil {
-		fmt.Fprintf(os.Stderr, "error: %v\n", err)
-		return 1
+		return "", err
 	}
+	return absRoot, nil
+}
 
-	model := ui.New(absRoot)
-	p := tea.NewProgram(model, tea.WithAltScreen())
+// runUI runs the Bubble Tea program for model until the user quits.
+func runUI(model ui.Model) int {
+	p := tea.NewProgram(model, tea.WithAltScreen(), tea.WithMouseCellMotion())
 	if _, err := runProgram(p); err != nil {
 		fmt.Fprintf(os.Stderr, "fatal: %v\n", err)
 		return 1
@@ -5,8 +5,10 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mobanhawi/aster/internal/snapshot"
 )
 
 func TestRun(t *testing.T) {
@@ -57,6 +59,51 @@ func TestRun(t *testing.T) {
 			args:         []string{"aster", tempDir},
 			expectedCode: 0,
 		},
+		{
+			name:         "one-file-system short flag",
+			args:         []string{"aster", "-x", tempDir},
+			expectedCode: 0,
+		},
+		{
+			name:         "one-file-system long flag",
+			args:         []string{"aster", "--one-file-system", tempDir},
+			expectedCode: 0,
+		},
+		{
+			name:         "scan filter flags",
+			args:         []string{"aster", "--exclude", "*.git", "--max-depth", "3", "--skip-hidden", tempDir},
+			expectedCode: 0,
+		},
+		{
+			name:         "follow symlinks",
+			args:         []string{"aster", "-L", tempDir},
+			expectedCode: 0,
+		},
+		{
+			name:         "negative max depth",
+			args:         []string{"aster", "--max-depth", "-1", tempDir},
+			expectedCode: 1,
+		},
+		{
+			name:         "unknown flag",
+			args:         []string{"aster", "--bogus", tempDir},
+			expectedCode: 1,
+		},
+		{
+			name:         "report help",
+			args:         []string{"aster", "report", "-h"},
+			expectedCode: 0,
+		},
+		{
+			name:         "report without path",
+			args:         []string{"aster", "report"},
+			expectedCode: 1,
+		},
+		{
+			name:         "report bad format",
+			args:         []string{"aster", "report", "-f", "xml", tempDir},
+			expectedCode: 1,
+		},
 		{
 			name:         "valid path tea program error",
 			args:         []string{"aster", tempDir},
@@ -126,3 +173,259 @@ func TestMainFunc(t *testing.T) {
 		t.Errorf("expected main to exit with 0, got %d", exitedWith)
 	}
 }
+
+func TestParseFlags(t *testing.T) {
+	opts, rest, err := parseFlags([]string{"-x", "/data"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !opts.scan.OneFileSystem {
+		t.Errorf("expected -x to enable OneFileSystem")
+	}
+	if len(rest) != 1 || rest[0] != "/data" {
+		t.Errorf("positional args = %v, want [/data]", rest)
+	}
+}
+
+func TestParseFlagsScanOptions(t *testing.T) {
+	opts, rest, err := parseFlags([]string{
+		"--exclude", "*.git", "-e", "node_modules", "--max-depth", "3",
+		"--skip-hidden", "--skip-symlinks", "~/src",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := opts.scan.Exclude; len(got) != 2 || got[0] != "*.git" || got[1] != "node_modules" {
+		t.Errorf("Exclude = %v, want [*.git node_modules]", got)
+	}
+	if opts.scan.MaxDepth != 3 {
+		t.Errorf("MaxDepth = %d, want 3", opts.scan.MaxDepth)
+	}
+	if !opts.scan.SkipHidden || !opts.scan.SkipSymlinks {
+		t.Errorf("expected SkipHidden and SkipSymlinks to be set")
+	}
+	if len(rest) != 1 || rest[0] != "~/src" {
+		t.Errorf("positional args = %v, want [~/src]", rest)
+	}
+}
+
+func TestParseFlagsWatch(t *testing.T) {
+	t.Run("GivenWatchWithPath_WhenParsed_ThenEnabled", func(t *testing.T) {
+		opts, _, err := parseFlags([]string{"--watch", "/var/log"})
+		if err != nil || !opts.watch {
+			t.Errorf("watch = %v, err = %v; want enabled", opts.watch, err)
+		}
+	})
+
+	for _, flag := range []string{"--save", "--load", "--refresh", "--diff", "--export-ncdu", "--import-ncdu"} {
+		t.Run("GivenWatchWith"+flag+"_WhenParsed_ThenError", func(t *testing.T) {
+			if _, _, err := parseFlags([]string{"--watch", flag, "f", "/data"}); err == nil {
+				t.Errorf("--watch with %s should be rejected", flag)
+			}
+		})
+	}
+}
+
+func TestParseFlagsOlderThan(t *testing.T) {
+	testCases := []struct {
+		name    string
+		args    []string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"GivenDays_WhenParsed_ThenFilterThresholdSet", []string{"--older-than", "90", "/data"}, 90 * 24 * time.Hour, false},
+		{"GivenNoFlag_WhenParsed_ThenFilterOff", []string{"/data"}, 0, false},
+		{"GivenNegativeDays_WhenParsed_ThenError", []string{"--older-than", "-1", "/data"}, 0, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			opts, _, err := parseFlags(tc.args)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tc.wantErr)
+			}
+			if got := opts.view().OlderThan; !tc.wantErr && got != tc.want {
+				t.Errorf("OlderThan = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRunReport(t *testing.T) {
+	silenceOutput(t)
+
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "file.bin"), make([]byte, 1024), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	for _, format := range []string{"table", "json", "csv"} {
+		if code := run([]string{"aster", "report", "-n", "5", "--format", format, root}); code != 0 {
+			t.Errorf("report --format %s: exit code = %d, want 0", format, code)
+		}
+	}
+	if code := run([]string{"aster", "report", filepath.Join(root, "missing")}); code != 1 {
+		t.Errorf("report on missing path: exit code = %d, want 1", code)
+	}
+}
+
+func TestParseReportFlags(t *testing.T) {
+	t.Run("GivenFlags_WhenParsed_ThenReportAndScanOptionsSet", func(t *testing.T) {
+		opts, rest, err := parseReportFlags([]string{
+			"--top", "3", "--rank-depth", "2", "--min-size", "1MB", "-f", "json", "-x", "/data",
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if opts.report.Top != 3 || opts.report.RankDepth != 2 || opts.report.MinSize != 1_000_000 {
+			t.Errorf("report options = %+v, want top 3, depth 2, min 1MB", opts.report)
+		}
+		if opts.format != "json" || !opts.scan.OneFileSystem {
+			t.Errorf("format = %q, OneFileSystem = %v", opts.format, opts.scan.OneFileSystem)
+		}
+		if len(rest) != 1 || rest[0] != "/data" {
+			t.Errorf("positional args = %v, want [/data]", rest)
+		}
+	})
+
+	for _, args := range [][]string{
+		{"--top", "0"},
+		{"--rank-depth", "-1"},
+		{"--min-size", "lots"},
+		{"--format", "yaml"},
+		{"--max-depth", "-2"},
+	} {
+		t.Run("GivenInvalid_"+args[0]+"_WhenParsed_ThenError", func(t *testing.T) {
+			if _, _, err := parseReportFlags(append(args, "/data")); err == nil {
+				t.Errorf("expected error for %v", args)
+			}
+		})
+	}
+}
+
+func TestSaveAndLoadSnapshot(t *testing.T) {
+	originalRunProgram := runProgram
+	defer func() { runProgram = originalRunProgram }()
+	runProgram = func(_ *tea.Program) (tea.Model, error) { return nil, nil }
+
+	silenceOutput(t)
+
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "file.bin"), make([]byte, 1024), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	snap := filepath.Join(t.TempDir(), "snap.aster")
+
+	if code := run([]string{"aster", "--save", snap, root}); code != 0 {
+		t.Fatalf("--save exit code = %d, want 0", code)
+	}
+	if _, err := os.Stat(snap); err != nil {
+		t.Fatalf("snapshot not written: %v", err)
+	}
+	if code := run([]string{"aster", "--load", snap}); code != 0 {
+		t.Errorf("--load exit code = %d, want 0", code)
+	}
+	if code := run([]string{"aster", "--load", snap, root}); code != 1 {
+		t.Errorf("--load with a path: exit code = %d, want 1", code)
+	}
+	if code := run([]string{"aster", "--load", filepath.Join(root, "missing.aster")}); code != 1 {
+		t.Errorf("--load missing file: exit code = %d, want 1", code)
+	}
+	if code := run([]string{"aster", "--save", filepath.Join(root, "no", "such", "dir.aster"), root}); code != 1 {
+		t.Errorf("--save to unwritable path: exit code = %d, want 1", code)
+	}
+
+	// Compare the snapshot against itself, then against a live rescan.
+	if code := run([]string{"aster", "--diff", snap, snap}); code != 0 {
+		t.Errorf("--diff snapshot snapshot: exit code = %d, want 0", code)
+	}
+	if code := run([]string{"aster", "--diff", snap, root}); code != 0 {
+		t.Errorf("--diff snapshot path: exit code = %d, want 0", code)
+	}
+	if code := run([]string{"aster", "--diff", snap}); code != 1 {
+		t.Errorf("--diff without newer side: exit code = %d, want 1", code)
+	}
+	if code := run([]string{"aster", "--diff", filepath.Join(root, "file.bin"), root}); code != 1 {
+		t.Errorf("--diff with a non-snapshot: exit code = %d, want 1", code)
+	}
+}
+
+func TestRefreshSnapshot(t *testing.T) {
+	silenceOutput(t)
+
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "file.bin"), make([]byte, 1024), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	snap := filepath.Join(t.TempDir(), "snap.aster")
+	if code := run([]string{"aster", "--save", snap, root}); code != 0 {
+		t.Fatalf("--save exit code = %d, want 0", code)
+	}
+	if err := os.WriteFile(filepath.Join(root, "new.bin"), make([]byte, 2048), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	if code := run([]string{"aster", "--refresh", snap}); code != 0 {
+		t.Fatalf("--refresh exit code = %d, want 0", code)
+	}
+	tree, _, err := snapshot.Load(snap)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if tree.Size() != 3072 || len(tree.Children) != 2 {
+		t.Errorf("refreshed snapshot: size %d with %d children, want 3072 with 2", tree.Size(), len(tree.Children))
+	}
+
+	if code := run([]string{"aster", "--refresh", snap, root}); code != 1 {
+		t.Errorf("--refresh with a path: exit code = %d, want 1", code)
+	}
+	if code := run([]string{"aster", "--refresh", filepath.Join(root, "missing.aster")}); code != 1 {
+		t.Errorf("--refresh missing file: exit code = %d, want 1", code)
+	}
+}
+
+func TestExportAndImportNcdu(t *testing.T) {
+	originalRunProgram := runProgram
+	defer func() { runProgram = originalRunProgram }()
+	runProgram = func(_ *tea.Program) (tea.Model, error) { return nil, nil }
+
+	silenceOutput(t)
+
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "file.bin"), make([]byte, 1024), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	dump := filepath.Join(t.TempDir(), "dump.json")
+
+	if code := run([]string{"aster", "--export-ncdu", dump, root}); code != 0 {
+		t.Fatalf("--export-ncdu exit code = %d, want 0", code)
+	}
+	if code := run([]string{"aster", "--export-ncdu", "-", root}); code != 0 {
+		t.Errorf("--export-ncdu to stdout: exit code = %d, want 0", code)
+	}
+	if code := run([]string{"aster", "--import-ncdu", dump}); code != 0 {
+		t.Errorf("--import-ncdu exit code = %d, want 0", code)
+	}
+	if code := run([]string{"aster", "--import-ncdu", dump, root}); code != 1 {
+		t.Errorf("--import-ncdu with a path: exit code = %d, want 1", code)
+	}
+	if code := run([]string{"aster", "--import-ncdu", filepath.Join(root, "file.bin")}); code != 1 {
+		t.Errorf("--import-ncdu with a non-dump: exit code = %d, want 1", code)
+	}
+	if code := run([]string{"aster", "--export-ncdu", filepath.Join(root, "no", "such", "dump.json"), root}); code != 1 {
+		t.Errorf("--export-ncdu to unwritable path: exit code = %d, want 1", code)
+	}
+}
+
+// silenceOutput points stdout and stderr at the null device for the test.
+func silenceOutput(t *testing.T) {
+	t.Helper()
+	oldStdout, oldStderr := os.Stdout, os.Stderr
+	nullOut, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		return
+	}
+	os.Stdout, os.Stderr = nullOut, nullOut
+	t.Cleanup(func() {
+		os.Stdout, os.Stderr = oldStdout, oldStderr
+		nullOut.Close()
+	})
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	humanize "github.com/dustin/go-humanize"
+	"github.com/mobanhawi/aster/internal/report"
+	"github.com/mobanhawi/aster/internal/scanner"
+)
+
+const reportUsage = `usage: aster report [flags] <path>
+
+Scan <path> and print its largest directories and files without the TUI.
+
+flags:
+  -n, --top N             list the N largest directories and files (default 10)
+      --rank-depth N      only rank entries up to N levels below <path> (0 = all)
+      --min-size SIZE     skip entries smaller than SIZE, e.g. 100MB or 1GiB
+  -f, --format FORMAT     table, json or csv (default table)
+  -e, --exclude PATTERN   skip entries matching PATTERN (repeatable)
+  -d, --max-depth N       collapse directories deeper than N levels
+      --skip-hidden       skip dotfiles and dot-directories
+      --skip-symlinks     leave symlinks out of the tree
+  -L, --follow-symlinks   count symlink targets and descend into linked dirs
+  -x, --one-file-system   don't descend into other mounted filesystems
+  -h, --help              show this help
+`
+
+// reportOptions holds everything parsed from the report command line.
+type reportOptions struct {
+	report report.Options
+	format string
+	scan   scanner.Options
+}
+
+// parseReportFlags parses the arguments following "report".
+func parseReportFlags(args []string) (reportOptions, []string, error) {
+	opts := reportOptions{report: report.Options{Top: 10}, format: "table"}
+	var minSize string
+	fs := flag.NewFlagSet("aster report", flag.ContinueOnError)
+	fs.SetOutput(io.Discard) // usage and errors are printed by runReport
+	fs.IntVar(&opts.report.Top, "n", 10, "")
+	fs.IntVar(&opts.report.Top, "top", 10, "")
+	fs.IntVar(&opts.report.RankDepth, "rank-depth", 0, "")
+	fs.StringVar(&minSize, "min-size", "", "")
+	fs.StringVar(&opts.format, "f", "table", "")
+	fs.StringVar(&opts.format, "format", "table", "")
+	addScanFlags(fs, &opts.scan)
+	if err := fs.Parse(args); err != nil {
+		return opts, nil, err
+	}
+	if err := checkScanFlags(opts.scan); err != nil {
+		return opts, nil, err
+	}
+
+	switch {
+	case opts.report.Top < 1:
+		return opts, nil, fmt.Errorf("invalid --top %d: must be >= 1", opts.report.Top)
+	case opts.report.RankDepth < 0:
+		return opts, nil, fmt.Errorf("invalid --rank-depth %d: must be >= 0", opts.report.RankDepth)
+	}
+	switch opts.format {
+	case "table", "json", "csv":
+	default:
+		return opts, nil, fmt.Errorf("invalid --format %q: want table, json or csv", opts.format)
+	}
+	if minSize != "" {
+		n, err := humanize.ParseBytes(minSize)
+		if err != nil {
+			return opts, nil, fmt.Errorf("invalid --min-size %q: %w", minSize, err)
+		}
+		opts.report.MinSize = int64(min(n, 1<<62)) // #nosec G115 -- clamped well inside int64
+	}
+	return opts, fs.Args(), nil
+}
+
+// runReport implements "aster report": scan, rank and print to stdout.
+func runReport(args []string) int {
+	opts, rest, err := parseReportFlags(args)
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Print(reportUsage)
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		fmt.Fprint(os.Stderr, reportUsage)
+		return 1
+	}
+	if len(rest) != 1 {
+		fmt.Fprint(os.Stderr, reportUsage)
+		return 1
+	}
+
+	root, err := resolveRoot(rest[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	node, err := scanHeadless(root, opts.scan)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+
+	r := report.Build(node, opts.report)
+	switch opts.format {
+	case "json":
+		err = r.WriteJSON(os.Stdout)
+	case "csv":
+		err = r.WriteCSV(os.Stdout)
+	default:
+		err = r.WriteTable(os.Stdout)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	return 0
+}